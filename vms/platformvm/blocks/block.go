@@ -4,14 +4,18 @@
 package blocks
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 )
 
+var ErrBlockTooLarge = errors.New("block too large")
+
 // Block defines the common stateless interface for all blocks
 type Block interface {
 	snow.ContextInitializable
@@ -39,7 +43,13 @@ type BanffBlock interface {
 func initialize(blk Block) error {
 	// We serialize this block as a pointer so that it can be deserialized into
 	// a Block
+	//
+	// [Codec] can't marshal more than [MaxBlockSize] bytes, so running out of
+	// space means that the block is too large.
 	bytes, err := Codec.Marshal(Version, &blk)
+	if errors.Is(err, wrappers.ErrInsufficientLength) {
+		return fmt.Errorf("%w: exceeds %d bytes", ErrBlockTooLarge, MaxBlockSize)
+	}
 	if err != nil {
 		return fmt.Errorf("couldn't marshal block: %w", err)
 	}
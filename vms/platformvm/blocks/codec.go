@@ -8,12 +8,20 @@ import (
 
 	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/codec/linearcodec"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 )
 
-// Version is the current default codec version
-const Version = txs.Version
+const (
+	// Version is the current default codec version
+	Version = txs.Version
+
+	// MaxBlockSize is the maximum size, in bytes, of a block that [Codec] can
+	// marshal and parse. Blocks created by this package that would be larger
+	// fail with [ErrBlockTooLarge] since they couldn't be propagated.
+	MaxBlockSize = 256 * units.KiB
+)
 
 // GenesisCode allows blocks of larger than usual size to be parsed.
 // While this gives flexibility in accommodating large genesis blocks
@@ -26,7 +34,7 @@ var (
 
 func init() {
 	c := linearcodec.NewDefault()
-	Codec = codec.NewDefaultManager()
+	Codec = codec.NewManager(MaxBlockSize)
 	gc := linearcodec.NewCustomMaxLength(math.MaxInt32)
 	GenesisCodec = codec.NewManager(math.MaxInt32)
 
@@ -6,6 +6,7 @@ package executor
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -95,6 +96,7 @@ func (a *acceptor) ApricotAtomicBlock(b *blocks.ApricotAtomicBlock) error {
 	}
 
 	// Note that this method writes [batch] to the database.
+	start := time.Now()
 	if err := a.ctx.SharedMemory.Apply(blkState.atomicRequests, batch); err != nil {
 		return fmt.Errorf(
 			"failed to atomically accept tx %s in block %s: %w",
@@ -103,6 +105,7 @@ func (a *acceptor) ApricotAtomicBlock(b *blocks.ApricotAtomicBlock) error {
 			err,
 		)
 	}
+	a.metrics.ObserveAtomicRequestsApply(getBlockType(b), time.Since(start))
 
 	a.ctx.Log.Trace(
 		"accepted block",
@@ -255,9 +258,11 @@ func (a *acceptor) standardBlock(b blocks.Block, blockType string) error {
 	}
 
 	// Note that this method writes [batch] to the database.
+	start := time.Now()
 	if err := a.ctx.SharedMemory.Apply(blkState.atomicRequests, batch); err != nil {
 		return fmt.Errorf("failed to apply vm's state to shared memory: %w", err)
 	}
+	a.metrics.ObserveAtomicRequestsApply(getBlockType(b), time.Since(start))
 
 	if onAcceptFunc := blkState.onAcceptFunc; onAcceptFunc != nil {
 		onAcceptFunc()
@@ -286,6 +291,18 @@ func (a *acceptor) commonAccept(b blocks.Block) error {
 	a.state.SetLastAccepted(blkID)
 	a.state.SetHeight(b.Height())
 	a.state.AddStatelessBlock(b)
+	if blkState, ok := a.blkIDToState[blkID]; ok {
+		a.state.SetBlockTimestamp(blkID, blkState.timestamp)
+		if len(blkState.atomicRequests) > 0 {
+			a.state.AddBlockAtomicRequests(blkID, blkState.atomicRequests)
+		}
+		// Proposal blocks don't have an [onAcceptState]. The UTXO changes of
+		// their tx are in the [onAcceptState] of the option block.
+		if blkState.onAcceptState != nil {
+			produced, consumed := blkState.onAcceptState.UTXOChanges()
+			a.state.SetBlockUTXOChanges(blkID, consumed, produced)
+		}
+	}
 	a.validators.OnAcceptedBlockID(blkID)
 	return nil
 }
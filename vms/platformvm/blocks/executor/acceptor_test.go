@@ -6,6 +6,8 @@ package executor
 import (
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
@@ -146,6 +148,10 @@ func TestAcceptorVisitAtomicBlock(t *testing.T) {
 	s.EXPECT().SetLastAccepted(blk.ID()).Times(1)
 	s.EXPECT().SetHeight(blk.Height()).Times(1)
 	s.EXPECT().AddStatelessBlock(blk).Times(1)
+	s.EXPECT().SetBlockTimestamp(blk.ID(), gomock.Any()).Times(1)
+	onAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1)
+	s.EXPECT().SetBlockUTXOChanges(blk.ID(), gomock.Any(), gomock.Any()).Times(1)
+	s.EXPECT().AddBlockAtomicRequests(blk.ID(), atomicRequests).Times(1)
 	batch := database.NewMockBatch(ctrl)
 	s.EXPECT().CommitBatch().Return(batch, nil).Times(1)
 	s.EXPECT().Abort().Times(1)
@@ -156,6 +162,93 @@ func TestAcceptorVisitAtomicBlock(t *testing.T) {
 	require.NoError(acceptor.ApricotAtomicBlock(blk))
 }
 
+func TestAcceptorAtomicBlockMetrics(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	s := state.NewMockState(ctrl)
+	sharedMemory := atomic.NewMockSharedMemory(ctrl)
+
+	registry := prometheus.NewRegistry()
+	m, err := metrics.New("", registry)
+	require.NoError(err)
+
+	parentID := ids.GenerateTestID()
+	acceptor := &acceptor{
+		backend: &backend{
+			lastAccepted: parentID,
+			blkIDToState: make(map[ids.ID]*blockState),
+			state:        s,
+			ctx: &snow.Context{
+				Log:          logging.NoLog{},
+				SharedMemory: sharedMemory,
+			},
+		},
+		metrics:    m,
+		validators: validators.TestManager,
+	}
+
+	blk, err := blocks.NewApricotAtomicBlock(
+		parentID,
+		1,
+		&txs.Tx{
+			Unsigned: &txs.AddDelegatorTx{
+				// Without the line below, this function will error.
+				DelegationRewardsOwner: &secp256k1fx.OutputOwners{},
+			},
+			Creds: []verify.Verifiable{},
+		},
+	)
+	require.NoError(err)
+
+	onAcceptState := state.NewMockDiff(ctrl)
+	atomicRequests := map[ids.ID]*atomic.Requests{ids.GenerateTestID(): nil}
+	acceptor.backend.blkIDToState[blk.ID()] = &blockState{
+		onAcceptState:  onAcceptState,
+		atomicRequests: atomicRequests,
+	}
+
+	s.EXPECT().SetLastAccepted(blk.ID()).Times(1)
+	s.EXPECT().SetHeight(blk.Height()).Times(1)
+	s.EXPECT().AddStatelessBlock(blk).Times(1)
+	s.EXPECT().SetBlockTimestamp(blk.ID(), gomock.Any()).Times(1)
+	onAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1)
+	s.EXPECT().SetBlockUTXOChanges(blk.ID(), gomock.Any(), gomock.Any()).Times(1)
+	s.EXPECT().AddBlockAtomicRequests(blk.ID(), atomicRequests).Times(1)
+	batch := database.NewMockBatch(ctrl)
+	s.EXPECT().CommitBatch().Return(batch, nil).Times(1)
+	s.EXPECT().Abort().Times(1)
+	onAcceptState.EXPECT().Apply(s).Times(1)
+	sharedMemory.EXPECT().Apply(atomicRequests, batch).Return(nil).Times(1)
+	s.EXPECT().Checksum().Return(ids.Empty).Times(1)
+
+	require.NoError(acceptor.ApricotAtomicBlock(blk))
+
+	mfs, err := registry.Gather()
+	require.NoError(err)
+
+	var (
+		numAccepted    float64
+		numApplyCounts uint64
+	)
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "atomic_blks_accepted":
+			numAccepted = mf.GetMetric()[0].GetCounter().GetValue()
+		case "atomic_requests_apply_duration":
+			for _, metric := range mf.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "blk_type" && label.GetValue() == "ApricotAtomic" {
+						numApplyCounts = metric.GetHistogram().GetSampleCount()
+					}
+				}
+			}
+		}
+	}
+	require.Equal(float64(1), numAccepted)
+	require.Equal(uint64(1), numApplyCounts)
+}
+
 func TestAcceptorVisitStandardBlock(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -235,6 +328,10 @@ func TestAcceptorVisitStandardBlock(t *testing.T) {
 	s.EXPECT().SetLastAccepted(blk.ID()).Times(1)
 	s.EXPECT().SetHeight(blk.Height()).Times(1)
 	s.EXPECT().AddStatelessBlock(blk).Times(1)
+	s.EXPECT().SetBlockTimestamp(blk.ID(), gomock.Any()).Times(1)
+	onAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1)
+	s.EXPECT().SetBlockUTXOChanges(blk.ID(), gomock.Any(), gomock.Any()).Times(1)
+	s.EXPECT().AddBlockAtomicRequests(blk.ID(), atomicRequests).Times(1)
 	batch := database.NewMockBatch(ctrl)
 	s.EXPECT().CommitBatch().Return(batch, nil).Times(1)
 	s.EXPECT().Abort().Times(1)
@@ -300,6 +397,9 @@ func TestAcceptorVisitCommitBlock(t *testing.T) {
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
 		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
+		s.EXPECT().SetBlockTimestamp(parentID, parentState.timestamp).Times(1),
+		parentOnAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1),
+		s.EXPECT().SetBlockUTXOChanges(parentID, gomock.Any(), gomock.Any()).Times(1),
 
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
@@ -324,10 +424,16 @@ func TestAcceptorVisitCommitBlock(t *testing.T) {
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
 		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
+		s.EXPECT().SetBlockTimestamp(parentID, parentState.timestamp).Times(1),
+		parentOnAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1),
+		s.EXPECT().SetBlockUTXOChanges(parentID, gomock.Any(), gomock.Any()).Times(1),
 
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
 		s.EXPECT().AddStatelessBlock(blk).Times(1),
+		s.EXPECT().SetBlockTimestamp(blkID, gomock.Any()).Times(1),
+		onAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1),
+		s.EXPECT().SetBlockUTXOChanges(blkID, gomock.Any(), gomock.Any()).Times(1),
 
 		onAcceptState.EXPECT().Apply(s).Times(1),
 		s.EXPECT().Commit().Return(nil).Times(1),
@@ -391,6 +497,9 @@ func TestAcceptorVisitAbortBlock(t *testing.T) {
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
 		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
+		s.EXPECT().SetBlockTimestamp(parentID, parentState.timestamp).Times(1),
+		parentOnAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1),
+		s.EXPECT().SetBlockUTXOChanges(parentID, gomock.Any(), gomock.Any()).Times(1),
 
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
@@ -416,10 +525,16 @@ func TestAcceptorVisitAbortBlock(t *testing.T) {
 		parentStatelessBlk.EXPECT().Height().Return(blk.Height()-1).Times(1),
 		s.EXPECT().SetHeight(blk.Height()-1).Times(1),
 		s.EXPECT().AddStatelessBlock(parentState.statelessBlock).Times(1),
+		s.EXPECT().SetBlockTimestamp(parentID, parentState.timestamp).Times(1),
+		parentOnAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1),
+		s.EXPECT().SetBlockUTXOChanges(parentID, gomock.Any(), gomock.Any()).Times(1),
 
 		s.EXPECT().SetLastAccepted(blkID).Times(1),
 		s.EXPECT().SetHeight(blk.Height()).Times(1),
 		s.EXPECT().AddStatelessBlock(blk).Times(1),
+		s.EXPECT().SetBlockTimestamp(blkID, gomock.Any()).Times(1),
+		onAcceptState.EXPECT().UTXOChanges().Return(nil, nil).Times(1),
+		s.EXPECT().SetBlockUTXOChanges(blkID, gomock.Any(), gomock.Any()).Times(1),
 
 		onAcceptState.EXPECT().Apply(s).Times(1),
 		s.EXPECT().Commit().Return(nil).Times(1),
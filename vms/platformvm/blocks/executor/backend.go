@@ -6,8 +6,10 @@ package executor
 import (
 	"time"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs/mempool"
@@ -29,6 +31,10 @@ type backend struct {
 	blkIDToState map[ids.ID]*blockState
 	state        state.State
 
+	// rejectReasons records why recently dropped blocks failed verification or
+	// were rejected.
+	rejectReasons cache.LRU[ids.ID, string]
+
 	ctx *snow.Context
 }
 
@@ -95,3 +101,22 @@ func (b *backend) getTimestamp(blkID ids.ID) time.Time {
 	// so we just return the chain time.
 	return b.state.GetTimestamp()
 }
+
+// conflicts returns true if [inputs] overlap with the inputs of [blkID] or any
+// of its processing ancestors.
+func (b *backend) conflicts(blkID ids.ID, inputs set.Set[ids.ID]) bool {
+	for {
+		blkState, ok := b.blkIDToState[blkID]
+		if !ok {
+			// The block state isn't pinned in memory.
+			// This means the block must be accepted already.
+			return false
+		}
+
+		if blkState.inputs.Overlaps(inputs) {
+			return true
+		}
+
+		blkID = blkState.statelessBlock.Parent()
+	}
+}
@@ -10,9 +10,13 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 )
 
@@ -34,7 +38,34 @@ func (b *Block) Verify(context.Context) error {
 		return nil
 	}
 
-	return b.Visit(b.manager.verifier)
+	start := time.Now()
+	err := b.Visit(b.manager.verifier)
+	b.manager.metrics.ObserveBlockVerification(getBlockType(b.Block), time.Since(start))
+	if err != nil {
+		b.manager.rejectReasons.Put(blkID, err.Error())
+		return err
+	}
+	b.manager.rejectReasons.Evict(blkID)
+	return nil
+}
+
+// CheckValid returns nil if this block is a valid atomic block on top of its
+// parent. It performs the same checks as [Verify], but the results are
+// discarded, so the block isn't marked as verified and no state is modified.
+// This allows multiple conflicting blocks to be checked one after another.
+// Like [Verify], this reads the in-memory state of processing blocks, so the
+// caller must hold the context lock.
+func (b *Block) CheckValid() error {
+	atomicBlk, ok := b.Block.(*blocks.ApricotAtomicBlock)
+	if !ok {
+		return fmt.Errorf("%w: %T", errNotAtomicBlock, b.Block)
+	}
+
+	v := &verifier{
+		backend:           b.manager.backend,
+		txExecutorBackend: b.manager.txExecutorBackend,
+	}
+	return v.checkApricotAtomicBlock(atomicBlk)
 }
 
 func (b *Block) Accept(context.Context) error {
@@ -42,9 +73,21 @@ func (b *Block) Accept(context.Context) error {
 }
 
 func (b *Block) Reject(context.Context) error {
+	blkID := b.ID()
+	if _, ok := b.manager.rejectReasons.Get(blkID); !ok {
+		b.manager.rejectReasons.Put(blkID, rejectReasonConsensus)
+	}
 	return b.Visit(b.manager.rejector)
 }
 
+// RejectReason returns why this block was dropped, either because it failed
+// verification or because it was rejected by consensus. Returns the empty
+// string if no reason was recorded.
+func (b *Block) RejectReason() string {
+	reason, _ := b.manager.rejectReasons.Get(b.ID())
+	return reason
+}
+
 func (b *Block) Status() choices.Status {
 	blkID := b.ID()
 	// If this block is an accepted Proposal block with no accepted children, it
@@ -79,7 +122,16 @@ func (b *Block) Status() choices.Status {
 }
 
 func (b *Block) Timestamp() time.Time {
-	return b.manager.getTimestamp(b.ID())
+	blkID := b.ID()
+	if _, ok := b.manager.blkIDToState[blkID]; !ok && blkID != b.manager.lastAccepted {
+		// The block isn't processing or the last accepted block, so the chain
+		// time doesn't reflect its timestamp. If the block was accepted, its
+		// timestamp may have been persisted.
+		if timestamp, err := b.manager.state.GetBlockTimestamp(blkID); err == nil {
+			return timestamp
+		}
+	}
+	return b.manager.getTimestamp(blkID)
 }
 
 func (b *Block) Options(context.Context) ([2]snowman.Block, error) {
@@ -88,17 +140,87 @@ func (b *Block) Options(context.Context) ([2]snowman.Block, error) {
 		return [2]snowman.Block{}, err
 	}
 
-	commitBlock := b.manager.NewBlock(options.commitBlock)
-	abortBlock := b.manager.NewBlock(options.abortBlock)
-
 	blkID := b.ID()
 	blkState, ok := b.manager.blkIDToState[blkID]
 	if !ok {
 		return [2]snowman.Block{}, fmt.Errorf("block %s state not found", blkID)
 	}
 
-	if blkState.initiallyPreferCommit {
-		return [2]snowman.Block{commitBlock, abortBlock}, nil
+	preferCommit := blkState.initiallyPreferCommit
+	if b.manager.preferenceFunc != nil {
+		var err error
+		preferCommit, err = b.manager.preferenceFunc(blkID)
+		if err != nil {
+			return [2]snowman.Block{}, fmt.Errorf("failed to compute preference of block %s: %w", blkID, err)
+		}
+	}
+	return b.orderOptions(options, preferCommit), nil
+}
+
+// OptionsWithPreference returns the options of this block ordered by
+// [preferCommit], ignoring the preference determined during verification.
+func (b *Block) OptionsWithPreference(preferCommit bool) ([2]snowman.Block, error) {
+	options := options{}
+	if err := b.Block.Visit(&options); err != nil {
+		return [2]snowman.Block{}, err
+	}
+	return b.orderOptions(options, preferCommit), nil
+}
+
+func (b *Block) orderOptions(options options, preferCommit bool) [2]snowman.Block {
+	commitBlock := b.manager.NewBlock(options.commitBlock)
+	abortBlock := b.manager.NewBlock(options.abortBlock)
+	if preferCommit {
+		return [2]snowman.Block{commitBlock, abortBlock}
+	}
+	return [2]snowman.Block{abortBlock, commitBlock}
+}
+
+// AtomicInputs returns the IDs of the UTXOs this block consumes from shared
+// memory. Unlike Verify, this does not modify any state, so it may be called
+// on blocks that were not verified by this node, including accepted blocks.
+func (b *Block) AtomicInputs() (set.Set[ids.ID], error) {
+	inputs, _, err := b.manager.atomicOutputs(b.Block)
+	return inputs, err
+}
+
+// AtomicRequests returns the shared memory requests that are applied when this
+// block is accepted. Unlike Verify, this does not modify any state, so it may
+// be called on blocks that were not verified by this node, including accepted
+// blocks.
+func (b *Block) AtomicRequests() (map[ids.ID]*atomic.Requests, error) {
+	_, requests, err := b.manager.atomicOutputs(b.Block)
+	return requests, err
+}
+
+// ConflictsWith returns true if [inputs] conflict with the inputs consumed by
+// this block or any of its processing ancestors. Accepted blocks are never
+// considered conflicting.
+//
+// This allows a block builder to check whether a child of this block
+// containing [inputs] would fail verification, without building it.
+func (b *Block) ConflictsWith(inputs set.Set[ids.ID]) (bool, error) {
+	blkID := b.ID()
+	if _, ok := b.manager.blkIDToState[blkID]; !ok {
+		// The block isn't processing, so it must be accepted for its inputs to
+		// be known.
+		if _, err := b.manager.state.GetStatelessBlock(blkID); err != nil {
+			return false, fmt.Errorf("couldn't check conflicts of unverified block %s: %w", blkID, err)
+		}
+	}
+	return b.manager.conflicts(blkID, inputs), nil
+}
+
+// Children returns the IDs of the processing blocks whose parent is this
+// block, in sorted order.
+func (b *Block) Children() []ids.ID {
+	blkID := b.ID()
+	var children []ids.ID
+	for childID, childState := range b.manager.blkIDToState {
+		if childState.statelessBlock.Parent() == blkID {
+			children = append(children, childID)
+		}
 	}
-	return [2]snowman.Block{abortBlock, commitBlock}, nil
+	utils.Sort(children)
+	return children
 }
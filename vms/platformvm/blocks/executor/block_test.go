@@ -5,18 +5,31 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
 
+	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
+	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs/executor"
 )
 
 func TestStatus(t *testing.T) {
@@ -251,3 +264,496 @@ func TestBlockOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockOptionsWithPreference(t *testing.T) {
+	tests := []struct {
+		name                   string
+		blk                    blocks.Block
+		preferCommit           bool
+		expectedPreferenceType blocks.Block
+		expectedOtherType      blocks.Block
+	}{
+		{
+			name:                   "apricot proposal block; commit forced",
+			blk:                    &blocks.ApricotProposalBlock{},
+			preferCommit:           true,
+			expectedPreferenceType: &blocks.ApricotCommitBlock{},
+			expectedOtherType:      &blocks.ApricotAbortBlock{},
+		},
+		{
+			name:                   "apricot proposal block; abort forced",
+			blk:                    &blocks.ApricotProposalBlock{},
+			preferCommit:           false,
+			expectedPreferenceType: &blocks.ApricotAbortBlock{},
+			expectedOtherType:      &blocks.ApricotCommitBlock{},
+		},
+		{
+			name:                   "banff proposal block; commit forced",
+			blk:                    &blocks.BanffProposalBlock{},
+			preferCommit:           true,
+			expectedPreferenceType: &blocks.BanffCommitBlock{},
+			expectedOtherType:      &blocks.BanffAbortBlock{},
+		},
+		{
+			name:                   "banff proposal block; abort forced",
+			blk:                    &blocks.BanffProposalBlock{},
+			preferCommit:           false,
+			expectedPreferenceType: &blocks.BanffAbortBlock{},
+			expectedOtherType:      &blocks.BanffCommitBlock{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			// The stored preference is the opposite of the forced one.
+			blkID := tt.blk.ID()
+			blk := &Block{
+				Block: tt.blk,
+				manager: &manager{
+					backend: &backend{
+						blkIDToState: map[ids.ID]*blockState{
+							blkID: {
+								proposalBlockState: proposalBlockState{
+									initiallyPreferCommit: !tt.preferCommit,
+								},
+							},
+						},
+					},
+				},
+			}
+
+			options, err := blk.OptionsWithPreference(tt.preferCommit)
+			require.NoError(err)
+			require.IsType(tt.expectedPreferenceType, options[0].(*Block).Block)
+			require.IsType(tt.expectedOtherType, options[1].(*Block).Block)
+		})
+	}
+
+	blk := &Block{
+		Block:   &blocks.BanffStandardBlock{},
+		manager: &manager{},
+	}
+	_, err := blk.OptionsWithPreference(true)
+	require.ErrorIs(t, err, snowman.ErrNotOracle)
+}
+
+func TestBlockOptionsPreferenceFunc(t *testing.T) {
+	require := require.New(t)
+
+	innerBlk := &blocks.BanffProposalBlock{}
+	blkID := innerBlk.ID()
+
+	// Verification preferred commit.
+	m := &manager{
+		backend: &backend{
+			blkIDToState: map[ids.ID]*blockState{
+				blkID: {
+					proposalBlockState: proposalBlockState{
+						initiallyPreferCommit: true,
+					},
+				},
+			},
+		},
+	}
+	blk := &Block{
+		Block:   innerBlk,
+		manager: m,
+	}
+
+	var calledWith ids.ID
+	m.SetPreferenceFunc(func(blkID ids.ID) (bool, error) {
+		calledWith = blkID
+		return false, nil
+	})
+
+	options, err := blk.Options(context.Background())
+	require.NoError(err)
+	require.Equal(blkID, calledWith)
+	require.IsType(&blocks.BanffAbortBlock{}, options[0].(*Block).Block)
+	require.IsType(&blocks.BanffCommitBlock{}, options[1].(*Block).Block)
+
+	errPreference := errors.New("preference failed")
+	m.SetPreferenceFunc(func(ids.ID) (bool, error) {
+		return false, errPreference
+	})
+	_, err = blk.Options(context.Background())
+	require.ErrorIs(err, errPreference)
+
+	// Removing the func restores the preference determined during
+	// verification.
+	m.SetPreferenceFunc(nil)
+	options, err = blk.Options(context.Background())
+	require.NoError(err)
+	require.IsType(&blocks.BanffCommitBlock{}, options[0].(*Block).Block)
+	require.IsType(&blocks.BanffAbortBlock{}, options[1].(*Block).Block)
+}
+
+func TestBlockAtomicOutputs(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	parentID := ids.GenerateTestID()
+	s := state.NewMockState(ctrl)
+	s.EXPECT().GetLastAccepted().Return(parentID).AnyTimes()
+	s.EXPECT().GetBlockAtomicRequests(gomock.Any()).Return(nil, database.ErrNotFound).AnyTimes()
+	m := &manager{
+		backend: &backend{
+			blkIDToState: map[ids.ID]*blockState{},
+			state:        s,
+		},
+		txExecutorBackend: &executor.Backend{},
+	}
+
+	// We can't serialize a mock tx because it isn't registered with
+	// blocks.Codec, so the block is created with a dummy tx that is replaced
+	// after creation.
+	atomicBlk, err := blocks.NewApricotAtomicBlock(
+		parentID,
+		1,
+		&txs.Tx{
+			Unsigned: &txs.AdvanceTimeTx{},
+			Creds:    []verify.Verifiable{},
+		},
+	)
+	require.NoError(err)
+
+	inputs := set.Of(ids.GenerateTestID())
+	requests := map[ids.ID]*atomic.Requests{
+		ids.GenerateTestID(): {
+			RemoveRequests: [][]byte{{1}},
+		},
+	}
+	blkTx := txs.NewMockUnsignedTx(ctrl)
+	blkTx.EXPECT().Visit(gomock.AssignableToTypeOf(&executor.AtomicTxExecutor{})).DoAndReturn(
+		func(e *executor.AtomicTxExecutor) error {
+			e.Inputs = inputs
+			e.AtomicRequests = requests
+			return nil
+		},
+	).Times(2)
+	atomicBlk.Tx.Unsigned = blkTx
+
+	// Derived from the tx without verifying the block.
+	blk := m.NewBlock(atomicBlk).(*Block)
+	gotInputs, err := blk.AtomicInputs()
+	require.NoError(err)
+	require.Equal(inputs, gotInputs)
+
+	gotRequests, err := blk.AtomicRequests()
+	require.NoError(err)
+	require.Equal(requests, gotRequests)
+	require.NotContains(m.blkIDToState, atomicBlk.ID())
+
+	// Verified blocks return the values populated during verification.
+	verifiedInputs := set.Of(ids.GenerateTestID())
+	m.blkIDToState[atomicBlk.ID()] = &blockState{
+		standardBlockState: standardBlockState{
+			inputs: verifiedInputs,
+		},
+		atomicRequests: requests,
+	}
+	gotInputs, err = blk.AtomicInputs()
+	require.NoError(err)
+	require.Equal(verifiedInputs, gotInputs)
+	delete(m.blkIDToState, atomicBlk.ID())
+
+	// Missing parent state.
+	orphanBlk, err := blocks.NewApricotAtomicBlock(
+		ids.GenerateTestID(),
+		1,
+		&txs.Tx{
+			Unsigned: &txs.AdvanceTimeTx{},
+			Creds:    []verify.Verifiable{},
+		},
+	)
+	require.NoError(err)
+	_, err = m.NewBlock(orphanBlk).(*Block).AtomicRequests()
+	require.ErrorIs(err, state.ErrMissingParentState)
+
+	// Non atomic block.
+	_, err = m.NewBlock(&blocks.BanffStandardBlock{}).(*Block).AtomicInputs()
+	require.ErrorIs(err, errNotAtomicBlock)
+
+	// Accepted blocks return the atomic requests stored in the state, and the
+	// UTXOs they removed as their inputs.
+	acceptedInput := ids.GenerateTestID()
+	acceptedRequests := map[ids.ID]*atomic.Requests{
+		ids.GenerateTestID(): {
+			RemoveRequests: [][]byte{acceptedInput[:]},
+		},
+	}
+	acceptedState := state.NewMockState(ctrl)
+	acceptedState.EXPECT().GetLastAccepted().Return(atomicBlk.ID()).AnyTimes()
+	acceptedState.EXPECT().GetBlockAtomicRequests(atomicBlk.ID()).Return(acceptedRequests, nil).Times(2)
+	m.backend.state = acceptedState
+	gotInputs, err = blk.AtomicInputs()
+	require.NoError(err)
+	require.Equal(set.Of(acceptedInput), gotInputs)
+
+	gotRequests, err = blk.AtomicRequests()
+	require.NoError(err)
+	require.Equal(acceptedRequests, gotRequests)
+
+	// Accepted blocks without stored atomic requests can't be derived because
+	// their parent's state is no longer available.
+	acceptedState.EXPECT().GetBlockAtomicRequests(atomicBlk.ID()).Return(nil, database.ErrNotFound)
+	_, err = blk.AtomicInputs()
+	require.ErrorIs(err, state.ErrMissingParentState)
+}
+
+func TestBlockConflictsWith(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	// Chain: acceptedID <- parentID <- blkID, where only [parentID] and [blkID]
+	// are processing.
+	acceptedID := ids.GenerateTestID()
+	parentID := ids.GenerateTestID()
+	blkID := ids.GenerateTestID()
+
+	parentStatelessBlk := blocks.NewMockBlock(ctrl)
+	parentStatelessBlk.EXPECT().Parent().Return(acceptedID).AnyTimes()
+	statelessBlk := blocks.NewMockBlock(ctrl)
+	statelessBlk.EXPECT().ID().Return(blkID).AnyTimes()
+	statelessBlk.EXPECT().Parent().Return(parentID).AnyTimes()
+
+	parentInput := ids.GenerateTestID()
+	blkInput := ids.GenerateTestID()
+	acceptedInput := ids.GenerateTestID()
+
+	s := state.NewMockState(ctrl)
+	m := &manager{
+		backend: &backend{
+			blkIDToState: map[ids.ID]*blockState{
+				parentID: {
+					statelessBlock: parentStatelessBlk,
+					standardBlockState: standardBlockState{
+						inputs: set.Of(parentInput),
+					},
+				},
+				blkID: {
+					statelessBlock: statelessBlk,
+					standardBlockState: standardBlockState{
+						inputs: set.Of(blkInput),
+					},
+				},
+			},
+			state: s,
+		},
+	}
+	blk := m.NewBlock(statelessBlk).(*Block)
+
+	conflicts, err := blk.ConflictsWith(set.Of(parentInput))
+	require.NoError(err)
+	require.True(conflicts)
+
+	conflicts, err = blk.ConflictsWith(set.Of(blkInput))
+	require.NoError(err)
+	require.True(conflicts)
+
+	// Inputs of accepted blocks aren't tracked, so they never conflict.
+	conflicts, err = blk.ConflictsWith(set.Of(acceptedInput))
+	require.NoError(err)
+	require.False(conflicts)
+
+	// Accepted blocks have no processing ancestors.
+	acceptedStatelessBlk := blocks.NewMockBlock(ctrl)
+	acceptedStatelessBlk.EXPECT().ID().Return(acceptedID).AnyTimes()
+	s.EXPECT().GetStatelessBlock(acceptedID).Return(acceptedStatelessBlk, nil)
+	conflicts, err = m.NewBlock(acceptedStatelessBlk).(*Block).ConflictsWith(set.Of(parentInput))
+	require.NoError(err)
+	require.False(conflicts)
+
+	// Unknown blocks can't be checked.
+	unknownID := ids.GenerateTestID()
+	unknownStatelessBlk := blocks.NewMockBlock(ctrl)
+	unknownStatelessBlk.EXPECT().ID().Return(unknownID).AnyTimes()
+	s.EXPECT().GetStatelessBlock(unknownID).Return(nil, database.ErrNotFound)
+	_, err = m.NewBlock(unknownStatelessBlk).(*Block).ConflictsWith(set.Of(parentInput))
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestBlockTimestamp(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	chainTime := time.Unix(1000, 0)
+	persistedTime := time.Unix(500, 0)
+
+	s := state.NewMockState(ctrl)
+	s.EXPECT().GetTimestamp().Return(chainTime).AnyTimes()
+
+	statelessBlk, err := blocks.NewBanffStandardBlock(persistedTime, ids.GenerateTestID(), 1, nil)
+	require.NoError(err)
+	blkID := statelessBlk.ID()
+
+	manager := &manager{
+		backend: &backend{
+			state:        s,
+			blkIDToState: map[ids.ID]*blockState{},
+			lastAccepted: ids.GenerateTestID(),
+		},
+	}
+	blk := manager.NewBlock(statelessBlk)
+
+	// An accepted block reports its persisted timestamp.
+	s.EXPECT().GetBlockTimestamp(blkID).Return(persistedTime, nil)
+	require.Equal(persistedTime, blk.Timestamp())
+
+	// Without a persisted timestamp, the chain time is reported.
+	s.EXPECT().GetBlockTimestamp(blkID).Return(time.Time{}, database.ErrNotFound)
+	require.Equal(chainTime, blk.Timestamp())
+
+	// The last accepted block reports the chain time.
+	manager.backend.lastAccepted = blkID
+	require.Equal(chainTime, blk.Timestamp())
+}
+
+func TestBlockRejectReason(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	s := state.NewMockState(ctrl)
+
+	grandParentID := ids.GenerateTestID()
+	grandParentStatelessBlk := blocks.NewMockBlock(ctrl)
+	parentID := ids.GenerateTestID()
+	parentStatelessBlk := blocks.NewMockBlock(ctrl)
+	parentState := state.NewMockDiff(ctrl)
+	atomicInputs := set.Of(ids.GenerateTestID())
+
+	backend := &backend{
+		blkIDToState: map[ids.ID]*blockState{
+			grandParentID: {
+				standardBlockState: standardBlockState{
+					inputs: atomicInputs,
+				},
+				statelessBlock: grandParentStatelessBlk,
+			},
+			parentID: {
+				statelessBlock: parentStatelessBlk,
+				onAcceptState:  parentState,
+			},
+		},
+		state: s,
+		ctx: &snow.Context{
+			Log: logging.NoLog{},
+		},
+	}
+	manager := &manager{
+		backend: backend,
+		metrics: metrics.Noop,
+		verifier: &verifier{
+			txExecutorBackend: &executor.Backend{
+				Config: &config.Config{
+					ApricotPhase5Time: time.Now().Add(time.Hour),
+					BanffTime:         mockable.MaxTime, // banff is not activated
+				},
+				Clk: &mockable.Clock{},
+			},
+			backend: backend,
+		},
+		rejector: &rejector{
+			backend: backend,
+		},
+	}
+
+	blkTx := txs.NewMockUnsignedTx(ctrl)
+	blkTx.EXPECT().Visit(gomock.AssignableToTypeOf(&executor.StandardTxExecutor{})).DoAndReturn(
+		func(e *executor.StandardTxExecutor) error {
+			e.OnAccept = func() {}
+			e.Inputs = atomicInputs
+			return nil
+		},
+	).Times(1)
+
+	// We can't serialize [blkTx] because it isn't registered with the
+	// blocks.Codec. Serialize this block with a dummy tx and replace it after
+	// creation with the mock tx.
+	statelessBlk, err := blocks.NewApricotStandardBlock(
+		parentID,
+		2,
+		[]*txs.Tx{
+			{
+				Unsigned: &txs.AdvanceTimeTx{},
+				Creds:    []verify.Verifiable{},
+			},
+		},
+	)
+	require.NoError(err)
+	statelessBlk.Transactions[0].Unsigned = blkTx
+
+	parentStatelessBlk.EXPECT().Height().Return(uint64(1)).Times(1)
+	parentState.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	parentStatelessBlk.EXPECT().Parent().Return(grandParentID).Times(1)
+
+	blk := manager.NewBlock(statelessBlk).(*Block)
+	require.Empty(blk.RejectReason())
+
+	err = blk.Verify(context.Background())
+	require.ErrorIs(err, errConflictingParentTxs)
+	require.Equal(errConflictingParentTxs.Error(), blk.RejectReason())
+
+	// Rejecting the block keeps the verification failure as the reason.
+	require.NoError(blk.Reject(context.Background()))
+	require.Equal(errConflictingParentTxs.Error(), blk.RejectReason())
+
+	// A block rejected by consensus records that it was rejected.
+	otherStatelessBlk, err := blocks.NewBanffStandardBlock(time.Now(), parentID, 2, nil)
+	require.NoError(err)
+	otherBlk := manager.NewBlock(otherStatelessBlk).(*Block)
+	require.NoError(otherBlk.Reject(context.Background()))
+	require.Equal(rejectReasonConsensus, otherBlk.RejectReason())
+}
+
+func TestBlockChildren(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	// Tree:
+	//       parentID
+	//      /        \
+	//  child0ID   child1ID
+	//     |
+	//  grandchildID
+	parentID := ids.GenerateTestID()
+	child0ID := ids.GenerateTestID()
+	child1ID := ids.GenerateTestID()
+	grandchildID := ids.GenerateTestID()
+
+	newStatelessBlk := func(blkID, parentID ids.ID) *blocks.MockBlock {
+		blk := blocks.NewMockBlock(ctrl)
+		blk.EXPECT().ID().Return(blkID).AnyTimes()
+		blk.EXPECT().Parent().Return(parentID).AnyTimes()
+		return blk
+	}
+	parentStatelessBlk := newStatelessBlk(parentID, ids.GenerateTestID())
+	child0StatelessBlk := newStatelessBlk(child0ID, parentID)
+	child1StatelessBlk := newStatelessBlk(child1ID, parentID)
+	grandchildStatelessBlk := newStatelessBlk(grandchildID, child0ID)
+
+	m := &manager{
+		backend: &backend{
+			blkIDToState: map[ids.ID]*blockState{
+				parentID:     {statelessBlock: parentStatelessBlk},
+				child0ID:     {statelessBlock: child0StatelessBlk},
+				child1ID:     {statelessBlock: child1StatelessBlk},
+				grandchildID: {statelessBlock: grandchildStatelessBlk},
+			},
+		},
+	}
+
+	expectedChildren := []ids.ID{child0ID, child1ID}
+	utils.Sort(expectedChildren)
+	require.Equal(expectedChildren, m.NewBlock(parentStatelessBlk).(*Block).Children())
+	require.Equal([]ids.ID{grandchildID}, m.NewBlock(child0StatelessBlk).(*Block).Children())
+	require.Empty(m.NewBlock(child1StatelessBlk).(*Block).Children())
+
+	// Children that are no longer processing aren't reported.
+	delete(m.blkIDToState, child1ID)
+	require.Equal([]ids.ID{child0ID}, m.NewBlock(parentStatelessBlk).(*Block).Children())
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import "github.com/ava-labs/avalanchego/vms/platformvm/blocks"
+
+var _ blocks.Visitor = (*blockTyper)(nil)
+
+// getBlockType returns the human readable type of [blk], such as
+// "ApricotStandard" or "BanffProposal".
+func getBlockType(blk blocks.Block) string {
+	typer := blockTyper{}
+	_ = blk.Visit(&typer) // blockTyper never returns an error
+	return typer.blockType
+}
+
+// blockTyper resolves the human readable type of a block
+type blockTyper struct {
+	// output populated by this struct's methods:
+	blockType string
+}
+
+func (t *blockTyper) BanffAbortBlock(*blocks.BanffAbortBlock) error {
+	t.blockType = "BanffAbort"
+	return nil
+}
+
+func (t *blockTyper) BanffCommitBlock(*blocks.BanffCommitBlock) error {
+	t.blockType = "BanffCommit"
+	return nil
+}
+
+func (t *blockTyper) BanffProposalBlock(*blocks.BanffProposalBlock) error {
+	t.blockType = "BanffProposal"
+	return nil
+}
+
+func (t *blockTyper) BanffStandardBlock(*blocks.BanffStandardBlock) error {
+	t.blockType = "BanffStandard"
+	return nil
+}
+
+func (t *blockTyper) ApricotAbortBlock(*blocks.ApricotAbortBlock) error {
+	t.blockType = "ApricotAbort"
+	return nil
+}
+
+func (t *blockTyper) ApricotCommitBlock(*blocks.ApricotCommitBlock) error {
+	t.blockType = "ApricotCommit"
+	return nil
+}
+
+func (t *blockTyper) ApricotProposalBlock(*blocks.ApricotProposalBlock) error {
+	t.blockType = "ApricotProposal"
+	return nil
+}
+
+func (t *blockTyper) ApricotStandardBlock(*blocks.ApricotStandardBlock) error {
+	t.blockType = "ApricotStandard"
+	return nil
+}
+
+func (t *blockTyper) ApricotAtomicBlock(*blocks.ApricotAtomicBlock) error {
+	t.blockType = "ApricotAtomic"
+	return nil
+}
@@ -4,17 +4,36 @@
 package executor
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/chains/atomic"
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs/executor"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs/mempool"
 	"github.com/ava-labs/avalanchego/vms/platformvm/validators"
 )
 
-var _ Manager = (*manager)(nil)
+const (
+	rejectReasonCacheSize = 256
+
+	rejectReasonConsensus = "rejected by consensus"
+)
+
+var (
+	_ Manager = (*manager)(nil)
+
+	errNotAtomicBlock = errors.New("not an atomic block")
+)
 
 type Manager interface {
 	state.Versions
@@ -24,6 +43,28 @@ type Manager interface {
 	GetBlock(blkID ids.ID) (snowman.Block, error)
 	GetStatelessBlock(blkID ids.ID) (blocks.Block, error)
 	NewBlock(blocks.Block) snowman.Block
+
+	// BlockType returns the human readable type of the block with [blkID],
+	// such as "ApricotStandard" or "BanffProposal".
+	BlockType(blkID ids.ID) (string, error)
+
+	// SetPreferenceFunc overrides how the preferred option of a verified
+	// proposal block is chosen. [preferenceFunc] is called with the ID of the
+	// proposal block. If [preferenceFunc] is nil, the preference determined
+	// during verification is used.
+	SetPreferenceFunc(preferenceFunc func(blkID ids.ID) (preferCommit bool, err error))
+
+	// Orphans returns the IDs of the processing blocks that can never be
+	// accepted because an ancestor was rejected. That is, the blocks whose
+	// processing ancestors don't lead back to the last accepted block.
+	// The IDs are sorted.
+	Orphans() []ids.ID
+
+	// SimulateTx returns true iff [tx] would be accepted if it were issued on
+	// top of the last accepted block. If it wouldn't be, the returned error
+	// is the reason. The tx is executed against a diff of the last accepted
+	// state that is discarded, so no state is modified.
+	SimulateTx(tx *txs.Tx) (bool, error)
 }
 
 func NewManager(
@@ -39,10 +80,15 @@ func NewManager(
 		state:        s,
 		ctx:          txExecutorBackend.Ctx,
 		blkIDToState: map[ids.ID]*blockState{},
+		rejectReasons: cache.LRU[ids.ID, string]{
+			Size: rejectReasonCacheSize,
+		},
 	}
 
 	return &manager{
-		backend: backend,
+		backend:           backend,
+		metrics:           metrics,
+		txExecutorBackend: txExecutorBackend,
 		verifier: &verifier{
 			backend:           backend,
 			txExecutorBackend: txExecutorBackend,
@@ -62,9 +108,15 @@ func NewManager(
 
 type manager struct {
 	*backend
-	verifier blocks.Visitor
-	acceptor blocks.Visitor
-	rejector blocks.Visitor
+	metrics           metrics.Metrics
+	txExecutorBackend *executor.Backend
+	verifier          blocks.Visitor
+	acceptor          blocks.Visitor
+	rejector          blocks.Visitor
+
+	// preferenceFunc, if non-nil, replaces the preference determined during
+	// verification when ordering the options of a proposal block.
+	preferenceFunc func(blkID ids.ID) (bool, error)
 }
 
 func (m *manager) GetBlock(blkID ids.ID) (snowman.Block, error) {
@@ -85,3 +137,127 @@ func (m *manager) NewBlock(blk blocks.Block) snowman.Block {
 		Block:   blk,
 	}
 }
+
+func (m *manager) BlockType(blkID ids.ID) (string, error) {
+	blk, err := m.backend.GetBlock(blkID)
+	if err != nil {
+		return "", err
+	}
+
+	return getBlockType(blk), nil
+}
+
+func (m *manager) SetPreferenceFunc(preferenceFunc func(blkID ids.ID) (bool, error)) {
+	m.preferenceFunc = preferenceFunc
+}
+
+func (m *manager) Orphans() []ids.ID {
+	// Memoizes whether each block visited is an orphan.
+	isOrphan := make(map[ids.ID]bool, len(m.blkIDToState))
+	var orphan func(blkID ids.ID) bool
+	orphan = func(blkID ids.ID) bool {
+		if blkID == m.lastAccepted {
+			return false
+		}
+		if result, ok := isOrphan[blkID]; ok {
+			return result
+		}
+		blkState, ok := m.blkIDToState[blkID]
+		// A block that is neither processing nor the last accepted block was
+		// rejected or accepted before the last accepted block.
+		result := !ok || orphan(blkState.statelessBlock.Parent())
+		isOrphan[blkID] = result
+		return result
+	}
+
+	var orphans []ids.ID
+	for blkID := range m.blkIDToState {
+		if orphan(blkID) {
+			orphans = append(orphans, blkID)
+		}
+	}
+	utils.Sort(orphans)
+	return orphans
+}
+
+func (m *manager) SimulateTx(tx *txs.Tx) (bool, error) {
+	verifier := executor.MempoolTxVerifier{
+		Backend:       m.txExecutorBackend,
+		ParentID:      m.lastAccepted,
+		StateVersions: m,
+		Tx:            tx,
+	}
+	if err := tx.Unsigned.Visit(&verifier); err != nil {
+		return false, fmt.Errorf("tx %s failed semantic verification: %w", tx.ID(), err)
+	}
+	return true, nil
+}
+
+// atomicOutputs returns the atomic inputs and atomic requests of [blk].
+//
+// If [blk] has been verified, the values populated during verification are
+// returned. If [blk] has been accepted, the atomic requests that were applied
+// to shared memory are read from the state, and the atomic inputs are the
+// UTXOs they removed. Otherwise, they are derived by executing the atomic tx
+// of [blk] on top of its parent's state. The resulting diff is discarded, so
+// no state is modified.
+//
+// If [blk] is neither processing nor accepted with its atomic requests
+// persisted, its parent's state may not be available, in which case
+// [state.ErrMissingParentState] is returned.
+func (m *manager) atomicOutputs(blk blocks.Block) (set.Set[ids.ID], map[ids.ID]*atomic.Requests, error) {
+	blkID := blk.ID()
+	if blkState, ok := m.blkIDToState[blkID]; ok {
+		return blkState.inputs, blkState.atomicRequests, nil
+	}
+
+	atomicBlk, ok := blk.(*blocks.ApricotAtomicBlock)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %T", errNotAtomicBlock, blk)
+	}
+
+	requests, err := m.state.GetBlockAtomicRequests(blkID)
+	switch err {
+	case nil:
+		inputs, err := removedUTXOIDs(requests)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse atomic requests of block %s: %w", blkID, err)
+		}
+		return inputs, requests, nil
+	case database.ErrNotFound:
+	default:
+		return nil, nil, fmt.Errorf("failed to get atomic requests of block %s: %w", blkID, err)
+	}
+
+	parentID := atomicBlk.Parent()
+	if _, ok := m.GetState(parentID); !ok {
+		return nil, nil, fmt.Errorf("%w: %s", state.ErrMissingParentState, parentID)
+	}
+
+	atomicExecutor := executor.AtomicTxExecutor{
+		Backend:       m.txExecutorBackend,
+		ParentID:      parentID,
+		StateVersions: m,
+		Tx:            atomicBlk.Tx,
+	}
+	if err := atomicBlk.Tx.Unsigned.Visit(&atomicExecutor); err != nil {
+		return nil, nil, fmt.Errorf("tx %s failed semantic verification: %w", atomicBlk.Tx.ID(), err)
+	}
+	return atomicExecutor.Inputs, atomicExecutor.AtomicRequests, nil
+}
+
+// removedUTXOIDs returns the IDs of the UTXOs that [requests] remove from
+// shared memory.
+func removedUTXOIDs(requests map[ids.ID]*atomic.Requests) (set.Set[ids.ID], error) {
+	var utxoIDs set.Set[ids.ID]
+	for _, chainRequests := range requests {
+		for _, utxoIDBytes := range chainRequests.RemoveRequests {
+			utxoID, err := ids.ToID(utxoIDBytes)
+			if err != nil {
+				return nil, err
+			}
+			utxoIDs.Add(utxoID)
+		}
+	}
+	return utxoIDs, nil
+}
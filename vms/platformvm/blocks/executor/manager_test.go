@@ -4,16 +4,27 @@
 package executor
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"go.uber.org/mock/gomock"
 
+	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs/executor"
 )
 
 func TestGetBlock(t *testing.T) {
@@ -72,3 +83,224 @@ func TestManagerLastAccepted(t *testing.T) {
 
 	require.Equal(t, lastAcceptedID, manager.LastAccepted())
 }
+
+func TestManagerOrphans(t *testing.T) {
+	require := require.New(t)
+
+	lastAcceptedID := ids.GenerateTestID()
+
+	// A chain of processing blocks on top of the last accepted block.
+	builder := blocks.NewBlockChainBuilder(lastAcceptedID, 0)
+	for i := 0; i < 3; i++ {
+		_, err := builder.AddApricotStandard()
+		require.NoError(err)
+	}
+	chain := builder.Blocks()
+
+	// A conflicting sibling of the first block of the chain.
+	sibling, err := blocks.NewBanffStandardBlock(time.Unix(1, 0), lastAcceptedID, 1, nil)
+	require.NoError(err)
+
+	backend := &backend{
+		lastAccepted: lastAcceptedID,
+		blkIDToState: map[ids.ID]*blockState{},
+		rejectReasons: cache.LRU[ids.ID, string]{
+			Size: rejectReasonCacheSize,
+		},
+		ctx: &snow.Context{
+			Log: logging.NoLog{},
+		},
+	}
+	for _, blk := range []blocks.Block{chain[0], chain[1], chain[2], sibling} {
+		backend.blkIDToState[blk.ID()] = &blockState{
+			statelessBlock: blk,
+		}
+	}
+	manager := &manager{
+		backend: backend,
+		rejector: &rejector{
+			backend: backend,
+		},
+	}
+	require.Empty(manager.Orphans())
+
+	// Rejecting the first block of the chain orphans its descendants.
+	require.NoError(manager.NewBlock(chain[0]).Reject(context.Background()))
+
+	expectedOrphans := []ids.ID{chain[1].ID(), chain[2].ID()}
+	utils.Sort(expectedOrphans)
+	require.Equal(expectedOrphans, manager.Orphans())
+}
+
+func TestManagerBlockType(t *testing.T) {
+	var (
+		parentID  = ids.GenerateTestID()
+		height    = uint64(2)
+		timestamp = time.Now()
+		tx        = &txs.Tx{
+			Unsigned: &txs.AdvanceTimeTx{},
+			Creds:    []verify.Verifiable{},
+		}
+	)
+
+	tests := []struct {
+		name         string
+		blkF         func() (blocks.Block, error)
+		expectedType string
+	}{
+		{
+			name: "banff abort",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewBanffAbortBlock(timestamp, parentID, height)
+			},
+			expectedType: "BanffAbort",
+		},
+		{
+			name: "banff commit",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewBanffCommitBlock(timestamp, parentID, height)
+			},
+			expectedType: "BanffCommit",
+		},
+		{
+			name: "banff proposal",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewBanffProposalBlock(timestamp, parentID, height, tx)
+			},
+			expectedType: "BanffProposal",
+		},
+		{
+			name: "banff standard",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewBanffStandardBlock(timestamp, parentID, height, []*txs.Tx{tx})
+			},
+			expectedType: "BanffStandard",
+		},
+		{
+			name: "apricot abort",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewApricotAbortBlock(parentID, height)
+			},
+			expectedType: "ApricotAbort",
+		},
+		{
+			name: "apricot commit",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewApricotCommitBlock(parentID, height)
+			},
+			expectedType: "ApricotCommit",
+		},
+		{
+			name: "apricot proposal",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewApricotProposalBlock(parentID, height, tx)
+			},
+			expectedType: "ApricotProposal",
+		},
+		{
+			name: "apricot standard",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewApricotStandardBlock(parentID, height, []*txs.Tx{tx})
+			},
+			expectedType: "ApricotStandard",
+		},
+		{
+			name: "apricot atomic",
+			blkF: func() (blocks.Block, error) {
+				return blocks.NewApricotAtomicBlock(parentID, height, tx)
+			},
+			expectedType: "ApricotAtomic",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			statelessBlk, err := test.blkF()
+			require.NoError(err)
+			blkID := statelessBlk.ID()
+
+			state := state.NewMockState(ctrl)
+			manager := &manager{
+				backend: &backend{
+					state:        state,
+					blkIDToState: map[ids.ID]*blockState{},
+				},
+			}
+
+			// Case: block is in database
+			state.EXPECT().GetStatelessBlock(blkID).Return(statelessBlk, nil).Times(1)
+			blkType, err := manager.BlockType(blkID)
+			require.NoError(err)
+			require.Equal(test.expectedType, blkType)
+
+			// Case: block is in memory
+			manager.blkIDToState[blkID] = &blockState{
+				statelessBlock: statelessBlk,
+			}
+			blkType, err = manager.BlockType(blkID)
+			require.NoError(err)
+			require.Equal(test.expectedType, blkType)
+		})
+	}
+
+	// Case: block isn't in memory or database
+	ctrl := gomock.NewController(t)
+	state := state.NewMockState(ctrl)
+	manager := &manager{
+		backend: &backend{
+			state:        state,
+			blkIDToState: map[ids.ID]*blockState{},
+		},
+	}
+	blkID := ids.GenerateTestID()
+	state.EXPECT().GetStatelessBlock(blkID).Return(nil, database.ErrNotFound).Times(1)
+	_, err := manager.BlockType(blkID)
+	require.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestManagerSimulateTx(t *testing.T) {
+	require := require.New(t)
+
+	env := newEnvironment(t, nil)
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+
+	tx, err := env.txBuilder.NewExportTx(
+		1, // amount
+		xChainID,
+		preFundedKeys[0].PublicKey().Address(),
+		[]*secp256k1.PrivateKey{preFundedKeys[0]},
+		preFundedKeys[0].PublicKey().Address(),
+	)
+	require.NoError(err)
+
+	accepted, err := env.blkManager.SimulateTx(tx)
+	require.NoError(err)
+	require.True(accepted)
+
+	// Simulating the tx didn't consume its inputs.
+	accepted, err = env.blkManager.SimulateTx(tx)
+	require.NoError(err)
+	require.True(accepted)
+	_, _, err = env.state.GetTx(tx.ID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Once the tx is accepted, simulating it again is a double spend.
+	stateDiff, err := state.NewDiff(env.state.GetLastAccepted(), env.blkManager)
+	require.NoError(err)
+	require.NoError(tx.Unsigned.Visit(&executor.StandardTxExecutor{
+		Backend: env.backend,
+		State:   stateDiff,
+		Tx:      tx,
+	}))
+	stateDiff.AddTx(tx, status.Committed)
+	require.NoError(stateDiff.Apply(env.state))
+
+	accepted, err = env.blkManager.SimulateTx(tx)
+	require.ErrorIs(err, database.ErrNotFound)
+	require.False(accepted)
+}
@@ -14,6 +14,7 @@ import (
 	snowman "github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	blocks "github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 	state "github.com/ava-labs/avalanchego/vms/platformvm/state"
+	txs "github.com/ava-labs/avalanchego/vms/platformvm/txs"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -40,6 +41,21 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// BlockType mocks base method.
+func (m *MockManager) BlockType(arg0 ids.ID) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockType", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockType indicates an expected call of BlockType.
+func (mr *MockManagerMockRecorder) BlockType(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockType", reflect.TypeOf((*MockManager)(nil).BlockType), arg0)
+}
+
 // GetBlock mocks base method.
 func (m *MockManager) GetBlock(arg0 ids.ID) (snowman.Block, error) {
 	m.ctrl.T.Helper()
@@ -112,3 +128,44 @@ func (mr *MockManagerMockRecorder) NewBlock(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewBlock", reflect.TypeOf((*MockManager)(nil).NewBlock), arg0)
 }
+
+// Orphans mocks base method.
+func (m *MockManager) Orphans() []ids.ID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Orphans")
+	ret0, _ := ret[0].([]ids.ID)
+	return ret0
+}
+
+// Orphans indicates an expected call of Orphans.
+func (mr *MockManagerMockRecorder) Orphans() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Orphans", reflect.TypeOf((*MockManager)(nil).Orphans))
+}
+
+// SetPreferenceFunc mocks base method.
+func (m *MockManager) SetPreferenceFunc(arg0 func(ids.ID) (bool, error)) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPreferenceFunc", arg0)
+}
+
+// SetPreferenceFunc indicates an expected call of SetPreferenceFunc.
+func (mr *MockManagerMockRecorder) SetPreferenceFunc(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferenceFunc", reflect.TypeOf((*MockManager)(nil).SetPreferenceFunc), arg0)
+}
+
+// SimulateTx mocks base method.
+func (m *MockManager) SimulateTx(arg0 *txs.Tx) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimulateTx", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateTx indicates an expected call of SimulateTx.
+func (mr *MockManagerMockRecorder) SimulateTx(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateTx", reflect.TypeOf((*MockManager)(nil).SimulateTx), arg0)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow/validators"
+	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -295,6 +296,123 @@ func TestBanffStandardBlockTimeVerification(t *testing.T) {
 	}
 }
 
+func TestBanffStandardBlockTimeVerificationWithApricotParent(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	env := newEnvironment(t, ctrl)
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+	env.config.BanffTime = time.Time{} // activate Banff
+
+	// Apricot blocks carry no explicit timestamp, so the child must be
+	// verified against the chain time resulting from the parent.
+	parentHeight := uint64(2022)
+	apricotParentBlk, err := blocks.NewApricotStandardBlock(
+		ids.Empty, // does not matter
+		parentHeight,
+		nil, // txs do not matter in this test
+	)
+	require.NoError(err)
+	parentID := apricotParentBlk.ID()
+
+	onParentAccept := state.NewMockDiff(ctrl)
+	chainTime := env.clk.Time().Truncate(time.Second)
+	env.blkManager.(*manager).blkIDToState[parentID] = &blockState{
+		statelessBlock: apricotParentBlk,
+		onAcceptState:  onParentAccept,
+	}
+	env.blkManager.(*manager).lastAccepted = parentID
+	env.mockedState.EXPECT().GetLastAccepted().Return(parentID).AnyTimes()
+	env.mockedState.EXPECT().GetTimestamp().Return(chainTime).AnyTimes()
+
+	nextStakerTime := chainTime.Add(executor.SyncBound).Add(-1 * time.Second)
+	currentStakerIt := state.NewMockStakerIterator(ctrl)
+	currentStakerIt.EXPECT().Next().Return(true).AnyTimes()
+	currentStakerIt.EXPECT().Value().Return(
+		&state.Staker{
+			NextTime: nextStakerTime,
+			Priority: txs.PrimaryNetworkValidatorCurrentPriority,
+		},
+	).AnyTimes()
+	currentStakerIt.EXPECT().Release().Return().AnyTimes()
+	onParentAccept.EXPECT().GetCurrentStakerIterator().Return(currentStakerIt, nil).AnyTimes()
+
+	pendingIt := state.NewMockStakerIterator(ctrl)
+	pendingIt.EXPECT().Next().Return(false).AnyTimes()
+	pendingIt.EXPECT().Release().Return().AnyTimes()
+	onParentAccept.EXPECT().GetPendingStakerIterator().Return(pendingIt, nil).AnyTimes()
+
+	onParentAccept.EXPECT().GetTimestamp().Return(chainTime).AnyTimes()
+
+	utxo := &avax.UTXO{
+		UTXOID: avax.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: avax.Asset{
+			ID: avaxAssetID,
+		},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: env.config.CreateSubnetTxFee,
+		},
+	}
+	onParentAccept.EXPECT().GetUTXO(utxo.InputID()).Return(utxo, nil).AnyTimes()
+
+	utx := &txs.CreateSubnetTx{
+		BaseTx: txs.BaseTx{BaseTx: avax.BaseTx{
+			NetworkID:    env.ctx.NetworkID,
+			BlockchainID: env.ctx.ChainID,
+			Ins: []*avax.TransferableInput{{
+				UTXOID: utxo.UTXOID,
+				Asset:  utxo.Asset,
+				In: &secp256k1fx.TransferInput{
+					Amt: env.config.CreateSubnetTxFee,
+				},
+			}},
+		}},
+		Owner: &secp256k1fx.OutputOwners{},
+	}
+	tx := &txs.Tx{Unsigned: utx}
+	require.NoError(tx.Sign(txs.Codec, [][]*secp256k1.PrivateKey{{}}))
+
+	tests := []struct {
+		name        string
+		timestamp   time.Time
+		expectedErr error
+	}{
+		{
+			name:        "decreasing timestamp",
+			timestamp:   chainTime.Add(-1 * time.Second),
+			expectedErr: errChildBlockEarlierThanParent,
+		},
+		{
+			name:        "equal timestamp",
+			timestamp:   chainTime,
+			expectedErr: nil,
+		},
+		{
+			name:        "increasing timestamp",
+			timestamp:   chainTime.Add(time.Second),
+			expectedErr: nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			banffChildBlk, err := blocks.NewBanffStandardBlock(
+				test.timestamp,
+				parentID,
+				parentHeight+1,
+				[]*txs.Tx{tx},
+			)
+			require.NoError(err)
+			block := env.blkManager.NewBlock(banffChildBlk)
+			err = block.Verify(context.Background())
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
 func TestBanffStandardBlockUpdatePrimaryNetworkStakers(t *testing.T) {
 	require := require.New(t)
 
@@ -844,3 +962,57 @@ func TestBanffStandardBlockDelegatorStakerWeight(t *testing.T) {
 	vdrWeight = primarySet.GetWeight(nodeID)
 	require.Equal(env.config.MinDelegatorStake+env.config.MinValidatorStake, vdrWeight)
 }
+
+func TestBanffStandardBlockUTXOChanges(t *testing.T) {
+	require := require.New(t)
+
+	env := newEnvironment(t, nil)
+	defer func() {
+		require.NoError(shutdownEnvironment(env))
+	}()
+	env.config.BanffTime = time.Time{} // activate Banff
+
+	tx, err := env.txBuilder.NewExportTx(
+		1, // amount
+		xChainID,
+		preFundedKeys[0].PublicKey().Address(),
+		[]*secp256k1.PrivateKey{preFundedKeys[0]},
+		preFundedKeys[0].PublicKey().Address(),
+	)
+	require.NoError(err)
+
+	parentID := env.state.GetLastAccepted()
+	parentBlk, err := env.state.GetStatelessBlock(parentID)
+	require.NoError(err)
+	statelessBlk, err := blocks.NewBanffStandardBlock(
+		env.state.GetTimestamp(),
+		parentID,
+		parentBlk.Height()+1,
+		[]*txs.Tx{tx},
+	)
+	require.NoError(err)
+	blk := env.blkManager.NewBlock(statelessBlk)
+	require.NoError(blk.Verify(context.Background()))
+
+	_, _, err = env.state.GetBlockUTXOChanges(blk.ID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(blk.Accept(context.Background()))
+
+	consumed, produced, err := env.state.GetBlockUTXOChanges(blk.ID())
+	require.NoError(err)
+
+	expectedConsumed := tx.Unsigned.InputIDs().List()
+	utils.Sort(expectedConsumed)
+	require.Equal(expectedConsumed, consumed)
+
+	// The exported UTXOs are in shared memory, so only the change is
+	// produced on this chain.
+	expectedProduced := tx.UTXOs()
+	require.Len(expectedProduced, 1)
+	require.Len(produced, len(expectedProduced))
+	for i, utxo := range produced {
+		require.Equal(expectedProduced[i].InputID(), utxo.InputID())
+		require.Equal(expectedProduced[i].Out, utxo.Out)
+	}
+}
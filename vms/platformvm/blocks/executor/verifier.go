@@ -20,6 +20,11 @@ import (
 var (
 	_ blocks.Visitor = (*verifier)(nil)
 
+	// ErrAtomicTxAfterApricotPhase5 is returned when an atomic block is
+	// verified after ApricotPhase5. Its atomic tx should be issued in a
+	// standard block instead.
+	ErrAtomicTxAfterApricotPhase5 = errors.New("atomic transactions should go through the standard block after apricot phase 5")
+
 	errApricotBlockIssuedAfterFork                = errors.New("apricot block issued after fork")
 	errBanffProposalBlockWithMultipleTransactions = errors.New("BanffProposalBlock contains multiple transactions")
 	errBanffStandardBlockWithoutChanges           = errors.New("BanffStandardBlock performs no state changes")
@@ -170,32 +175,12 @@ func (v *verifier) ApricotStandardBlock(b *blocks.ApricotStandardBlock) error {
 }
 
 func (v *verifier) ApricotAtomicBlock(b *blocks.ApricotAtomicBlock) error {
-	// We call [commonBlock] here rather than [apricotCommonBlock] because below
-	// this check we perform the more strict check that ApricotPhase5 isn't
-	// activated.
-	if err := v.commonBlock(b); err != nil {
+	if err := v.apricotAtomicCommonBlock(b); err != nil {
 		return err
 	}
 
-	parentID := b.Parent()
-	currentTimestamp := v.getTimestamp(parentID)
-	cfg := v.txExecutorBackend.Config
-	if cfg.IsApricotPhase5Activated(currentTimestamp) {
-		return fmt.Errorf(
-			"the chain timestamp (%d) is after the apricot phase 5 time (%d), hence atomic transactions should go through the standard block",
-			currentTimestamp.Unix(),
-			cfg.ApricotPhase5Time.Unix(),
-		)
-	}
-
-	atomicExecutor := executor.AtomicTxExecutor{
-		Backend:       v.txExecutorBackend,
-		ParentID:      parentID,
-		StateVersions: v,
-		Tx:            b.Tx,
-	}
-
-	if err := b.Tx.Unsigned.Visit(&atomicExecutor); err != nil {
+	atomicExecutor, err := v.executeAtomicTx(b)
+	if err != nil {
 		txID := b.Tx.ID()
 		v.MarkDropped(txID, err) // cache tx as dropped
 		return fmt.Errorf("tx %s failed semantic verification: %w", txID, err)
@@ -222,6 +207,53 @@ func (v *verifier) ApricotAtomicBlock(b *blocks.ApricotAtomicBlock) error {
 	return nil
 }
 
+// checkApricotAtomicBlock performs the same checks as [ApricotAtomicBlock]
+// without modifying any state.
+func (v *verifier) checkApricotAtomicBlock(b *blocks.ApricotAtomicBlock) error {
+	if err := v.apricotAtomicCommonBlock(b); err != nil {
+		return err
+	}
+
+	atomicExecutor, err := v.executeAtomicTx(b)
+	if err != nil {
+		return fmt.Errorf("tx %s failed semantic verification: %w", b.Tx.ID(), err)
+	}
+	return v.verifyUniqueInputs(b, atomicExecutor.Inputs)
+}
+
+func (v *verifier) apricotAtomicCommonBlock(b *blocks.ApricotAtomicBlock) error {
+	// We call [commonBlock] here rather than [apricotCommonBlock] because below
+	// this check we perform the more strict check that ApricotPhase5 isn't
+	// activated.
+	if err := v.commonBlock(b); err != nil {
+		return err
+	}
+
+	currentTimestamp := v.getTimestamp(b.Parent())
+	cfg := v.txExecutorBackend.Config
+	if cfg.IsApricotPhase5Activated(currentTimestamp) {
+		return fmt.Errorf(
+			"%w: the chain timestamp (%d) is after the apricot phase 5 time (%d)",
+			ErrAtomicTxAfterApricotPhase5,
+			currentTimestamp.Unix(),
+			cfg.ApricotPhase5Time.Unix(),
+		)
+	}
+	return nil
+}
+
+// executeAtomicTx executes the atomic tx of [b] on top of its parent's state.
+// The returned executor's state diff isn't applied anywhere.
+func (v *verifier) executeAtomicTx(b *blocks.ApricotAtomicBlock) (*executor.AtomicTxExecutor, error) {
+	atomicExecutor := &executor.AtomicTxExecutor{
+		Backend:       v.txExecutorBackend,
+		ParentID:      b.Parent(),
+		StateVersions: v,
+		Tx:            b.Tx,
+	}
+	return atomicExecutor, b.Tx.Unsigned.Visit(atomicExecutor)
+}
+
 func (v *verifier) banffOptionBlock(b blocks.BanffBlock) error {
 	if err := v.commonBlock(b); err != nil {
 		return err
@@ -470,19 +502,8 @@ func (v *verifier) verifyUniqueInputs(block blocks.Block, inputs set.Set[ids.ID]
 	}
 
 	// Check for conflicts in ancestors.
-	for {
-		parentID := block.Parent()
-		parentState, ok := v.blkIDToState[parentID]
-		if !ok {
-			// The parent state isn't pinned in memory.
-			// This means the parent must be accepted already.
-			return nil
-		}
-
-		if parentState.inputs.Overlaps(inputs) {
-			return errConflictingParentTxs
-		}
-
-		block = parentState.statelessBlock
+	if v.conflicts(block.Parent(), inputs) {
+		return errConflictingParentTxs
 	}
+	return nil
 }
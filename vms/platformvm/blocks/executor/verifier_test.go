@@ -22,6 +22,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/components/verify"
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 	"github.com/ava-labs/avalanchego/vms/platformvm/config"
+	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
 	"github.com/ava-labs/avalanchego/vms/platformvm/state"
 	"github.com/ava-labs/avalanchego/vms/platformvm/status"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
@@ -67,6 +68,7 @@ func TestVerifierVisitProposalBlock(t *testing.T) {
 	}
 	manager := &manager{
 		backend:  backend,
+		metrics:  metrics.Noop,
 		verifier: verifier,
 	}
 
@@ -152,6 +154,7 @@ func TestVerifierVisitAtomicBlock(t *testing.T) {
 	}
 	manager := &manager{
 		backend:  backend,
+		metrics:  metrics.Noop,
 		verifier: verifier,
 	}
 
@@ -203,6 +206,171 @@ func TestVerifierVisitAtomicBlock(t *testing.T) {
 	require.NoError(blk.Verify(context.Background()))
 }
 
+func TestVerifierVisitAtomicBlockAfterApricotPhase5(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	// Create mocked dependencies.
+	s := state.NewMockState(ctrl)
+	mempool := mempool.NewMockMempool(ctrl)
+	parentID := ids.GenerateTestID()
+	parentStatelessBlk := blocks.NewMockBlock(ctrl)
+	parentTime := time.Now()
+
+	backend := &backend{
+		blkIDToState: map[ids.ID]*blockState{
+			parentID: {
+				statelessBlock: parentStatelessBlk,
+				timestamp:      parentTime,
+			},
+		},
+		Mempool: mempool,
+		state:   s,
+		ctx: &snow.Context{
+			Log: logging.NoLog{},
+		},
+	}
+	verifier := &verifier{
+		txExecutorBackend: &executor.Backend{
+			Config: &config.Config{
+				ApricotPhase5Time: parentTime.Add(-time.Hour),
+				BanffTime:         mockable.MaxTime, // banff is not activated
+			},
+			Clk: &mockable.Clock{},
+		},
+		backend: backend,
+	}
+	manager := &manager{
+		backend:  backend,
+		metrics:  metrics.Noop,
+		verifier: verifier,
+	}
+
+	apricotBlk, err := blocks.NewApricotAtomicBlock(
+		parentID,
+		2,
+		&txs.Tx{
+			Unsigned: &txs.AdvanceTimeTx{},
+			Creds:    []verify.Verifiable{},
+		},
+	)
+	require.NoError(err)
+
+	// Set expectations for dependencies.
+	parentStatelessBlk.EXPECT().Height().Return(uint64(1)).Times(1)
+
+	blk := manager.NewBlock(apricotBlk)
+	err = blk.Verify(context.Background())
+	require.ErrorIs(err, ErrAtomicTxAfterApricotPhase5)
+	require.NotContains(verifier.backend.blkIDToState, apricotBlk.ID())
+}
+
+func TestBlockCheckValidAtomicBlock(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	// Create mocked dependencies.
+	s := state.NewMockState(ctrl)
+	mempool := mempool.NewMockMempool(ctrl)
+	parentID := ids.GenerateTestID()
+	parentStatelessBlk := blocks.NewMockBlock(ctrl)
+	grandparentID := ids.GenerateTestID()
+	parentState := state.NewMockDiff(ctrl)
+	parentInputs := set.Of(ids.GenerateTestID())
+
+	backend := &backend{
+		blkIDToState: map[ids.ID]*blockState{
+			parentID: {
+				standardBlockState: standardBlockState{
+					inputs: parentInputs,
+				},
+				statelessBlock: parentStatelessBlk,
+				onAcceptState:  parentState,
+			},
+		},
+		Mempool: mempool,
+		state:   s,
+		ctx: &snow.Context{
+			Log: logging.NoLog{},
+		},
+	}
+	txExecutorBackend := &executor.Backend{
+		Config: &config.Config{
+			ApricotPhase5Time: time.Now().Add(time.Hour),
+			BanffTime:         mockable.MaxTime, // banff is not activated
+		},
+		Clk: &mockable.Clock{},
+	}
+	manager := &manager{
+		backend:           backend,
+		metrics:           metrics.Noop,
+		txExecutorBackend: txExecutorBackend,
+		verifier: &verifier{
+			txExecutorBackend: txExecutorBackend,
+			backend:           backend,
+		},
+	}
+	parentStatelessBlk.EXPECT().Height().Return(uint64(1)).AnyTimes()
+	parentStatelessBlk.EXPECT().Parent().Return(grandparentID).AnyTimes()
+
+	// newAtomicBlock returns a block whose tx consumes [inputs]. Blocks with
+	// different [nonce]s have different IDs.
+	newAtomicBlock := func(nonce uint64, inputs set.Set[ids.ID], onAccept state.Diff) *Block {
+		blkTx := txs.NewMockUnsignedTx(ctrl)
+		blkTx.EXPECT().Visit(gomock.AssignableToTypeOf(&executor.AtomicTxExecutor{})).DoAndReturn(
+			func(e *executor.AtomicTxExecutor) error {
+				e.OnAccept = onAccept
+				e.Inputs = inputs
+				return nil
+			},
+		).Times(2) // Once by CheckValid and once by Verify.
+
+		// We can't serialize [blkTx] because it isn't registered with
+		// blocks.Codec. Serialize this block with a dummy tx and replace it
+		// after creation with the mock tx.
+		apricotBlk, err := blocks.NewApricotAtomicBlock(
+			parentID,
+			2,
+			&txs.Tx{
+				Unsigned: &txs.AdvanceTimeTx{Time: nonce},
+				Creds:    []verify.Verifiable{},
+			},
+		)
+		require.NoError(err)
+		apricotBlk.Tx.Unsigned = blkTx
+		return manager.NewBlock(apricotBlk).(*Block)
+	}
+
+	// A valid block is valid according to both CheckValid and Verify.
+	onAccept := state.NewMockDiff(ctrl)
+	validBlk := newAtomicBlock(0, set.Of(ids.GenerateTestID()), onAccept)
+	require.NoError(validBlk.CheckValid())
+	require.NotContains(backend.blkIDToState, validBlk.ID())
+
+	mempool.EXPECT().Remove([]*txs.Tx{validBlk.Block.Txs()[0]}).Times(1)
+	onAccept.EXPECT().AddTx(validBlk.Block.Txs()[0], status.Committed).Times(1)
+	onAccept.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	require.NoError(validBlk.Verify(context.Background()))
+
+	// A block conflicting with its parent is invalid according to both
+	// CheckValid and Verify.
+	conflictingOnAccept := state.NewMockDiff(ctrl)
+	conflictingBlk := newAtomicBlock(1, parentInputs, conflictingOnAccept)
+	err := conflictingBlk.CheckValid()
+	require.ErrorIs(err, errConflictingParentTxs)
+	require.NotContains(backend.blkIDToState, conflictingBlk.ID())
+
+	conflictingOnAccept.EXPECT().AddTx(conflictingBlk.Block.Txs()[0], status.Committed).Times(1)
+	err = conflictingBlk.Verify(context.Background())
+	require.ErrorIs(err, errConflictingParentTxs)
+
+	// Only atomic blocks can be checked.
+	standardBlk, err := blocks.NewApricotStandardBlock(parentID, 2, nil)
+	require.NoError(err)
+	err = manager.NewBlock(standardBlk).(*Block).CheckValid()
+	require.ErrorIs(err, errNotAtomicBlock)
+}
+
 func TestVerifierVisitStandardBlock(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -239,6 +407,7 @@ func TestVerifierVisitStandardBlock(t *testing.T) {
 	}
 	manager := &manager{
 		backend:  backend,
+		metrics:  metrics.Noop,
 		verifier: verifier,
 	}
 
@@ -342,6 +511,7 @@ func TestVerifierVisitCommitBlock(t *testing.T) {
 	}
 	manager := &manager{
 		backend:  backend,
+		metrics:  metrics.Noop,
 		verifier: verifier,
 	}
 
@@ -412,6 +582,7 @@ func TestVerifierVisitAbortBlock(t *testing.T) {
 	}
 	manager := &manager{
 		backend:  backend,
+		metrics:  metrics.Noop,
 		verifier: verifier,
 	}
 
@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/crypto/secp256k1"
+	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/components/verify"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
@@ -98,3 +100,66 @@ func TestNewApricotStandardBlock(t *testing.T) {
 	require.Equal(parentID, blk.Parent())
 	require.Equal(height, blk.Height())
 }
+
+func TestNewStandardBlockMaxSize(t *testing.T) {
+	require := require.New(t)
+
+	timestamp := time.Now().Truncate(time.Second)
+	parentID := ids.GenerateTestID()
+	height := uint64(1337)
+
+	// The txs aren't initialized, so the size of their credentials must be
+	// accounted for when the block is created.
+	newTx := func(genesisSize int) *txs.Tx {
+		return &txs.Tx{
+			Unsigned: &txs.CreateChainTx{
+				BaseTx: txs.BaseTx{
+					BaseTx: avax.BaseTx{
+						Ins:  []*avax.TransferableInput{},
+						Outs: []*avax.TransferableOutput{},
+					},
+				},
+				GenesisData: make([]byte, genesisSize),
+				SubnetAuth:  &secp256k1fx.Input{},
+			},
+			Creds: []verify.Verifiable{
+				&secp256k1fx.Credential{
+					Sigs: make([][secp256k1.SignatureLen]byte, 1),
+				},
+			},
+		}
+	}
+	// newTxs returns txs that fill most of a block, followed by a tx whose
+	// genesis is [padding] bytes.
+	const genesisSize = 16 * units.KiB
+	newTxs := func(padding int) []*txs.Tx {
+		blkTxs := make([]*txs.Tx, 0, MaxBlockSize/genesisSize)
+		for len(blkTxs) < cap(blkTxs)-1 {
+			blkTxs = append(blkTxs, newTx(genesisSize))
+		}
+		return append(blkTxs, newTx(padding))
+	}
+
+	blk, err := NewBanffStandardBlock(timestamp, parentID, height, newTxs(0))
+	require.NoError(err)
+	maxPadding := MaxBlockSize - len(blk.Bytes())
+
+	blk, err = NewBanffStandardBlock(timestamp, parentID, height, newTxs(maxPadding))
+	require.NoError(err)
+	require.Len(blk.Bytes(), MaxBlockSize)
+
+	_, err = NewBanffStandardBlock(timestamp, parentID, height, newTxs(maxPadding+1))
+	require.ErrorIs(err, ErrBlockTooLarge)
+
+	// Apricot blocks don't have a timestamp, so they can hold slightly more.
+	apricotBlk, err := NewApricotStandardBlock(parentID, height, newTxs(maxPadding))
+	require.NoError(err)
+	maxPadding += MaxBlockSize - len(apricotBlk.Bytes())
+
+	apricotBlk, err = NewApricotStandardBlock(parentID, height, newTxs(maxPadding))
+	require.NoError(err)
+	require.Len(apricotBlk.Bytes(), MaxBlockSize)
+
+	_, err = NewApricotStandardBlock(parentID, height, newTxs(maxPadding+1))
+	require.ErrorIs(err, ErrBlockTooLarge)
+}
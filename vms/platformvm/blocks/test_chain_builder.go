@@ -0,0 +1,99 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blocks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+var (
+	errHeightOverflow         = errors.New("block height overflows")
+	errTimestampBeforeParent  = errors.New("block timestamp is before its parent's timestamp")
+	errApricotBlockAfterBanff = errors.New("apricot block can't follow a banff block")
+)
+
+// BlockChainBuilder builds a chain of standard blocks for tests. Each added
+// block is built on top of the previously added one.
+type BlockChainBuilder struct {
+	parentID     ids.ID
+	parentHeight uint64
+	// The timestamp of the most recent banff block, if any.
+	parentTimestamp time.Time
+	isBanff         bool
+
+	blocks []Block
+}
+
+// NewBlockChainBuilder returns a builder whose first block is a child of the
+// block with ID [parentID] at [parentHeight].
+func NewBlockChainBuilder(parentID ids.ID, parentHeight uint64) *BlockChainBuilder {
+	return &BlockChainBuilder{
+		parentID:     parentID,
+		parentHeight: parentHeight,
+	}
+}
+
+// AddApricotStandard adds an apricot standard block containing [txs].
+func (b *BlockChainBuilder) AddApricotStandard(txs ...*txs.Tx) (*ApricotStandardBlock, error) {
+	if b.isBanff {
+		return nil, errApricotBlockAfterBanff
+	}
+	height, err := b.nextHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	blk, err := NewApricotStandardBlock(b.parentID, height, txs)
+	if err != nil {
+		return nil, err
+	}
+	b.add(blk)
+	return blk, nil
+}
+
+// AddBanffStandard adds a banff standard block with [timestamp] containing
+// [txs]. [timestamp] must not be before the timestamp of the previous banff
+// block.
+func (b *BlockChainBuilder) AddBanffStandard(timestamp time.Time, txs ...*txs.Tx) (*BanffStandardBlock, error) {
+	if b.isBanff && timestamp.Unix() < b.parentTimestamp.Unix() {
+		return nil, fmt.Errorf("%w: %s < %s", errTimestampBeforeParent, timestamp, b.parentTimestamp)
+	}
+	height, err := b.nextHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	blk, err := NewBanffStandardBlock(timestamp, b.parentID, height, txs)
+	if err != nil {
+		return nil, err
+	}
+	b.add(blk)
+	b.parentTimestamp = blk.Timestamp()
+	b.isBanff = true
+	return blk, nil
+}
+
+// Blocks returns the blocks added so far, in order.
+func (b *BlockChainBuilder) Blocks() []Block {
+	return b.blocks
+}
+
+func (b *BlockChainBuilder) nextHeight() (uint64, error) {
+	if b.parentHeight == math.MaxUint64 {
+		return 0, errHeightOverflow
+	}
+	return b.parentHeight + 1, nil
+}
+
+func (b *BlockChainBuilder) add(blk Block) {
+	b.parentID = blk.ID()
+	b.parentHeight = blk.Height()
+	b.blocks = append(b.blocks, blk)
+}
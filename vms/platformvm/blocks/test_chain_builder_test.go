@@ -0,0 +1,77 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package blocks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/components/verify"
+	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
+)
+
+func TestBlockChainBuilder(t *testing.T) {
+	require := require.New(t)
+
+	genesisID := ids.GenerateTestID()
+	builder := NewBlockChainBuilder(genesisID, 10)
+
+	tx := &txs.Tx{
+		Unsigned: &txs.AdvanceTimeTx{},
+		Creds:    []verify.Verifiable{},
+	}
+	require.NoError(tx.Initialize(txs.Codec))
+
+	_, err := builder.AddApricotStandard(tx)
+	require.NoError(err)
+	_, err = builder.AddApricotStandard()
+	require.NoError(err)
+
+	timestamp := time.Unix(1_000, 0)
+	_, err = builder.AddBanffStandard(timestamp)
+	require.NoError(err)
+	_, err = builder.AddBanffStandard(timestamp, tx)
+	require.NoError(err)
+	_, err = builder.AddBanffStandard(timestamp.Add(time.Second))
+	require.NoError(err)
+
+	blks := builder.Blocks()
+	require.Len(blks, 5)
+
+	parentID := genesisID
+	for i, blk := range blks {
+		require.Equal(parentID, blk.Parent())
+		require.Equal(uint64(11+i), blk.Height())
+		parentID = blk.ID()
+	}
+	require.IsType(&ApricotStandardBlock{}, blks[0])
+	require.Equal([]*txs.Tx{tx}, blks[0].Txs())
+	require.IsType(&BanffStandardBlock{}, blks[2])
+	require.Equal(timestamp, blks[2].(*BanffStandardBlock).Timestamp())
+	require.Equal([]*txs.Tx{tx}, blks[3].Txs())
+
+	// Timestamps can't decrease.
+	_, err = builder.AddBanffStandard(timestamp)
+	require.ErrorIs(err, errTimestampBeforeParent)
+
+	// Apricot blocks can't follow banff blocks.
+	_, err = builder.AddApricotStandard()
+	require.ErrorIs(err, errApricotBlockAfterBanff)
+
+	// Failed additions don't change the chain.
+	require.Len(builder.Blocks(), 5)
+}
+
+func TestBlockChainBuilderHeightOverflow(t *testing.T) {
+	require := require.New(t)
+
+	builder := NewBlockChainBuilder(ids.GenerateTestID(), math.MaxUint64)
+	_, err := builder.AddApricotStandard()
+	require.ErrorIs(err, errHeightOverflow)
+	require.Empty(builder.Blocks())
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForkActivation(t *testing.T) {
+	activationTime := time.Unix(1_000_000, 0)
+	c := &Config{
+		ApricotPhase3Time: activationTime,
+		ApricotPhase5Time: activationTime,
+		BanffTime:         activationTime,
+	}
+
+	forks := []struct {
+		name        string
+		isActivated func(time.Time) bool
+	}{
+		{
+			name:        "apricot phase 3",
+			isActivated: c.IsApricotPhase3Activated,
+		},
+		{
+			name:        "apricot phase 5",
+			isActivated: c.IsApricotPhase5Activated,
+		},
+		{
+			name:        "banff",
+			isActivated: c.IsBanffActivated,
+		},
+	}
+	for _, fork := range forks {
+		t.Run(fork.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.False(fork.isActivated(activationTime.Add(-time.Second)))
+			require.False(fork.isActivated(activationTime.Add(-time.Nanosecond)))
+			require.True(fork.isActivated(activationTime))
+			require.True(fork.isActivated(activationTime.Add(time.Nanosecond)))
+			require.True(fork.isActivated(activationTime.Add(time.Second)))
+		})
+	}
+}
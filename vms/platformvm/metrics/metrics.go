@@ -14,6 +14,8 @@ import (
 	"github.com/ava-labs/avalanchego/vms/platformvm/blocks"
 )
 
+const blockTypeLabel = "blk_type"
+
 var _ Metrics = (*metrics)(nil)
 
 type Metrics interface {
@@ -25,6 +27,11 @@ type Metrics interface {
 	MarkOptionVoteLost()
 	// Mark that the given block was accepted.
 	MarkAccepted(blocks.Block) error
+	// Mark that verifying a block of the given type took the given time.
+	ObserveBlockVerification(blockType string, duration time.Duration)
+	// Mark that applying the atomic requests of an accepted block of the
+	// given type to shared memory took the given time.
+	ObserveAtomicRequestsApply(blockType string, duration time.Duration)
 	// Mark that a validator set was created.
 	IncValidatorSetsCreated()
 	// Mark that a validator set was cached.
@@ -75,6 +82,25 @@ func New(
 			Help:      "Amount (in nAVAX) of AVAX staked on the Primary Network",
 		}),
 
+		blockVerificationDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "blk_verification_duration",
+				Help:      "Time (in seconds) spent verifying blocks",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{blockTypeLabel},
+		),
+		atomicRequestsApplyDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "atomic_requests_apply_duration",
+				Help:      "Time (in seconds) spent applying the atomic requests of accepted blocks to shared memory",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{blockTypeLabel},
+		),
+
 		numVotesWon: prometheus.NewCounter(prometheus.CounterOpts{
 			Namespace: namespace,
 			Name:      "votes_won",
@@ -118,6 +144,9 @@ func New(
 		registerer.Register(m.localStake),
 		registerer.Register(m.totalStake),
 
+		registerer.Register(m.blockVerificationDuration),
+		registerer.Register(m.atomicRequestsApplyDuration),
+
 		registerer.Register(m.numVotesWon),
 		registerer.Register(m.numVotesLost),
 
@@ -135,6 +164,9 @@ type metrics struct {
 
 	blockMetrics *blockMetrics
 
+	blockVerificationDuration   *prometheus.HistogramVec
+	atomicRequestsApplyDuration *prometheus.HistogramVec
+
 	timeUntilUnstake       prometheus.Gauge
 	timeUntilSubnetUnstake *prometheus.GaugeVec
 	localStake             prometheus.Gauge
@@ -160,6 +192,14 @@ func (m *metrics) MarkAccepted(b blocks.Block) error {
 	return b.Visit(m.blockMetrics)
 }
 
+func (m *metrics) ObserveBlockVerification(blockType string, duration time.Duration) {
+	m.blockVerificationDuration.WithLabelValues(blockType).Observe(duration.Seconds())
+}
+
+func (m *metrics) ObserveAtomicRequestsApply(blockType string, duration time.Duration) {
+	m.atomicRequestsApplyDuration.WithLabelValues(blockType).Observe(duration.Seconds())
+}
+
 func (m *metrics) IncValidatorSetsCreated() {
 	m.validatorSetsCreated.Inc()
 }
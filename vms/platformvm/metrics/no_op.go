@@ -25,6 +25,10 @@ func (noopMetrics) MarkAccepted(blocks.Block) error {
 	return nil
 }
 
+func (noopMetrics) ObserveBlockVerification(string, time.Duration) {}
+
+func (noopMetrics) ObserveAtomicRequestsApply(string, time.Duration) {}
+
 func (noopMetrics) InterceptRequest(i *rpc.RequestInfo) *http.Request {
 	return i.Request
 }
@@ -8,8 +8,11 @@ import (
 	"fmt"
 	"time"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
 	"github.com/ava-labs/avalanchego/vms/platformvm/status"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
@@ -24,9 +27,35 @@ var (
 type Diff interface {
 	Chain
 
+	// Changes returns a summary of the modifications this diff makes on top
+	// of its parent state.
+	Changes() (*StateChanges, error)
+
+	// UTXOChanges returns the UTXOs added, and the IDs of the UTXOs removed,
+	// by this diff. Both are sorted by UTXO ID.
+	UTXOChanges() (added []*avax.UTXO, removed []ids.ID)
+
 	Apply(State) error
 }
 
+// StateChanges summarizes the effects of a Diff on its parent state.
+type StateChanges struct {
+	// AddedUTXOs are sorted by their UTXO ID.
+	AddedUTXOs []*avax.UTXO
+	// RemovedUTXOs are sorted.
+	RemovedUTXOs []ids.ID
+
+	// Staker changes are sorted in order of their removal from the respective
+	// staker set.
+	AddedCurrentStakers   []*Staker
+	RemovedCurrentStakers []*Staker
+	AddedPendingStakers   []*Staker
+	RemovedPendingStakers []*Staker
+
+	PreviousTimestamp time.Time
+	Timestamp         time.Time
+}
+
 type diff struct {
 	parentID      ids.ID
 	stateVersions Versions
@@ -457,6 +486,40 @@ func (d *diff) DeleteUTXO(utxoID ids.ID) {
 	}
 }
 
+func (d *diff) Changes() (*StateChanges, error) {
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+
+	changes := &StateChanges{
+		PreviousTimestamp: parentState.GetTimestamp(),
+		Timestamp:         d.timestamp,
+	}
+
+	changes.AddedUTXOs, changes.RemovedUTXOs = d.UTXOChanges()
+	changes.AddedCurrentStakers, changes.RemovedCurrentStakers = d.currentStakerDiffs.changes()
+	changes.AddedPendingStakers, changes.RemovedPendingStakers = d.pendingStakerDiffs.changes()
+	return changes, nil
+}
+
+func (d *diff) UTXOChanges() ([]*avax.UTXO, []ids.ID) {
+	var (
+		added   []*avax.UTXO
+		removed []ids.ID
+	)
+	utxoIDs := maps.Keys(d.modifiedUTXOs)
+	utils.Sort(utxoIDs)
+	for _, utxoID := range utxoIDs {
+		if utxo := d.modifiedUTXOs[utxoID]; utxo != nil {
+			added = append(added, utxo)
+		} else {
+			removed = append(removed, utxoID)
+		}
+	}
+	return added, removed
+}
+
 func (d *diff) Apply(baseState State) error {
 	baseState.SetTimestamp(d.timestamp)
 	for subnetID, supply := range d.currentSupply {
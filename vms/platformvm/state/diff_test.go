@@ -459,6 +459,58 @@ func TestDiffUTXO(t *testing.T) {
 	}
 }
 
+func TestDiffChanges(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	lastAcceptedID := ids.GenerateTestID()
+	state, _ := newInitializedState(require)
+	versions := NewMockVersions(ctrl)
+	versions.EXPECT().GetState(lastAcceptedID).AnyTimes().Return(state, true)
+
+	d, err := NewDiff(lastAcceptedID, versions)
+	require.NoError(err)
+
+	// Mimic the effects of a tx that consumes the genesis UTXO and produces a
+	// new one.
+	consumedUTXOID := avax.UTXOID{
+		TxID:        initialTxID,
+		OutputIndex: 0,
+	}
+	_, err = d.GetUTXO(consumedUTXOID.InputID())
+	require.NoError(err)
+	d.DeleteUTXO(consumedUTXOID.InputID())
+
+	producedUTXO := &avax.UTXO{
+		UTXOID: avax.UTXOID{TxID: ids.GenerateTestID()},
+		Asset:  avax.Asset{ID: initialTxID},
+	}
+	d.AddUTXO(producedUTXO)
+
+	// Promote a pending validator to the current validator set.
+	pendingStaker := newTestStaker()
+	state.PutPendingValidator(pendingStaker)
+	d.DeletePendingValidator(pendingStaker)
+	d.PutCurrentValidator(pendingStaker)
+
+	newTimestamp := initialTime.Add(time.Second)
+	d.SetTimestamp(newTimestamp)
+
+	changes, err := d.Changes()
+	require.NoError(err)
+	require.Equal(
+		&StateChanges{
+			AddedUTXOs:            []*avax.UTXO{producedUTXO},
+			RemovedUTXOs:          []ids.ID{consumedUTXOID.InputID()},
+			AddedCurrentStakers:   []*Staker{pendingStaker},
+			RemovedPendingStakers: []*Staker{pendingStaker},
+			PreviousTimestamp:     initialTime,
+			Timestamp:             newTimestamp,
+		},
+		changes,
+	)
+}
+
 func assertChainsEqual(t *testing.T, expected, actual Chain) {
 	require := require.New(t)
 
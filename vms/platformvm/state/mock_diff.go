@@ -127,6 +127,21 @@ func (mr *MockDiffMockRecorder) Apply(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockDiff)(nil).Apply), arg0)
 }
 
+// Changes mocks base method.
+func (m *MockDiff) Changes() (*StateChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Changes")
+	ret0, _ := ret[0].(*StateChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Changes indicates an expected call of Changes.
+func (mr *MockDiffMockRecorder) Changes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Changes", reflect.TypeOf((*MockDiff)(nil).Changes))
+}
+
 // DeleteCurrentDelegator mocks base method.
 func (m *MockDiff) DeleteCurrentDelegator(arg0 *Staker) {
 	m.ctrl.T.Helper()
@@ -497,3 +512,18 @@ func (mr *MockDiffMockRecorder) SetTimestamp(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTimestamp", reflect.TypeOf((*MockDiff)(nil).SetTimestamp), arg0)
 }
+
+// UTXOChanges mocks base method.
+func (m *MockDiff) UTXOChanges() ([]*avax.UTXO, []ids.ID) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UTXOChanges")
+	ret0, _ := ret[0].([]*avax.UTXO)
+	ret1, _ := ret[1].([]ids.ID)
+	return ret0, ret1
+}
+
+// UTXOChanges indicates an expected call of UTXOChanges.
+func (mr *MockDiffMockRecorder) UTXOChanges() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UTXOChanges", reflect.TypeOf((*MockDiff)(nil).UTXOChanges))
+}
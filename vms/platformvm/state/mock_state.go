@@ -13,6 +13,7 @@ import (
 	sync "sync"
 	time "time"
 
+	atomic "github.com/ava-labs/avalanchego/chains/atomic"
 	database "github.com/ava-labs/avalanchego/database"
 	ids "github.com/ava-labs/avalanchego/ids"
 	validators "github.com/ava-labs/avalanchego/snow/validators"
@@ -59,6 +60,18 @@ func (mr *MockStateMockRecorder) Abort() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Abort", reflect.TypeOf((*MockState)(nil).Abort))
 }
 
+// AddBlockAtomicRequests mocks base method.
+func (m *MockState) AddBlockAtomicRequests(arg0 ids.ID, arg1 map[ids.ID]*atomic.Requests) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddBlockAtomicRequests", arg0, arg1)
+}
+
+// AddBlockAtomicRequests indicates an expected call of AddBlockAtomicRequests.
+func (mr *MockStateMockRecorder) AddBlockAtomicRequests(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBlockAtomicRequests", reflect.TypeOf((*MockState)(nil).AddBlockAtomicRequests), arg0, arg1)
+}
+
 // AddChain mocks base method.
 func (m *MockState) AddChain(arg0 *txs.Tx) {
 	m.ctrl.T.Helper()
@@ -288,6 +301,21 @@ func (mr *MockStateMockRecorder) DeleteUTXO(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUTXO", reflect.TypeOf((*MockState)(nil).DeleteUTXO), arg0)
 }
 
+// GetBlockAtomicRequests mocks base method.
+func (m *MockState) GetBlockAtomicRequests(arg0 ids.ID) (map[ids.ID]*atomic.Requests, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockAtomicRequests", arg0)
+	ret0, _ := ret[0].(map[ids.ID]*atomic.Requests)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockAtomicRequests indicates an expected call of GetBlockAtomicRequests.
+func (mr *MockStateMockRecorder) GetBlockAtomicRequests(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockAtomicRequests", reflect.TypeOf((*MockState)(nil).GetBlockAtomicRequests), arg0)
+}
+
 // GetBlockIDAtHeight mocks base method.
 func (m *MockState) GetBlockIDAtHeight(arg0 uint64) (ids.ID, error) {
 	m.ctrl.T.Helper()
@@ -303,6 +331,37 @@ func (mr *MockStateMockRecorder) GetBlockIDAtHeight(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockIDAtHeight", reflect.TypeOf((*MockState)(nil).GetBlockIDAtHeight), arg0)
 }
 
+// GetBlockTimestamp mocks base method.
+func (m *MockState) GetBlockTimestamp(arg0 ids.ID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockTimestamp", arg0)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockTimestamp indicates an expected call of GetBlockTimestamp.
+func (mr *MockStateMockRecorder) GetBlockTimestamp(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockTimestamp", reflect.TypeOf((*MockState)(nil).GetBlockTimestamp), arg0)
+}
+
+// GetBlockUTXOChanges mocks base method.
+func (m *MockState) GetBlockUTXOChanges(arg0 ids.ID) ([]ids.ID, []*avax.UTXO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockUTXOChanges", arg0)
+	ret0, _ := ret[0].([]ids.ID)
+	ret1, _ := ret[1].([]*avax.UTXO)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBlockUTXOChanges indicates an expected call of GetBlockUTXOChanges.
+func (mr *MockStateMockRecorder) GetBlockUTXOChanges(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockUTXOChanges", reflect.TypeOf((*MockState)(nil).GetBlockUTXOChanges), arg0)
+}
+
 // GetChains mocks base method.
 func (m *MockState) GetChains(arg0 ids.ID) ([]*txs.Tx, error) {
 	m.ctrl.T.Helper()
@@ -378,6 +437,21 @@ func (mr *MockStateMockRecorder) GetCurrentValidator(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentValidator", reflect.TypeOf((*MockState)(nil).GetCurrentValidator), arg0, arg1)
 }
 
+// GetCurrentValidatorsBySubnet mocks base method.
+func (m *MockState) GetCurrentValidatorsBySubnet(arg0 ids.ID) (StakerIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentValidatorsBySubnet", arg0)
+	ret0, _ := ret[0].(StakerIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCurrentValidatorsBySubnet indicates an expected call of GetCurrentValidatorsBySubnet.
+func (mr *MockStateMockRecorder) GetCurrentValidatorsBySubnet(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentValidatorsBySubnet", reflect.TypeOf((*MockState)(nil).GetCurrentValidatorsBySubnet), arg0)
+}
+
 // GetDelegateeReward mocks base method.
 func (m *MockState) GetDelegateeReward(arg0 ids.ID, arg1 ids.NodeID) (uint64, error) {
 	m.ctrl.T.Helper()
@@ -467,6 +541,22 @@ func (mr *MockStateMockRecorder) GetRewardUTXOs(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRewardUTXOs", reflect.TypeOf((*MockState)(nil).GetRewardUTXOs), arg0)
 }
 
+// GetStaker mocks base method.
+func (m *MockState) GetStaker(arg0 ids.ID, arg1 ids.NodeID) (*Staker, *Staker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStaker", arg0, arg1)
+	ret0, _ := ret[0].(*Staker)
+	ret1, _ := ret[1].(*Staker)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetStaker indicates an expected call of GetStaker.
+func (mr *MockStateMockRecorder) GetStaker(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStaker", reflect.TypeOf((*MockState)(nil).GetStaker), arg0, arg1)
+}
+
 // GetStartTime mocks base method.
 func (m *MockState) GetStartTime(arg0 ids.NodeID, arg1 ids.ID) (time.Time, error) {
 	m.ctrl.T.Helper()
@@ -541,6 +631,21 @@ func (mr *MockStateMockRecorder) GetTimestamp() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimestamp", reflect.TypeOf((*MockState)(nil).GetTimestamp))
 }
 
+// GetTotalStake mocks base method.
+func (m *MockState) GetTotalStake(arg0 ids.ID) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalStake", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotalStake indicates an expected call of GetTotalStake.
+func (mr *MockStateMockRecorder) GetTotalStake(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalStake", reflect.TypeOf((*MockState)(nil).GetTotalStake), arg0)
+}
+
 // GetTx mocks base method.
 func (m *MockState) GetTx(arg0 ids.ID) (*txs.Tx, status.Status, error) {
 	m.ctrl.T.Helper()
@@ -588,6 +693,35 @@ func (mr *MockStateMockRecorder) GetUptime(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUptime", reflect.TypeOf((*MockState)(nil).GetUptime), arg0, arg1)
 }
 
+// IndexBlockTimestamps mocks base method.
+func (m *MockState) IndexBlockTimestamps(arg0 sync.Locker, arg1 logging.Logger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexBlockTimestamps", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IndexBlockTimestamps indicates an expected call of IndexBlockTimestamps.
+func (mr *MockStateMockRecorder) IndexBlockTimestamps(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexBlockTimestamps", reflect.TypeOf((*MockState)(nil).IndexBlockTimestamps), arg0, arg1)
+}
+
+// PreviewReward mocks base method.
+func (m *MockState) PreviewReward(arg0 *Staker, arg1 time.Duration, arg2 uint64) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewReward", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewReward indicates an expected call of PreviewReward.
+func (mr *MockStateMockRecorder) PreviewReward(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewReward", reflect.TypeOf((*MockState)(nil).PreviewReward), arg0, arg1, arg2)
+}
+
 // PruneAndIndex mocks base method.
 func (m *MockState) PruneAndIndex(arg0 sync.Locker, arg1 logging.Logger) error {
 	m.ctrl.T.Helper()
@@ -650,6 +784,45 @@ func (mr *MockStateMockRecorder) PutPendingValidator(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutPendingValidator", reflect.TypeOf((*MockState)(nil).PutPendingValidator), arg0)
 }
 
+// RemoveExpiredStakers mocks base method.
+func (m *MockState) RemoveExpiredStakers(arg0 time.Time) ([]*Staker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveExpiredStakers", arg0)
+	ret0, _ := ret[0].([]*Staker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveExpiredStakers indicates an expected call of RemoveExpiredStakers.
+func (mr *MockStateMockRecorder) RemoveExpiredStakers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExpiredStakers", reflect.TypeOf((*MockState)(nil).RemoveExpiredStakers), arg0)
+}
+
+// SetBlockTimestamp mocks base method.
+func (m *MockState) SetBlockTimestamp(arg0 ids.ID, arg1 time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBlockTimestamp", arg0, arg1)
+}
+
+// SetBlockTimestamp indicates an expected call of SetBlockTimestamp.
+func (mr *MockStateMockRecorder) SetBlockTimestamp(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlockTimestamp", reflect.TypeOf((*MockState)(nil).SetBlockTimestamp), arg0, arg1)
+}
+
+// SetBlockUTXOChanges mocks base method.
+func (m *MockState) SetBlockUTXOChanges(arg0 ids.ID, arg1 []ids.ID, arg2 []*avax.UTXO) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetBlockUTXOChanges", arg0, arg1, arg2)
+}
+
+// SetBlockUTXOChanges indicates an expected call of SetBlockUTXOChanges.
+func (mr *MockStateMockRecorder) SetBlockUTXOChanges(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBlockUTXOChanges", reflect.TypeOf((*MockState)(nil).SetBlockUTXOChanges), arg0, arg1, arg2)
+}
+
 // SetCurrentSupply mocks base method.
 func (m *MockState) SetCurrentSupply(arg0 ids.ID, arg1 uint64) {
 	m.ctrl.T.Helper()
@@ -726,6 +899,21 @@ func (mr *MockStateMockRecorder) SetUptime(arg0, arg1, arg2, arg3 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUptime", reflect.TypeOf((*MockState)(nil).SetUptime), arg0, arg1, arg2, arg3)
 }
 
+// ShouldIndexBlockTimestamps mocks base method.
+func (m *MockState) ShouldIndexBlockTimestamps() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShouldIndexBlockTimestamps")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShouldIndexBlockTimestamps indicates an expected call of ShouldIndexBlockTimestamps.
+func (mr *MockStateMockRecorder) ShouldIndexBlockTimestamps() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldIndexBlockTimestamps", reflect.TypeOf((*MockState)(nil).ShouldIndexBlockTimestamps))
+}
+
 // ShouldPrune mocks base method.
 func (m *MockState) ShouldPrune() (bool, error) {
 	m.ctrl.T.Helper()
@@ -769,3 +957,18 @@ func (mr *MockStateMockRecorder) ValidatorSet(arg0, arg1 interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatorSet", reflect.TypeOf((*MockState)(nil).ValidatorSet), arg0, arg1)
 }
+
+// WouldConflict mocks base method.
+func (m *MockState) WouldConflict(arg0 *txs.Tx, arg1 []*StateChanges) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WouldConflict", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WouldConflict indicates an expected call of WouldConflict.
+func (mr *MockStateMockRecorder) WouldConflict(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WouldConflict", reflect.TypeOf((*MockState)(nil).WouldConflict), arg0, arg1)
+}
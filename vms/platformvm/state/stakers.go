@@ -6,8 +6,11 @@ package state
 import (
 	"github.com/google/btree"
 
+	"golang.org/x/exp/maps"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
 )
 
 type Stakers interface {
@@ -91,7 +94,8 @@ type PendingStakers interface {
 	DeletePendingDelegator(staker *Staker)
 
 	// GetPendingStakerIterator returns stakers in order of their removal from
-	// the pending staker set.
+	// the pending staker set, which is the order of their start times across
+	// all subnets.
 	GetPendingStakerIterator() (StakerIterator, error)
 }
 
@@ -202,6 +206,20 @@ func (v *baseStakers) GetStakerIterator() StakerIterator {
 	return NewTreeIterator(v.stakers)
 }
 
+func (v *baseStakers) GetValidatorIterator(subnetID ids.ID) StakerIterator {
+	subnetValidators, ok := v.validators[subnetID]
+	if !ok {
+		return EmptyIterator
+	}
+	validators := btree.NewG(defaultTreeDegree, (*Staker).Less)
+	for _, validator := range subnetValidators {
+		if validator.validator != nil {
+			validators.ReplaceOrInsert(validator.validator)
+		}
+	}
+	return NewTreeIterator(validators)
+}
+
 func (v *baseStakers) getOrCreateValidator(subnetID ids.ID, nodeID ids.NodeID) *baseStaker {
 	subnetValidators, ok := v.validators[subnetID]
 	if !ok {
@@ -378,6 +396,25 @@ func (s *diffStakers) GetStakerIterator(parentIterator StakerIterator) StakerIte
 	)
 }
 
+// changes returns the stakers added and removed by this diff, in order of their
+// removal from the staker set.
+func (s *diffStakers) changes() ([]*Staker, []*Staker) {
+	var added []*Staker
+	if s.addedStakers != nil {
+		added = make([]*Staker, 0, s.addedStakers.Len())
+		s.addedStakers.Ascend(func(staker *Staker) bool {
+			added = append(added, staker)
+			return true
+		})
+	}
+	var removed []*Staker
+	if len(s.deletedStakers) != 0 {
+		removed = maps.Values(s.deletedStakers)
+		utils.Sort(removed)
+	}
+	return added, removed
+}
+
 func (s *diffStakers) getOrCreateDiff(subnetID ids.ID, nodeID ids.NodeID) *diffValidator {
 	if s.validatorDiffs == nil {
 		s.validatorDiffs = make(map[ids.ID]map[ids.NodeID]*diffValidator)
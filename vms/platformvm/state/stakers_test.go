@@ -106,6 +106,27 @@ func TestBaseStakersValidator(t *testing.T) {
 	assertIteratorsEqual(t, EmptyIterator, stakerIterator)
 }
 
+func TestBaseStakersValidatorIterator(t *testing.T) {
+	subnetStaker := newTestStaker()
+	otherSubnetStaker := newTestStaker()
+	delegator := newTestStaker()
+	delegator.SubnetID = subnetStaker.SubnetID
+
+	v := newBaseStakers()
+	v.PutValidator(subnetStaker)
+	v.PutValidator(otherSubnetStaker)
+	v.PutDelegator(delegator)
+
+	validatorIterator := v.GetValidatorIterator(subnetStaker.SubnetID)
+	assertIteratorsEqual(t, NewSliceIterator(subnetStaker), validatorIterator)
+
+	validatorIterator = v.GetValidatorIterator(otherSubnetStaker.SubnetID)
+	assertIteratorsEqual(t, NewSliceIterator(otherSubnetStaker), validatorIterator)
+
+	validatorIterator = v.GetValidatorIterator(ids.GenerateTestID())
+	assertIteratorsEqual(t, EmptyIterator, validatorIterator)
+}
+
 func TestBaseStakersDelegator(t *testing.T) {
 	staker := newTestStaker()
 	delegator := newTestStaker()
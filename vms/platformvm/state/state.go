@@ -20,6 +20,7 @@ import (
 
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/cache/metercacher"
+	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/linkeddb"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
@@ -35,6 +36,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/timer"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -61,9 +63,17 @@ var (
 	errMissingValidatorSet          = errors.New("missing validator set")
 	errValidatorSetAlreadyPopulated = errors.New("validator set already populated")
 	errDuplicateValidatorSet        = errors.New("duplicate validator set")
+	errZeroCurrentSupply            = errors.New("current supply is zero")
+	errNonPositiveStakeDuration     = errors.New("stake duration must be positive")
+	errWrongGenesisValidatorTxType  = errors.New("wrong genesis validator tx type")
+	errDuplicateGenesisValidator    = errors.New("duplicate genesis validator")
+	errGenesisSupplyExceeded        = errors.New("genesis funds exceed initial supply")
 
 	blockIDPrefix                       = []byte("blockID")
 	blockPrefix                         = []byte("block")
+	blockTimestampPrefix                = []byte("blockTimestamp")
+	blockAtomicRequestsPrefix           = []byte("blockAtomicRequests")
+	blockUTXOChangesPrefix              = []byte("blockUTXOChanges")
 	validatorsPrefix                    = []byte("validators")
 	currentPrefix                       = []byte("current")
 	pendingPrefix                       = []byte("pending")
@@ -90,6 +100,9 @@ var (
 	heightsIndexedKey = []byte("heights indexed")
 	initializedKey    = []byte("initialized")
 	prunedKey         = []byte("pruned")
+
+	blockTimestampsIndexedKey       = []byte("block timestamps indexed")
+	blockTimestampsIndexProgressKey = []byte("block timestamps index progress")
 )
 
 // Chain collects all methods to manage the state of the chain for block
@@ -109,6 +122,9 @@ type Chain interface {
 	GetRewardUTXOs(txID ids.ID) ([]*avax.UTXO, error)
 	AddRewardUTXO(txID ids.ID, utxo *avax.UTXO)
 
+	// GetSubnets returns the CreateSubnetTx of every subnet, in an
+	// unspecified order. The primary network isn't created by a transaction,
+	// so it isn't included.
 	GetSubnets() ([]*txs.Tx, error)
 	AddSubnet(createSubnetTx *txs.Tx)
 
@@ -127,6 +143,12 @@ type State interface {
 	uptime.State
 	avax.UTXOReader
 
+	// WouldConflict returns true if [tx] consumes a UTXO that is consumed by
+	// any of [pendingDiffs], which are the changes of blocks that haven't been
+	// accepted yet. Imported inputs are consumed from shared memory rather
+	// than the state, so they're only reported if a pending diff removed them.
+	WouldConflict(tx *txs.Tx, pendingDiffs []*StateChanges) (bool, error)
+
 	GetLastAccepted() ids.ID
 	SetLastAccepted(blkID ids.ID)
 
@@ -137,6 +159,58 @@ type State interface {
 
 	GetBlockIDAtHeight(height uint64) (ids.ID, error)
 
+	// GetBlockTimestamp returns the timestamp of the accepted block [blkID].
+	// If the timestamp of the block isn't known, [database.ErrNotFound] is
+	// returned.
+	GetBlockTimestamp(blkID ids.ID) (time.Time, error)
+
+	// Invariant: [blkID] is an accepted block.
+	SetBlockTimestamp(blkID ids.ID, timestamp time.Time)
+
+	// GetBlockAtomicRequests returns the atomic requests, keyed by chain ID,
+	// that were applied to shared memory when the block [blkID] was accepted.
+	// If [blkID] isn't an accepted block that made atomic requests,
+	// [database.ErrNotFound] is returned.
+	GetBlockAtomicRequests(blkID ids.ID) (map[ids.ID]*atomic.Requests, error)
+
+	// Invariant: [blkID] is an accepted block.
+	AddBlockAtomicRequests(blkID ids.ID, requests map[ids.ID]*atomic.Requests)
+
+	// GetBlockUTXOChanges returns the IDs of the UTXOs consumed, and the UTXOs
+	// produced, by the accepted block [blkID]. The changes made by a proposal
+	// block's tx are returned for the option block accepted after it. UTXOs
+	// imported from or exported to shared memory aren't included.
+	// If the UTXO changes of the block aren't known, [database.ErrNotFound]
+	// is returned.
+	GetBlockUTXOChanges(blkID ids.ID) (consumed []ids.ID, produced []*avax.UTXO, err error)
+
+	// Invariant: [blkID] is an accepted block.
+	SetBlockUTXOChanges(blkID ids.ID, consumed []ids.ID, produced []*avax.UTXO)
+
+	// GetStaker returns the current and pending validators on [subnetID] with
+	// [nodeID]. If either of the validators does not exist, nil is returned in
+	// its place.
+	GetStaker(subnetID ids.ID, nodeID ids.NodeID) (current *Staker, pending *Staker, err error)
+
+	// RemoveExpiredStakers removes all the current validators and delegators
+	// whose end time is before [now]. The removed stakers are returned in
+	// order of their removal from the current staker set.
+	RemoveExpiredStakers(now time.Time) ([]*Staker, error)
+
+	// PreviewReward returns the reward [staker] would receive for staking its
+	// weight for [stakeDuration] with [currentSupply], as calculated by the
+	// configured reward calculator. No state is modified.
+	PreviewReward(staker *Staker, stakeDuration time.Duration, currentSupply uint64) (uint64, error)
+
+	// GetCurrentValidatorsBySubnet returns the current validators of
+	// [subnetID] in order of their removal from the current staker set. If
+	// [subnetID] has no current validators, an empty iterator is returned.
+	GetCurrentValidatorsBySubnet(subnetID ids.ID) (StakerIterator, error)
+
+	// GetTotalStake returns the sum of the weights of the current validators
+	// and delegators of [subnetID]. Returns an error if the sum overflows.
+	GetTotalStake(subnetID ids.ID) (uint64, error)
+
 	// ValidatorSet adds all the validators and delegators of [subnetID] into
 	// [vdrs].
 	ValidatorSet(subnetID ids.ID, vdrs validators.Set) error
@@ -195,6 +269,20 @@ type State interface {
 	// TODO: Remove after v1.11.x is activated
 	PruneAndIndex(sync.Locker, logging.Logger) error
 
+	// Returns if the timestamps of accepted blocks that were written before
+	// block timestamps were persisted should be indexed.
+	//
+	// TODO: Remove after v1.11.x is activated
+	ShouldIndexBlockTimestamps() (bool, error)
+
+	// Indexes the timestamps of accepted blocks that were written before block
+	// timestamps were persisted. Progress is committed periodically, so
+	// indexing resumes where it left off if the node restarts. This function
+	// supports being (and is recommended to be) called asynchronously.
+	//
+	// TODO: Remove after v1.11.x is activated
+	IndexBlockTimestamps(sync.Locker, logging.Logger) error
+
 	// Commit changes to the base database.
 	Commit() error
 
@@ -293,6 +381,8 @@ type state struct {
 
 	baseDB *versiondb.Database
 
+	// All current and pending stakers are loaded into memory on startup, so
+	// staker lookups never read from disk and there is no staker cache.
 	currentStakers *baseStakers
 	pendingStakers *baseStakers
 
@@ -306,6 +396,15 @@ type state struct {
 	blockCache  cache.Cacher[ids.ID, blocks.Block] // cache of blockID -> Block. If the entry is nil, it is not in the database
 	blockDB     database.Database
 
+	addedBlockTimestamps map[ids.ID]time.Time // map of blockID -> timestamp
+	blockTimestampDB     database.Database
+
+	addedBlockAtomicRequests map[ids.ID]map[ids.ID]*atomic.Requests // map of blockID -> chainID -> requests
+	blockAtomicRequestsDB    database.Database
+
+	addedBlockUTXOChanges map[ids.ID]*blockUTXOChanges // map of blockID -> UTXO changes
+	blockUTXOChangesDB    database.Database
+
 	validatorsDB                 database.Database
 	currentValidatorsDB          database.Database
 	currentValidatorBaseDB       database.Database
@@ -623,6 +722,15 @@ func newState(
 		blockCache:  blockCache,
 		blockDB:     prefixdb.New(blockPrefix, baseDB),
 
+		addedBlockTimestamps: make(map[ids.ID]time.Time),
+		blockTimestampDB:     prefixdb.New(blockTimestampPrefix, baseDB),
+
+		addedBlockAtomicRequests: make(map[ids.ID]map[ids.ID]*atomic.Requests),
+		blockAtomicRequestsDB:    prefixdb.New(blockAtomicRequestsPrefix, baseDB),
+
+		addedBlockUTXOChanges: make(map[ids.ID]*blockUTXOChanges),
+		blockUTXOChangesDB:    prefixdb.New(blockUTXOChangesPrefix, baseDB),
+
 		currentStakers: newBaseStakers(),
 		pendingStakers: newBaseStakers(),
 
@@ -710,6 +818,82 @@ func (s *state) GetCurrentStakerIterator() (StakerIterator, error) {
 	return s.currentStakers.GetStakerIterator(), nil
 }
 
+func (s *state) GetStaker(subnetID ids.ID, nodeID ids.NodeID) (*Staker, *Staker, error) {
+	current, err := s.GetCurrentValidator(subnetID, nodeID)
+	if err != nil && err != database.ErrNotFound {
+		return nil, nil, err
+	}
+	pending, err := s.GetPendingValidator(subnetID, nodeID)
+	if err != nil && err != database.ErrNotFound {
+		return nil, nil, err
+	}
+	return current, pending, nil
+}
+
+func (s *state) RemoveExpiredStakers(now time.Time) ([]*Staker, error) {
+	stakerIterator, err := s.GetCurrentStakerIterator()
+	if err != nil {
+		return nil, err
+	}
+
+	// Current stakers are sorted by their end time, so the expired stakers are
+	// a prefix of the iterator. They are removed after iterating because the
+	// staker set can't be modified while it's being iterated over.
+	var expired []*Staker
+	for stakerIterator.Next() {
+		staker := stakerIterator.Value()
+		if !staker.EndTime.Before(now) {
+			break
+		}
+		expired = append(expired, staker)
+	}
+	stakerIterator.Release()
+
+	for _, staker := range expired {
+		if staker.Priority.IsCurrentValidator() {
+			s.DeleteCurrentValidator(staker)
+		} else {
+			s.DeleteCurrentDelegator(staker)
+		}
+	}
+	return expired, nil
+}
+
+func (s *state) PreviewReward(staker *Staker, stakeDuration time.Duration, currentSupply uint64) (uint64, error) {
+	if stakeDuration <= 0 {
+		return 0, fmt.Errorf("%w: %s", errNonPositiveStakeDuration, stakeDuration)
+	}
+	if currentSupply == 0 {
+		return 0, errZeroCurrentSupply
+	}
+	return s.rewards.Calculate(stakeDuration, staker.Weight, currentSupply), nil
+}
+
+func (s *state) GetCurrentValidatorsBySubnet(subnetID ids.ID) (StakerIterator, error) {
+	return s.currentStakers.GetValidatorIterator(subnetID), nil
+}
+
+func (s *state) GetTotalStake(subnetID ids.ID) (uint64, error) {
+	stakerIterator, err := s.GetCurrentStakerIterator()
+	if err != nil {
+		return 0, err
+	}
+	defer stakerIterator.Release()
+
+	var totalStake uint64
+	for stakerIterator.Next() {
+		staker := stakerIterator.Value()
+		if staker.SubnetID != subnetID {
+			continue
+		}
+		totalStake, err = math.Add64(totalStake, staker.Weight)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add weight of staker %s: %w", staker.TxID, err)
+		}
+	}
+	return totalStake, nil
+}
+
 func (s *state) GetPendingValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker, error) {
 	return s.pendingStakers.GetValidator(subnetID, nodeID)
 }
@@ -744,9 +928,19 @@ func (s *state) shouldInit() (bool, error) {
 }
 
 func (s *state) doneInit() error {
+	// The timestamps of all blocks accepted by a new state are persisted, so
+	// they never need to be indexed.
+	if err := s.singletonDB.Put(blockTimestampsIndexedKey, nil); err != nil {
+		return err
+	}
 	return s.singletonDB.Put(initializedKey, nil)
 }
 
+func (s *state) ShouldIndexBlockTimestamps() (bool, error) {
+	indexed, err := s.singletonDB.Has(blockTimestampsIndexedKey)
+	return !indexed, err
+}
+
 func (s *state) ShouldPrune() (bool, error) {
 	has, err := s.singletonDB.Has(prunedKey)
 	if err != nil {
@@ -996,6 +1190,18 @@ func (s *state) DeleteUTXO(utxoID ids.ID) {
 	s.modifiedUTXOs[utxoID] = nil
 }
 
+func (*state) WouldConflict(tx *txs.Tx, pendingDiffs []*StateChanges) (bool, error) {
+	if tx == nil || tx.Unsigned == nil {
+		return false, txs.ErrNilSignedTx
+	}
+
+	var consumed set.Set[ids.ID]
+	for _, changes := range pendingDiffs {
+		consumed.Add(changes.RemovedUTXOs...)
+	}
+	return consumed.Overlaps(tx.Unsigned.InputIDs()), nil
+}
+
 func (s *state) GetStartTime(nodeID ids.NodeID, subnetID ids.ID) (time.Time, error) {
 	staker, err := s.currentStakers.GetValidator(subnetID, nodeID)
 	if err != nil {
@@ -1253,10 +1459,68 @@ func (s *state) ApplyValidatorPublicKeyDiffs(
 	return diffIter.Error()
 }
 
+// validateGenesis verifies the internal consistency of [genesis] so that a
+// malformed genesis is rejected before any of it is written.
+//
+// Note: The initial supply also accounts for funds allocated on other chains,
+// so the funds held on this chain at genesis are only required to not exceed
+// it.
+func validateGenesis(genesis *genesis.State) error {
+	var (
+		funds   uint64
+		nodeIDs = set.NewSet[ids.NodeID](len(genesis.Validators))
+		err     error
+	)
+	for _, utxo := range genesis.UTXOs {
+		out, ok := utxo.Out.(avax.Amounter)
+		if !ok {
+			continue
+		}
+		funds, err = math.Add64(funds, out.Amount())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, vdrTx := range genesis.Validators {
+		tx, ok := vdrTx.Unsigned.(*txs.AddValidatorTx)
+		if !ok {
+			return fmt.Errorf("%w: expected *txs.AddValidatorTx but got %T", errWrongGenesisValidatorTxType, vdrTx.Unsigned)
+		}
+
+		nodeID := tx.NodeID()
+		if nodeIDs.Contains(nodeID) {
+			return fmt.Errorf("%w: %s", errDuplicateGenesisValidator, nodeID)
+		}
+		nodeIDs.Add(nodeID)
+
+		funds, err = math.Add64(funds, tx.Weight())
+		if err != nil {
+			return err
+		}
+	}
+
+	if funds > genesis.InitialSupply {
+		return fmt.Errorf(
+			"%w: funds (%d) > initial supply (%d)",
+			errGenesisSupplyExceeded,
+			funds,
+			genesis.InitialSupply,
+		)
+	}
+	return nil
+}
+
 func (s *state) syncGenesis(genesisBlk blocks.Block, genesis *genesis.State) error {
+	if err := validateGenesis(genesis); err != nil {
+		return fmt.Errorf("invalid genesis: %w", err)
+	}
+
 	genesisBlkID := genesisBlk.ID()
+	genesisTime := time.Unix(int64(genesis.Timestamp), 0)
 	s.SetLastAccepted(genesisBlkID)
-	s.SetTimestamp(time.Unix(int64(genesis.Timestamp), 0))
+	s.SetTimestamp(genesisTime)
+	s.SetBlockTimestamp(genesisBlkID, genesisTime)
 	s.SetCurrentSupply(constants.PrimaryNetworkID, genesis.InitialSupply)
 	s.AddStatelessBlock(genesisBlk)
 
@@ -1652,6 +1916,9 @@ func (s *state) write(updateValidators bool, height uint64) error {
 	errs := wrappers.Errs{}
 	errs.Add(
 		s.writeBlocks(),
+		s.writeBlockTimestamps(),
+		s.writeBlockAtomicRequests(),
+		s.writeBlockUTXOChanges(),
 		s.writeCurrentStakers(updateValidators, height),
 		s.writePendingStakers(),
 		s.WriteValidatorMetadata(s.currentValidatorList, s.currentSubnetValidatorList), // Must be called after writeCurrentStakers
@@ -1690,6 +1957,9 @@ func (s *state) Close() error {
 		s.chainDB.Close(),
 		s.singletonDB.Close(),
 		s.blockDB.Close(),
+		s.blockTimestampDB.Close(),
+		s.blockAtomicRequestsDB.Close(),
+		s.blockUTXOChangesDB.Close(),
 		s.blockIDDB.Close(),
 	)
 	return errs.Err
@@ -1820,6 +2090,114 @@ func (s *state) writeBlocks() error {
 	return nil
 }
 
+func (s *state) GetBlockTimestamp(blkID ids.ID) (time.Time, error) {
+	if timestamp, exists := s.addedBlockTimestamps[blkID]; exists {
+		return timestamp, nil
+	}
+	return database.GetTimestamp(s.blockTimestampDB, blkID[:])
+}
+
+func (s *state) SetBlockTimestamp(blkID ids.ID, timestamp time.Time) {
+	s.addedBlockTimestamps[blkID] = timestamp
+}
+
+func (s *state) writeBlockTimestamps() error {
+	for blkID, timestamp := range s.addedBlockTimestamps {
+		blkID := blkID
+
+		delete(s.addedBlockTimestamps, blkID)
+		if err := database.PutTimestamp(s.blockTimestampDB, blkID[:], timestamp); err != nil {
+			return fmt.Errorf("failed to write timestamp of block %s: %w", blkID, err)
+		}
+	}
+	return nil
+}
+
+func (s *state) GetBlockAtomicRequests(blkID ids.ID) (map[ids.ID]*atomic.Requests, error) {
+	if requests, exists := s.addedBlockAtomicRequests[blkID]; exists {
+		return requests, nil
+	}
+
+	requestsBytes, err := s.blockAtomicRequestsDB.Get(blkID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make(map[ids.ID]*atomic.Requests)
+	if _, err := blocks.GenesisCodec.Unmarshal(requestsBytes, &requests); err != nil {
+		return nil, fmt.Errorf("failed to parse atomic requests of block %s: %w", blkID, err)
+	}
+	return requests, nil
+}
+
+func (s *state) AddBlockAtomicRequests(blkID ids.ID, requests map[ids.ID]*atomic.Requests) {
+	s.addedBlockAtomicRequests[blkID] = requests
+}
+
+func (s *state) writeBlockAtomicRequests() error {
+	for blkID, requests := range s.addedBlockAtomicRequests {
+		blkID := blkID
+
+		requestsBytes, err := blocks.GenesisCodec.Marshal(blocks.Version, requests)
+		if err != nil {
+			return fmt.Errorf("failed to serialize atomic requests of block %s: %w", blkID, err)
+		}
+
+		delete(s.addedBlockAtomicRequests, blkID)
+		if err := s.blockAtomicRequestsDB.Put(blkID[:], requestsBytes); err != nil {
+			return fmt.Errorf("failed to write atomic requests of block %s: %w", blkID, err)
+		}
+	}
+	return nil
+}
+
+// blockUTXOChanges is the persisted form of the UTXO changes of a block.
+type blockUTXOChanges struct {
+	Consumed []ids.ID     `serialize:"true"`
+	Produced []*avax.UTXO `serialize:"true"`
+}
+
+func (s *state) GetBlockUTXOChanges(blkID ids.ID) ([]ids.ID, []*avax.UTXO, error) {
+	if changes, exists := s.addedBlockUTXOChanges[blkID]; exists {
+		return changes.Consumed, changes.Produced, nil
+	}
+
+	changesBytes, err := s.blockUTXOChangesDB.Get(blkID[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes := &blockUTXOChanges{}
+	if _, err := txs.GenesisCodec.Unmarshal(changesBytes, changes); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse UTXO changes of block %s: %w", blkID, err)
+	}
+	return changes.Consumed, changes.Produced, nil
+}
+
+func (s *state) SetBlockUTXOChanges(blkID ids.ID, consumed []ids.ID, produced []*avax.UTXO) {
+	s.addedBlockUTXOChanges[blkID] = &blockUTXOChanges{
+		Consumed: consumed,
+		Produced: produced,
+	}
+}
+
+func (s *state) writeBlockUTXOChanges() error {
+	for blkID, changes := range s.addedBlockUTXOChanges {
+		blkID := blkID
+
+		changesBytes, err := txs.GenesisCodec.Marshal(txs.Version, changes)
+		if err != nil {
+			return fmt.Errorf("failed to serialize UTXO changes of block %s: %w", blkID, err)
+		}
+
+		delete(s.addedBlockUTXOChanges, blkID)
+		if err := s.blockUTXOChangesDB.Put(blkID[:], changesBytes); err != nil {
+			return fmt.Errorf("failed to write UTXO changes of block %s: %w", blkID, err)
+		}
+	}
+	return nil
+}
+
 func (s *state) GetStatelessBlock(blockID ids.ID) (blocks.Block, error) {
 	if blk, exists := s.addedBlocks[blockID]; exists {
 		return blk, nil
@@ -2509,3 +2887,158 @@ func (s *state) PruneAndIndex(lock sync.Locker, log logging.Logger) error {
 
 	return s.Commit()
 }
+
+// IndexBlockTimestamps backfills the timestamps of accepted blocks that were
+// written before block timestamps were persisted. Only Banff blocks explicitly
+// include their timestamp, so the timestamps of Apricot blocks remain unknown.
+func (s *state) IndexBlockTimestamps(lock sync.Locker, log logging.Logger) error {
+	lock.Lock()
+	// Blocks are indexed in the order of their keys, so indexing resumes from
+	// the last block that was indexed before the node restarted.
+	startKey, err := s.singletonDB.Get(blockTimestampsIndexProgressKey)
+	if err != nil && err != database.ErrNotFound {
+		lock.Unlock()
+		return fmt.Errorf("failed to get block timestamp index progress: %w", err)
+	}
+	blockIterator := s.blockDB.NewIteratorWithStart(startKey)
+	lock.Unlock()
+	// Releasing is done using a closure to ensure that updating blockIterator
+	// will result in having the most recent iterator released when executing
+	// the deferred function.
+	defer func() {
+		blockIterator.Release()
+	}()
+
+	log.Info("starting block timestamp indexing")
+
+	var (
+		startTime  = time.Now()
+		lastCommit = startTime
+		lastUpdate = startTime
+		numScanned = 0
+		numIndexed = 0
+		timestamps = make(map[ids.ID]time.Time)
+	)
+
+	for blockIterator.Next() {
+		blk, status, _, err := parseStoredBlock(blockIterator.Value())
+		if err != nil {
+			return err
+		}
+		if banffBlk, ok := blk.(blocks.BanffBlock); ok && status == choices.Accepted {
+			timestamps[banffBlk.ID()] = banffBlk.Timestamp()
+		}
+
+		numScanned++
+		if numScanned%pruneCommitLimit != 0 {
+			continue
+		}
+
+		blkID := blk.ID()
+		if err := blockIterator.Error(); err != nil {
+			return err
+		}
+
+		// We must hold the lock during committing to make sure we don't
+		// attempt to commit to disk while a block is concurrently being
+		// accepted.
+		lock.Lock()
+		err = s.writeIndexedBlockTimestamps(timestamps, blkID[:])
+		lock.Unlock()
+		if err != nil {
+			return err
+		}
+		numIndexed += len(timestamps)
+		timestamps = make(map[ids.ID]time.Time)
+
+		// We release the iterator here to allow the underlying database to
+		// clean up deleted state.
+		blockIterator.Release()
+
+		now := time.Now()
+		if now.Sub(lastUpdate) > pruneUpdateFrequency {
+			lastUpdate = now
+
+			progress := timer.ProgressFromHash(blkID[:])
+			eta := timer.EstimateETA(
+				startTime,
+				progress,
+				stdmath.MaxUint64,
+			)
+
+			log.Info("committing block timestamp indexing",
+				zap.Int("numIndexed", numIndexed),
+				zap.Duration("eta", eta),
+			)
+		}
+
+		// See [PruneAndIndex] for why this is capped.
+		indexDuration := now.Sub(lastCommit)
+		sleepDuration := math.Min(
+			pruneCommitSleepMultiplier*indexDuration,
+			pruneCommitSleepCap,
+		)
+		time.Sleep(sleepDuration)
+
+		// Make sure not to include the sleep duration into the next index
+		// duration.
+		lastCommit = time.Now()
+
+		blockIterator = s.blockDB.NewIteratorWithStart(blkID[:])
+	}
+
+	// Ensure we fully iterated over all blocks before writing that indexing
+	// has finished.
+	if err := blockIterator.Error(); err != nil {
+		return err
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	errs := wrappers.Errs{}
+	errs.Add(
+		s.writeIndexedBlockTimestamps(timestamps, nil),
+		s.singletonDB.Put(blockTimestampsIndexedKey, nil),
+		s.singletonDB.Delete(blockTimestampsIndexProgressKey),
+		s.Commit(),
+	)
+	if errs.Errored() {
+		return errs.Err
+	}
+	numIndexed += len(timestamps)
+
+	log.Info("finished block timestamp indexing",
+		zap.Int("numIndexed", numIndexed),
+		zap.Duration("duration", time.Since(startTime)),
+	)
+	return nil
+}
+
+// writeIndexedBlockTimestamps persists [timestamps] for the blocks whose
+// timestamps aren't persisted yet. If [progressKey] is non-nil, it's recorded
+// as the key of the block that indexing resumes from, and the changes are
+// committed.
+//
+// Assumes the lock passed to [IndexBlockTimestamps] is held.
+func (s *state) writeIndexedBlockTimestamps(timestamps map[ids.ID]time.Time, progressKey []byte) error {
+	for blkID, timestamp := range timestamps {
+		has, err := s.blockTimestampDB.Has(blkID[:])
+		if err != nil {
+			return err
+		}
+		if has {
+			continue
+		}
+		if err := database.PutTimestamp(s.blockTimestampDB, blkID[:], timestamp); err != nil {
+			return fmt.Errorf("failed to write timestamp of block %s: %w", blkID, err)
+		}
+	}
+	if progressKey == nil {
+		return nil
+	}
+	if err := s.singletonDB.Put(blockTimestampsIndexProgressKey, progressKey); err != nil {
+		return fmt.Errorf("failed to write block timestamp index progress: %w", err)
+	}
+	return s.Commit()
+}
@@ -4,7 +4,9 @@
 package state
 
 import (
+	"bytes"
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"go.uber.org/mock/gomock"
+
+	"github.com/ava-labs/avalanchego/chains/atomic"
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
@@ -23,7 +28,9 @@ import (
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/constants"
 	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
 	"github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 	"github.com/ava-labs/avalanchego/vms/components/avax"
@@ -32,6 +39,7 @@ import (
 	"github.com/ava-labs/avalanchego/vms/platformvm/genesis"
 	"github.com/ava-labs/avalanchego/vms/platformvm/metrics"
 	"github.com/ava-labs/avalanchego/vms/platformvm/reward"
+	"github.com/ava-labs/avalanchego/vms/platformvm/status"
 	"github.com/ava-labs/avalanchego/vms/platformvm/txs"
 	"github.com/ava-labs/avalanchego/vms/secp256k1fx"
 )
@@ -86,6 +94,106 @@ func TestStateSyncGenesis(t *testing.T) {
 	assertIteratorsEqual(t, EmptyIterator, delegatorIterator)
 }
 
+func TestStateSyncGenesisValidation(t *testing.T) {
+	newValidatorTx := func(nodeID ids.NodeID, weight uint64) *txs.Tx {
+		return &txs.Tx{Unsigned: &txs.AddValidatorTx{
+			Validator: txs.Validator{
+				NodeID: nodeID,
+				Start:  uint64(initialTime.Unix()),
+				End:    uint64(initialValidatorEndTime.Unix()),
+				Wght:   weight,
+			},
+			RewardsOwner:     &secp256k1fx.OutputOwners{},
+			DelegationShares: reward.PercentDenominator,
+		}}
+	}
+	newUTXO := func(amount uint64) *avax.UTXO {
+		return &avax.UTXO{
+			UTXOID: avax.UTXOID{
+				TxID: ids.GenerateTestID(),
+			},
+			Asset: avax.Asset{ID: initialTxID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: amount,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		genesis     *genesis.State
+		expectedErr error
+	}{
+		{
+			name: "valid",
+			genesis: &genesis.State{
+				UTXOs: []*avax.UTXO{
+					newUTXO(units.Avax),
+				},
+				Validators: []*txs.Tx{
+					newValidatorTx(ids.GenerateTestNodeID(), units.Avax),
+				},
+				Timestamp:     uint64(initialTime.Unix()),
+				InitialSupply: 3 * units.Avax,
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "duplicate validator node ID",
+			genesis: &genesis.State{
+				Validators: []*txs.Tx{
+					newValidatorTx(initialNodeID, units.Avax),
+					newValidatorTx(initialNodeID, units.Avax),
+				},
+				Timestamp:     uint64(initialTime.Unix()),
+				InitialSupply: 2 * units.Avax,
+			},
+			expectedErr: errDuplicateGenesisValidator,
+		},
+		{
+			name: "funds exceed initial supply",
+			genesis: &genesis.State{
+				UTXOs: []*avax.UTXO{
+					newUTXO(units.Avax),
+				},
+				Validators: []*txs.Tx{
+					newValidatorTx(ids.GenerateTestNodeID(), units.Avax),
+				},
+				Timestamp:     uint64(initialTime.Unix()),
+				InitialSupply: 2*units.Avax - 1,
+			},
+			expectedErr: errGenesisSupplyExceeded,
+		},
+		{
+			name: "wrong validator tx type",
+			genesis: &genesis.State{
+				Validators: []*txs.Tx{
+					{Unsigned: &txs.AdvanceTimeTx{}},
+				},
+				Timestamp:     uint64(initialTime.Unix()),
+				InitialSupply: units.Avax,
+			},
+			expectedErr: errWrongGenesisValidatorTxType,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			for _, tx := range test.genesis.Validators {
+				require.NoError(tx.Initialize(txs.Codec))
+			}
+
+			s, _ := newUninitializedState(require)
+			genesisBlk, err := blocks.NewApricotCommitBlock(ids.GenerateTestID(), 0)
+			require.NoError(err)
+
+			err = s.(*state).syncGenesis(genesisBlk, test.genesis)
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+}
+
 func newInitializedState(require *require.Assertions) (State, database.Database) {
 	s, db := newUninitializedState(require)
 
@@ -512,6 +620,311 @@ func TestStateAddRemoveValidator(t *testing.T) {
 	}
 }
 
+func TestStateGetCurrentValidatorsBySubnet(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	var (
+		subnetID      = ids.GenerateTestID()
+		otherSubnetID = ids.GenerateTestID()
+		startTime     = time.Now().Round(time.Second)
+	)
+	newValidator := func(subnetID ids.ID, offset time.Duration) *Staker {
+		endTime := startTime.Add(24 * time.Hour).Add(offset)
+		return &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  subnetID,
+			Weight:    1,
+			StartTime: startTime,
+			EndTime:   endTime,
+			NextTime:  endTime,
+			Priority:  txs.SubnetPermissionedValidatorCurrentPriority,
+		}
+	}
+
+	subnetValidators := []*Staker{
+		newValidator(subnetID, 0),
+		newValidator(subnetID, time.Second),
+	}
+	otherSubnetValidator := newValidator(otherSubnetID, 0)
+
+	state.PutCurrentValidator(subnetValidators[1])
+	state.PutCurrentValidator(otherSubnetValidator)
+	state.PutCurrentValidator(subnetValidators[0])
+
+	it, err := state.GetCurrentValidatorsBySubnet(subnetID)
+	require.NoError(err)
+	assertIteratorsEqual(t, NewSliceIterator(subnetValidators...), it)
+
+	it, err = state.GetCurrentValidatorsBySubnet(otherSubnetID)
+	require.NoError(err)
+	assertIteratorsEqual(t, NewSliceIterator(otherSubnetValidator), it)
+
+	it, err = state.GetCurrentValidatorsBySubnet(ids.GenerateTestID())
+	require.NoError(err)
+	assertIteratorsEqual(t, EmptyIterator, it)
+}
+
+func TestStateGetTotalStake(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	var (
+		subnetID      = ids.GenerateTestID()
+		otherSubnetID = ids.GenerateTestID()
+		nodeID        = ids.GenerateTestNodeID()
+	)
+	newStaker := func(subnetID ids.ID, nodeID ids.NodeID, weight uint64) *Staker {
+		return &Staker{
+			TxID:     ids.GenerateTestID(),
+			NodeID:   nodeID,
+			SubnetID: subnetID,
+			Weight:   weight,
+		}
+	}
+
+	state.PutCurrentValidator(newStaker(subnetID, nodeID, 1))
+	state.PutCurrentValidator(newStaker(subnetID, ids.GenerateTestNodeID(), 20))
+	state.PutCurrentDelegator(newStaker(subnetID, nodeID, 300))
+	state.PutCurrentValidator(newStaker(otherSubnetID, ids.GenerateTestNodeID(), 4_000))
+	// Pending stakers aren't included.
+	state.PutPendingValidator(newStaker(subnetID, ids.GenerateTestNodeID(), 50_000))
+
+	totalStake, err := state.GetTotalStake(subnetID)
+	require.NoError(err)
+	require.Equal(uint64(321), totalStake)
+
+	totalStake, err = state.GetTotalStake(ids.GenerateTestID())
+	require.NoError(err)
+	require.Zero(totalStake)
+
+	// The total can't overflow.
+	state.PutCurrentValidator(newStaker(otherSubnetID, ids.GenerateTestNodeID(), stdmath.MaxUint64-4_000))
+	totalStake, err = state.GetTotalStake(otherSubnetID)
+	require.NoError(err)
+	require.Equal(uint64(stdmath.MaxUint64), totalStake)
+
+	state.PutCurrentDelegator(newStaker(otherSubnetID, nodeID, 1))
+	_, err = state.GetTotalStake(otherSubnetID)
+	require.ErrorIs(err, math.ErrOverflow)
+}
+
+func TestStateGetStaker(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	subnetID := ids.GenerateTestID()
+	newValidator := func(nodeID ids.NodeID) *Staker {
+		return &Staker{
+			TxID:     ids.GenerateTestID(),
+			NodeID:   nodeID,
+			SubnetID: subnetID,
+			Weight:   1,
+		}
+	}
+
+	var (
+		currentOnlyNodeID = ids.GenerateTestNodeID()
+		pendingOnlyNodeID = ids.GenerateTestNodeID()
+		bothNodeID        = ids.GenerateTestNodeID()
+		neitherNodeID     = ids.GenerateTestNodeID()
+
+		currentOnly = newValidator(currentOnlyNodeID)
+		pendingOnly = newValidator(pendingOnlyNodeID)
+		bothCurrent = newValidator(bothNodeID)
+		bothPending = newValidator(bothNodeID)
+	)
+	state.PutCurrentValidator(currentOnly)
+	state.PutPendingValidator(pendingOnly)
+	state.PutCurrentValidator(bothCurrent)
+	state.PutPendingValidator(bothPending)
+
+	tests := []struct {
+		name            string
+		nodeID          ids.NodeID
+		expectedCurrent *Staker
+		expectedPending *Staker
+	}{
+		{
+			name:            "current only",
+			nodeID:          currentOnlyNodeID,
+			expectedCurrent: currentOnly,
+		},
+		{
+			name:            "pending only",
+			nodeID:          pendingOnlyNodeID,
+			expectedPending: pendingOnly,
+		},
+		{
+			name:            "both",
+			nodeID:          bothNodeID,
+			expectedCurrent: bothCurrent,
+			expectedPending: bothPending,
+		},
+		{
+			name:   "neither",
+			nodeID: neitherNodeID,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(*testing.T) {
+			current, pending, err := state.GetStaker(subnetID, test.nodeID)
+			require.NoError(err)
+			require.Equal(test.expectedCurrent, current)
+			require.Equal(test.expectedPending, pending)
+		})
+	}
+}
+
+func TestStateGetPendingStakerIterator(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	// The genesis doesn't contain any pending stakers.
+	stakerIterator, err := state.GetPendingStakerIterator()
+	require.NoError(err)
+	require.Equal(EmptyIterator, stakerIterator)
+
+	startTime := time.Unix(1_000, 0)
+	newPendingValidator := func(subnetID ids.ID, startOffset time.Duration, priority txs.Priority) *Staker {
+		start := startTime.Add(startOffset)
+		return &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  subnetID,
+			Weight:    1,
+			StartTime: start,
+			EndTime:   start.Add(24 * time.Hour),
+			NextTime:  start,
+			Priority:  priority,
+		}
+	}
+
+	var (
+		subnetID = ids.GenerateTestID()
+		second   = newPendingValidator(subnetID, 2*time.Hour, txs.SubnetPermissionedValidatorPendingPriority)
+		third    = newPendingValidator(constants.PrimaryNetworkID, 3*time.Hour, txs.PrimaryNetworkValidatorPendingPriority)
+		first    = newPendingValidator(constants.PrimaryNetworkID, time.Hour, txs.PrimaryNetworkValidatorPendingPriority)
+	)
+	state.PutPendingValidator(second)
+	state.PutPendingValidator(third)
+	state.PutPendingValidator(first)
+
+	// Stakers of every subnet are yielded in order of their start times,
+	// regardless of the order they were added in.
+	stakerIterator, err = state.GetPendingStakerIterator()
+	require.NoError(err)
+	assertIteratorsEqual(t, NewSliceIterator(first, second, third), stakerIterator)
+}
+
+func TestStatePreviewReward(t *testing.T) {
+	require := require.New(t)
+
+	state, _ := newInitializedState(require)
+
+	initialSupply, err := state.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+
+	staker := &Staker{
+		TxID:     ids.GenerateTestID(),
+		NodeID:   ids.GenerateTestNodeID(),
+		SubnetID: constants.PrimaryNetworkID,
+		Weight:   2000 * units.Avax,
+	}
+
+	// Staking for the full minting period with half of the supply cap minted
+	// uses the max consumption rate of 12%:
+	//
+	// Reward = RemainingSupply * PortionOfExistingSupply * MintingRate
+	//        = 360M * (2000 / 360M) * 0.12
+	//        = 240
+	reward, err := state.PreviewReward(staker, 365*24*time.Hour, 360*units.MegaAvax)
+	require.NoError(err)
+	require.Equal(240*units.Avax, reward)
+
+	// Previewing a reward must not modify the supply.
+	currentSupply, err := state.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(initialSupply, currentSupply)
+
+	_, err = state.PreviewReward(staker, 0, 360*units.MegaAvax)
+	require.ErrorIs(err, errNonPositiveStakeDuration)
+
+	_, err = state.PreviewReward(staker, time.Hour, 0)
+	require.ErrorIs(err, errZeroCurrentSupply)
+}
+
+func TestStateRemoveExpiredStakers(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	newStaker := func(nodeID ids.NodeID, endTime time.Time, priority txs.Priority) *Staker {
+		return &Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    nodeID,
+			SubnetID:  constants.PrimaryNetworkID,
+			Weight:    units.Avax,
+			StartTime: initialTime,
+			EndTime:   endTime,
+			NextTime:  endTime,
+			Priority:  priority,
+		}
+	}
+
+	var (
+		now = initialTime.Add(2 * time.Hour)
+
+		expiredNodeID  = ids.GenerateTestNodeID()
+		expiredVdr     = newStaker(expiredNodeID, initialTime.Add(time.Hour), txs.PrimaryNetworkValidatorCurrentPriority)
+		expiredDel     = newStaker(expiredNodeID, initialTime.Add(30*time.Minute), txs.PrimaryNetworkDelegatorCurrentPriority)
+		endsAtNowVdr   = newStaker(ids.GenerateTestNodeID(), now, txs.PrimaryNetworkValidatorCurrentPriority)
+		activeNodeID   = ids.GenerateTestNodeID()
+		activeVdr      = newStaker(activeNodeID, initialTime.Add(3*time.Hour), txs.PrimaryNetworkValidatorCurrentPriority)
+		expiredActDel  = newStaker(activeNodeID, initialTime.Add(90*time.Minute), txs.PrimaryNetworkDelegatorCurrentPriority)
+		activeDel      = newStaker(activeNodeID, initialTime.Add(150*time.Minute), txs.PrimaryNetworkDelegatorCurrentPriority)
+		expectedRemove = []*Staker{expiredDel, expiredVdr, expiredActDel}
+	)
+	s.PutCurrentValidator(expiredVdr)
+	s.PutCurrentDelegator(expiredDel)
+	s.PutCurrentValidator(endsAtNowVdr)
+	s.PutCurrentValidator(activeVdr)
+	s.PutCurrentDelegator(expiredActDel)
+	s.PutCurrentDelegator(activeDel)
+
+	removed, err := s.RemoveExpiredStakers(now)
+	require.NoError(err)
+	require.Equal(expectedRemove, removed)
+
+	_, err = s.GetCurrentValidator(constants.PrimaryNetworkID, expiredNodeID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	vdr, err := s.GetCurrentValidator(constants.PrimaryNetworkID, endsAtNowVdr.NodeID)
+	require.NoError(err)
+	require.Equal(endsAtNowVdr, vdr)
+
+	vdr, err = s.GetCurrentValidator(constants.PrimaryNetworkID, activeNodeID)
+	require.NoError(err)
+	require.Equal(activeVdr, vdr)
+
+	delegatorIterator, err := s.GetCurrentDelegatorIterator(constants.PrimaryNetworkID, activeNodeID)
+	require.NoError(err)
+	require.True(delegatorIterator.Next())
+	require.Equal(activeDel, delegatorIterator.Value())
+	require.False(delegatorIterator.Next())
+	delegatorIterator.Release()
+
+	// Nothing else has expired.
+	removed, err = s.RemoveExpiredStakers(now)
+	require.NoError(err)
+	require.Empty(removed)
+}
+
 func copyValidatorSet(
 	input map[ids.NodeID]*validators.GetValidatorOutput,
 ) map[ids.NodeID]*validators.GetValidatorOutput {
@@ -553,6 +966,271 @@ func requireEqualPublicKeysValidatorSet(
 	}
 }
 
+func TestStateBlockTimestamps(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	blkTime := initialTime.Add(time.Minute)
+	blk, err := blocks.NewBanffStandardBlock(blkTime, ids.GenerateTestID(), 1, nil)
+	require.NoError(err)
+	blkID := blk.ID()
+
+	_, err = s.GetBlockTimestamp(blkID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	s.AddStatelessBlock(blk)
+	s.SetBlockTimestamp(blkID, blkTime)
+	require.NoError(s.Commit())
+
+	// Reload the state from disk.
+	s = newStateFromDB(require, db)
+
+	timestamp, err := s.GetBlockTimestamp(blkID)
+	require.NoError(err)
+	require.True(blkTime.Equal(timestamp))
+}
+
+func TestStateBlockAtomicRequests(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	blkID := ids.GenerateTestID()
+	_, err := s.GetBlockAtomicRequests(blkID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	chainID := ids.GenerateTestID()
+	requests := map[ids.ID]*atomic.Requests{
+		chainID: {
+			RemoveRequests: [][]byte{{1, 2, 3}},
+			PutRequests: []*atomic.Element{{
+				Key:    []byte{4},
+				Value:  []byte{5, 6},
+				Traits: [][]byte{{7}},
+			}},
+		},
+	}
+	s.AddBlockAtomicRequests(blkID, requests)
+
+	gotRequests, err := s.GetBlockAtomicRequests(blkID)
+	require.NoError(err)
+	require.Equal(requests, gotRequests)
+
+	require.NoError(s.Commit())
+
+	// Reload the state from disk.
+	s = newStateFromDB(require, db)
+
+	gotRequests, err = s.GetBlockAtomicRequests(blkID)
+	require.NoError(err)
+	require.Equal(requests, gotRequests)
+}
+
+func TestStateBlockUTXOChanges(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	blkID := ids.GenerateTestID()
+	_, _, err := s.GetBlockUTXOChanges(blkID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	consumed := []ids.ID{ids.GenerateTestID(), ids.GenerateTestID()}
+	produced := []*avax.UTXO{{
+		UTXOID: avax.UTXOID{
+			TxID:        ids.GenerateTestID(),
+			OutputIndex: 1,
+		},
+		Asset: avax.Asset{ID: ids.GenerateTestID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: 1,
+			OutputOwners: secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+			},
+		},
+	}}
+	s.SetBlockUTXOChanges(blkID, consumed, produced)
+
+	gotConsumed, gotProduced, err := s.GetBlockUTXOChanges(blkID)
+	require.NoError(err)
+	require.Equal(consumed, gotConsumed)
+	require.Equal(produced, gotProduced)
+
+	require.NoError(s.Commit())
+
+	// Reload the state from disk.
+	s = newStateFromDB(require, db)
+
+	gotConsumed, gotProduced, err = s.GetBlockUTXOChanges(blkID)
+	require.NoError(err)
+	require.Equal(consumed, gotConsumed)
+	require.Len(gotProduced, 1)
+	require.Equal(produced[0].InputID(), gotProduced[0].InputID())
+	require.Equal(produced[0].Asset, gotProduced[0].Asset)
+	require.Equal(produced[0].Out, gotProduced[0].Out)
+}
+
+func TestStateUptime(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	// Uptimes are only tracked for current validators.
+	unknownNodeID := ids.GenerateTestNodeID()
+	_, _, err := s.GetUptime(unknownNodeID, constants.PrimaryNetworkID)
+	require.ErrorIs(err, database.ErrNotFound)
+	err = s.SetUptime(unknownNodeID, constants.PrimaryNetworkID, time.Hour, initialTime)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	upDuration := time.Hour
+	lastUpdated := initialTime.Add(2 * time.Hour)
+	require.NoError(s.SetUptime(initialNodeID, constants.PrimaryNetworkID, upDuration, lastUpdated))
+	require.NoError(s.Commit())
+
+	// Reload the state and its current validators from disk.
+	s = newStateFromDB(require, db)
+	require.NoError(s.(*state).loadCurrentValidators())
+
+	gotUpDuration, gotLastUpdated, err := s.GetUptime(initialNodeID, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(upDuration, gotUpDuration)
+	require.True(lastUpdated.Equal(gotLastUpdated))
+}
+
+func TestStateGetSubnets(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	// The primary network isn't included.
+	subnets, err := s.GetSubnets()
+	require.NoError(err)
+	require.Empty(subnets)
+
+	expectedSubnets := make([]*txs.Tx, 3)
+	for i := range expectedSubnets {
+		createSubnetTx := &txs.Tx{
+			Unsigned: &txs.CreateSubnetTx{
+				Owner: &secp256k1fx.OutputOwners{
+					Threshold: 1,
+					Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+				},
+			},
+		}
+		require.NoError(createSubnetTx.Initialize(txs.Codec))
+		s.AddTx(createSubnetTx, status.Committed)
+		s.AddSubnet(createSubnetTx)
+		expectedSubnets[i] = createSubnetTx
+	}
+
+	subnets, err = s.GetSubnets()
+	require.NoError(err)
+	require.ElementsMatch(expectedSubnets, subnets)
+
+	require.NoError(s.Commit())
+
+	// Reload the state from disk.
+	s = newStateFromDB(require, db)
+
+	subnets, err = s.GetSubnets()
+	require.NoError(err)
+	expectedSubnetIDs := set.NewSet[ids.ID](len(expectedSubnets))
+	for _, expectedSubnet := range expectedSubnets {
+		expectedSubnetIDs.Add(expectedSubnet.ID())
+	}
+	subnetIDs := set.NewSet[ids.ID](len(subnets))
+	for _, subnet := range subnets {
+		subnetIDs.Add(subnet.ID())
+	}
+	require.Len(subnets, len(expectedSubnets))
+	require.Equal(expectedSubnetIDs, subnetIDs)
+}
+
+func TestStateIndexBlockTimestamps(t *testing.T) {
+	require := require.New(t)
+
+	s, db := newInitializedState(require)
+
+	// Write blocks as if they were accepted before block timestamps were
+	// persisted.
+	banffBlkTime := initialTime.Add(time.Minute)
+	banffBlk, err := blocks.NewBanffStandardBlock(banffBlkTime, ids.GenerateTestID(), 1, nil)
+	require.NoError(err)
+	apricotBlk, err := blocks.NewApricotStandardBlock(banffBlk.ID(), 2, nil)
+	require.NoError(err)
+
+	s.AddStatelessBlock(banffBlk)
+	s.AddStatelessBlock(apricotBlk)
+	require.NoError(s.(*state).singletonDB.Delete(blockTimestampsIndexedKey))
+	require.NoError(s.Commit())
+
+	banffBlkID := banffBlk.ID()
+	apricotBlkID := apricotBlk.ID()
+	_, err = s.GetBlockTimestamp(banffBlkID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Indexing isn't done when the state is loaded.
+	s = newStateFromDB(require, db)
+	shouldIndex, err := s.ShouldIndexBlockTimestamps()
+	require.NoError(err)
+	require.True(shouldIndex)
+	_, err = s.GetBlockTimestamp(banffBlkID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(s.IndexBlockTimestamps(&sync.Mutex{}, logging.NoLog{}))
+
+	timestamp, err := s.GetBlockTimestamp(banffBlkID)
+	require.NoError(err)
+	require.True(banffBlkTime.Equal(timestamp))
+
+	// Apricot blocks don't include their timestamp.
+	_, err = s.GetBlockTimestamp(apricotBlkID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	shouldIndex, err = s.ShouldIndexBlockTimestamps()
+	require.NoError(err)
+	require.False(shouldIndex)
+	has, err := s.(*state).singletonDB.Has(blockTimestampsIndexProgressKey)
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestStateIndexBlockTimestampsResumes(t *testing.T) {
+	require := require.New(t)
+
+	s, _ := newInitializedState(require)
+
+	blk1, err := blocks.NewBanffStandardBlock(initialTime.Add(time.Minute), ids.GenerateTestID(), 1, nil)
+	require.NoError(err)
+	blk2, err := blocks.NewBanffStandardBlock(initialTime.Add(2*time.Minute), blk1.ID(), 2, nil)
+	require.NoError(err)
+	s.AddStatelessBlock(blk1)
+	s.AddStatelessBlock(blk2)
+	require.NoError(s.(*state).singletonDB.Delete(blockTimestampsIndexedKey))
+	require.NoError(s.Commit())
+
+	// Blocks are indexed in the order of their IDs. Record that indexing
+	// stopped after the block with the smaller ID.
+	indexedBlk, unindexedBlk := blk1, blk2
+	indexedBlkID, unindexedBlkID := indexedBlk.ID(), unindexedBlk.ID()
+	if bytes.Compare(indexedBlkID[:], unindexedBlkID[:]) > 0 {
+		indexedBlk, unindexedBlk = unindexedBlk, indexedBlk
+		indexedBlkID, unindexedBlkID = unindexedBlkID, indexedBlkID
+	}
+	progressKey := append(indexedBlkID[:], 0)
+	require.NoError(s.(*state).singletonDB.Put(blockTimestampsIndexProgressKey, progressKey))
+
+	require.NoError(s.IndexBlockTimestamps(&sync.Mutex{}, logging.NoLog{}))
+
+	_, err = s.GetBlockTimestamp(indexedBlkID)
+	require.ErrorIs(err, database.ErrNotFound)
+	timestamp, err := s.GetBlockTimestamp(unindexedBlkID)
+	require.NoError(err)
+	require.True(unindexedBlk.Timestamp().Equal(timestamp))
+}
+
 func TestParsedStateBlock(t *testing.T) {
 	require := require.New(t)
 
@@ -657,3 +1335,59 @@ func TestParsedStateBlock(t *testing.T) {
 		require.Equal(blk.ID(), gotBlk.ID())
 	}
 }
+
+func TestStateWouldConflict(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	s, _ := newInitializedState(require)
+	lastAcceptedID := s.GetLastAccepted()
+	versions := NewMockVersions(ctrl)
+	versions.EXPECT().GetState(lastAcceptedID).AnyTimes().Return(s, true)
+
+	spentUTXOID := avax.UTXOID{
+		TxID:        ids.GenerateTestID(),
+		OutputIndex: 1,
+	}
+	unspentUTXOID := avax.UTXOID{
+		TxID:        ids.GenerateTestID(),
+		OutputIndex: 2,
+	}
+	newTx := func(utxoID avax.UTXOID) *txs.Tx {
+		return &txs.Tx{
+			Unsigned: &txs.CreateSubnetTx{
+				BaseTx: txs.BaseTx{
+					BaseTx: avax.BaseTx{
+						Ins: []*avax.TransferableInput{{
+							UTXOID: utxoID,
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	// A pending block consumes [spentUTXOID].
+	d, err := NewDiff(lastAcceptedID, versions)
+	require.NoError(err)
+	d.DeleteUTXO(spentUTXOID.InputID())
+	changes, err := d.Changes()
+	require.NoError(err)
+	pendingDiffs := []*StateChanges{changes}
+
+	conflicts, err := s.WouldConflict(newTx(spentUTXOID), pendingDiffs)
+	require.NoError(err)
+	require.True(conflicts)
+
+	conflicts, err = s.WouldConflict(newTx(unspentUTXOID), pendingDiffs)
+	require.NoError(err)
+	require.False(conflicts)
+
+	// Without pending diffs, nothing conflicts.
+	conflicts, err = s.WouldConflict(newTx(spentUTXOID), nil)
+	require.NoError(err)
+	require.False(conflicts)
+
+	_, err = s.WouldConflict(&txs.Tx{}, pendingDiffs)
+	require.ErrorIs(err, txs.ErrNilSignedTx)
+}
@@ -24,7 +24,7 @@ type treeIterator struct {
 // NewTreeIterator returns a new iterator of the stakers in [tree] in ascending
 // order. Note that it isn't safe to modify [tree] while iterating over it.
 func NewTreeIterator(tree *btree.BTreeG[*Staker]) StakerIterator {
-	if tree == nil {
+	if tree == nil || tree.Len() == 0 {
 		return EmptyIterator
 	}
 	it := &treeIterator{
@@ -218,6 +218,24 @@ func (vm *VM) Initialize(
 		return err
 	}
 
+	shouldIndexBlockTimestamps, err := vm.state.ShouldIndexBlockTimestamps()
+	if err != nil {
+		return fmt.Errorf(
+			"failed to check if block timestamps should be indexed: %w",
+			err,
+		)
+	}
+	if shouldIndexBlockTimestamps {
+		go func() {
+			err := vm.state.IndexBlockTimestamps(&vm.ctx.Lock, vm.ctx.Log)
+			if err != nil {
+				vm.ctx.Log.Error("block timestamp indexing failed",
+					zap.Error(err),
+				)
+			}
+		}()
+	}
+
 	shouldPrune, err := vm.state.ShouldPrune()
 	if err != nil {
 		return fmt.Errorf(
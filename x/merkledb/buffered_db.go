@@ -0,0 +1,164 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"sync"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+)
+
+var (
+	_ database.Database = (*bufferedDB)(nil)
+	_ database.Batch    = (*bufferedBatch)(nil)
+)
+
+// bufferedDB is a database whose writes can be buffered in memory until
+// they're committed to the underlying database. Reads reflect the buffered
+// writes. Buffering can be started and committed while other goroutines read
+// from and write to the database.
+type bufferedDB struct {
+	database.Database
+
+	// Must be held when reading/writing [buffer]. Held for reading while
+	// [buffer] or the underlying database is being read or written.
+	lock sync.RWMutex
+	// Nil unless writes are being buffered.
+	buffer *versiondb.Database
+}
+
+func newBufferedDB(db database.Database) *bufferedDB {
+	return &bufferedDB{
+		Database: db,
+	}
+}
+
+// startBuffering causes subsequent writes to be buffered until
+// [commitBuffer] is called.
+// Assumes writes aren't already being buffered.
+func (b *bufferedDB) startBuffering() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.buffer = versiondb.New(b.Database)
+}
+
+// commitBuffer writes the buffered writes to the underlying database in a
+// single batch and stops buffering writes. If the write fails, writes are
+// still buffered.
+func (b *bufferedDB) commitBuffer() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.buffer == nil {
+		return nil
+	}
+	if err := b.buffer.Commit(); err != nil {
+		return err
+	}
+	b.buffer = nil
+	return nil
+}
+
+// target returns the database that reads and writes are made to.
+// Assumes [b.lock] is held.
+func (b *bufferedDB) target() database.Database {
+	if b.buffer != nil {
+		return b.buffer
+	}
+	return b.Database
+}
+
+func (b *bufferedDB) Has(key []byte) (bool, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.target().Has(key)
+}
+
+func (b *bufferedDB) Get(key []byte) ([]byte, error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.target().Get(key)
+}
+
+func (b *bufferedDB) Put(key []byte, value []byte) error {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.target().Put(key, value)
+}
+
+func (b *bufferedDB) Delete(key []byte) error {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.target().Delete(key)
+}
+
+func (b *bufferedDB) NewBatch() database.Batch {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return &bufferedBatch{
+		Batch:  b.target().NewBatch(),
+		db:     b,
+		buffer: b.buffer,
+	}
+}
+
+func (b *bufferedDB) NewIterator() database.Iterator {
+	return b.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (b *bufferedDB) NewIteratorWithStart(start []byte) database.Iterator {
+	return b.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (b *bufferedDB) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return b.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// The returned iterator reflects the writes buffered when it was created. If
+// the buffer is committed while iterating, the iterator reads the committed
+// writes from the underlying database.
+func (b *bufferedDB) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b.target().NewIteratorWithStartAndPrefix(start, prefix)
+}
+
+// bufferedBatch is a batch of writes to a [bufferedDB].
+type bufferedBatch struct {
+	database.Batch
+	db *bufferedDB
+	// The buffer [Batch] writes to, or nil if it writes to the underlying
+	// database.
+	buffer *versiondb.Database
+}
+
+func (b *bufferedBatch) Write() error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	if b.buffer == b.db.buffer {
+		return b.Batch.Write()
+	}
+
+	// Buffering was started or committed since the batch was created, so the
+	// writes are made to the current target instead. Otherwise, they could be
+	// written to a buffer that is never committed.
+	batch := b.db.target().NewBatch()
+	if err := b.Batch.Replay(batch); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (b *bufferedBatch) Inner() database.Batch {
+	return b
+}
@@ -4,27 +4,39 @@
 package merkledb
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/ava-labs/avalanchego/utils/linkedhashmap"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
-// A cache that calls [onEviction] on the evicted element.
+var ErrTooManyPinnedKeys = errors.New("too many pinned keys")
+
+// A cache that calls [onEviction] on the evicted element and its key.
+// Pinned keys are never evicted and don't count towards [maxSize].
 type onEvictCache[K comparable, V any] struct {
 	lock    sync.RWMutex
 	maxSize int
 	fifo    linkedhashmap.LinkedHashmap[K, V]
+	// The maximum number of pinned keys.
+	maxPinned int
+	pinned    set.Set[K]
+	// The elements of the pinned keys that are in the cache.
+	pinnedValues map[K]V
 	// Must not call any method that grabs [c.lock]
 	// because this would cause a deadlock.
-	onEviction func(V) error
+	onEviction func(K, V) error
 }
 
-func newOnEvictCache[K comparable, V any](maxSize int, onEviction func(V) error) onEvictCache[K, V] {
+func newOnEvictCache[K comparable, V any](maxSize int, onEviction func(K, V) error) onEvictCache[K, V] {
 	return onEvictCache[K, V]{
-		maxSize:    maxSize,
-		fifo:       linkedhashmap.New[K, V](),
-		onEviction: onEviction,
+		maxSize:      maxSize,
+		fifo:         linkedhashmap.New[K, V](),
+		pinnedValues: make(map[K]V),
+		onEviction:   onEviction,
 	}
 }
 
@@ -43,6 +55,9 @@ func (c *onEvictCache[K, V]) Get(key K) (V, bool) {
 	c.lock.RLock()
 	defer c.lock.RUnlock()
 
+	if value, ok := c.pinnedValues[key]; ok {
+		return value, true
+	}
 	return c.fifo.Get(key)
 }
 
@@ -53,12 +68,23 @@ func (c *onEvictCache[K, V]) Put(key K, value V) error {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	return c.put(key, value)
+}
+
+// put is [Put] without locking.
+// Assumes [c.lock] is held.
+func (c *onEvictCache[K, V]) put(key K, value V) error {
+	if c.pinned.Contains(key) {
+		c.pinnedValues[key] = value
+		return nil
+	}
+
 	c.fifo.Put(key, value) // Mark as MRU
 
 	if c.fifo.Len() > c.maxSize {
 		oldestKey, oldestVal, _ := c.fifo.Oldest()
 		c.fifo.Delete(oldestKey)
-		return c.onEviction(oldestVal)
+		return c.onEviction(oldestKey, oldestVal)
 	}
 	return nil
 }
@@ -71,19 +97,73 @@ func (c *onEvictCache[K, V]) Flush() error {
 	c.lock.Lock()
 	defer func() {
 		c.fifo = linkedhashmap.New[K, V]()
+		c.pinnedValues = make(map[K]V)
 		c.lock.Unlock()
 	}()
 
+	// Pinned keys stay pinned, but their elements are evicted.
+	var errs wrappers.Errs
+	for key, value := range c.pinnedValues {
+		errs.Add(c.onEviction(key, value))
+	}
+
 	// Note that we can't use [c.fifo]'s iterator because [c.onEviction]
 	// modifies [c.fifo], which violates the iterator's invariant.
-	var errs wrappers.Errs
 	for {
-		_, node, exists := c.removeOldest()
+		key, node, exists := c.removeOldest()
 		if !exists {
 			// The cache is empty.
 			return errs.Err
 		}
 
-		errs.Add(c.onEviction(node))
+		errs.Add(c.onEviction(key, node))
+	}
+}
+
+// Pin marks [keys] as pinned so that their elements are never evicted.
+// Elements of [keys] that are already in the cache stay in it.
+// Returns [ErrTooManyPinnedKeys] without pinning any key if more than
+// [c.maxPinned] keys would be pinned.
+func (c *onEvictCache[K, V]) Pin(keys []K) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	newPinned := 0
+	for _, key := range keys {
+		if !c.pinned.Contains(key) {
+			newPinned++
+		}
+	}
+	if numPinned := c.pinned.Len() + newPinned; numPinned > c.maxPinned {
+		return fmt.Errorf("%w: %d > %d", ErrTooManyPinnedKeys, numPinned, c.maxPinned)
+	}
+
+	for _, key := range keys {
+		c.pinned.Add(key)
+		if value, ok := c.fifo.Get(key); ok {
+			c.fifo.Delete(key)
+			c.pinnedValues[key] = value
+		}
+	}
+	return nil
+}
+
+// Unpin marks [keys] as no longer pinned. Their elements that are in the
+// cache become the most recently used elements and may be evicted.
+// Returns the last non-nil error during [c.onEviction], if any.
+func (c *onEvictCache[K, V]) Unpin(keys []K) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var errs wrappers.Errs
+	for _, key := range keys {
+		c.pinned.Remove(key)
+		value, ok := c.pinnedValues[key]
+		if !ok {
+			continue
+		}
+		delete(c.pinnedValues, key)
+		errs.Add(c.put(key, value))
 	}
+	return errs.Err
 }
@@ -16,7 +16,7 @@ func TestNewOnEvictCache(t *testing.T) {
 	require := require.New(t)
 
 	called := false
-	onEviction := func(int) error {
+	onEviction := func(int, int) error {
 		called = true
 		return nil
 	}
@@ -28,7 +28,7 @@ func TestNewOnEvictCache(t *testing.T) {
 	require.Zero(cache.fifo.Len())
 	// Can't test function equality directly so do this
 	// to make sure it was assigned correctly
-	require.NoError(cache.onEviction(0))
+	require.NoError(cache.onEviction(0, 0))
 	require.True(called)
 }
 
@@ -39,7 +39,7 @@ func TestOnEvictCacheNoOnEvictionError(t *testing.T) {
 	require := require.New(t)
 
 	evicted := []int{}
-	onEviction := func(n int) error {
+	onEviction := func(_, n int) error {
 		evicted = append(evicted, n)
 		return nil
 	}
@@ -156,7 +156,7 @@ func TestOnEvictCacheOnEvictionError(t *testing.T) {
 	var (
 		require    = require.New(t)
 		evicted    = []int{}
-		onEviction = func(n int) error {
+		onEviction = func(_, n int) error {
 			// Evicting even keys errors
 			evicted = append(evicted, n)
 			if n%2 == 0 {
@@ -206,3 +206,47 @@ func TestOnEvictCacheOnEvictionError(t *testing.T) {
 	_, ok = cache.Get(2)
 	require.False(ok)
 }
+
+func TestOnEvictCachePin(t *testing.T) {
+	require := require.New(t)
+
+	evicted := []int{}
+	onEviction := func(_, n int) error {
+		evicted = append(evicted, n)
+		return nil
+	}
+	cache := newOnEvictCache[int](2, onEviction)
+	cache.maxPinned = 2
+
+	require.NoError(cache.Put(0, 0))
+	require.NoError(cache.Pin([]int{0, 1}))
+
+	// Pinned keys don't count towards the cache size and aren't evicted.
+	require.NoError(cache.Put(1, 1))
+	for i := 2; i < 6; i++ {
+		require.NoError(cache.Put(i, i))
+	}
+	require.Equal([]int{2, 3}, evicted)
+	for i := 0; i < 2; i++ {
+		val, ok := cache.Get(i)
+		require.True(ok)
+		require.Equal(i, val)
+	}
+
+	err := cache.Pin([]int{1, 2})
+	require.ErrorIs(err, ErrTooManyPinnedKeys)
+	require.False(cache.pinned.Contains(2))
+
+	// Unpinned elements become the most recently used.
+	require.NoError(cache.Unpin([]int{0}))
+	require.Equal([]int{2, 3, 4}, evicted)
+	_, ok := cache.Get(0)
+	require.True(ok)
+
+	// Flushing evicts the pinned elements, but the keys stay pinned.
+	require.NoError(cache.Flush())
+	require.ElementsMatch([]int{2, 3, 4, 1, 0, 5}, evicted)
+	_, ok = cache.Get(1)
+	require.False(ok)
+	require.True(cache.pinned.Contains(1))
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+// The name of the hash function used to calculate node IDs and value digests.
+const HasherSHA256 = "sha256"
+
+// Capabilities describes the optional features supported by a database so that
+// consumers don't need to duplicate the [Config] it was created with.
+type Capabilities struct {
+	// The maximum number of children a node may have.
+	BranchFactor int
+	// The name of the hash function used to calculate node IDs.
+	Hasher string
+	// True if the changes of recent commits are retained so that historical
+	// views and reads of past states are possible.
+	// See [Config.HistoryLength].
+	HistoryEnabled bool
+	// True if proofs of past roots and change proofs between them can be
+	// served, as is required to serve syncing peers. This requires history,
+	// since the root may change while a peer is syncing.
+	ServesProofs bool
+	// True if large values are stored separately from the trie nodes.
+	// See [Config.SeparateValueStore].
+	SeparateValueStore bool
+}
+
+func (db *merkleDB) Capabilities() Capabilities {
+	historyEnabled := db.history.maxHistoryLen > 0
+	return Capabilities{
+		BranchFactor:       NodeBranchFactor,
+		Hasher:             HasherSHA256,
+		HistoryEnabled:     historyEnabled,
+		ServesProofs:       historyEnabled,
+		SeparateValueStore: db.valueDB != nil,
+	}
+}
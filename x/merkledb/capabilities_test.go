@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		name               string
+		historyLength      int
+		separateValueStore bool
+		expected           Capabilities
+	}{
+		{
+			name:          "default",
+			historyLength: defaultHistoryLength,
+			expected: Capabilities{
+				BranchFactor:   NodeBranchFactor,
+				Hasher:         HasherSHA256,
+				HistoryEnabled: true,
+				ServesProofs:   true,
+			},
+		},
+		{
+			name:               "separate value store",
+			historyLength:      defaultHistoryLength,
+			separateValueStore: true,
+			expected: Capabilities{
+				BranchFactor:       NodeBranchFactor,
+				Hasher:             HasherSHA256,
+				HistoryEnabled:     true,
+				ServesProofs:       true,
+				SeparateValueStore: true,
+			},
+		},
+		{
+			name:          "no history",
+			historyLength: 0,
+			expected: Capabilities{
+				BranchFactor: NodeBranchFactor,
+				Hasher:       HasherSHA256,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			config := newDefaultConfig()
+			config.HistoryLength = test.historyLength
+			config.SeparateValueStore = test.separateValueStore
+			db, err := New(context.Background(), memdb.New(), config)
+			require.NoError(err)
+			require.Equal(test.expected, db.Capabilities())
+		})
+	}
+}
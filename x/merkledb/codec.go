@@ -12,6 +12,8 @@ import (
 	"math"
 	"sync"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 )
@@ -33,6 +35,10 @@ const (
 	estimatedNodeChildLen = minVarIntLen + estimatedCompressedPathLen + ids.IDLen
 	// Child index, child ID
 	hashValuesChildLen = minVarIntLen + ids.IDLen
+
+	// Prefixes the output of [codecImpl.encodeCanonicalRangeProof]. Must be
+	// changed if that encoding ever changes.
+	canonicalRangeProofVersion = 0
 )
 
 var (
@@ -51,6 +57,10 @@ var (
 	errNonZeroNibblePadding = errors.New("nibbles should be padded with 0s")
 	errExtraSpace           = errors.New("trailing buffer space")
 	errNegativeSliceLength  = errors.New("negative slice length")
+	errNegativeNumNodes     = errors.New("number of proof nodes is negative")
+	errNegativeNumKeys      = errors.New("number of key-values is negative")
+	errInvalidSharedPrefix  = errors.New("shared prefix is longer than the previous key")
+	errNonCanonicalPrefix   = errors.New("shared prefix isn't the longest common prefix")
 )
 
 // encoderDecoder defines the interface needed by merkleDB to marshal
@@ -65,11 +75,20 @@ type encoder interface {
 	encodeDBNode(n *dbNode) []byte
 	// Assumes [hv] is non-nil.
 	encodeHashValues(hv *hashValues) []byte
+	// Assumes [proof] is non-nil.
+	encodeCompactRangeProof(proof *RangeProof) []byte
+	// Assumes [proof] is non-nil.
+	encodeCanonicalRangeProof(proof *RangeProof) []byte
 }
 
 type decoder interface {
 	// Assumes [n] is non-nil.
 	decodeDBNode(bytes []byte, n *dbNode) error
+	// Returns whether the node encoded in [bytes] has a value without
+	// decoding the rest of the node.
+	decodeDBNodeHasValue(bytes []byte) (bool, error)
+	// Assumes [proof] is non-nil.
+	decodeCompactRangeProof(bytes []byte, proof *RangeProof) error
 }
 
 func newCodec() encoderDecoder {
@@ -134,6 +153,13 @@ func (c *codecImpl) encodeHashValues(hv *hashValues) []byte {
 	return buf.Bytes()
 }
 
+func (c *codecImpl) decodeDBNodeHasValue(b []byte) (bool, error) {
+	if minDBNodeLen > len(b) {
+		return false, io.ErrUnexpectedEOF
+	}
+	return c.decodeBool(bytes.NewReader(b))
+}
+
 func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
 	if minDBNodeLen > len(b) {
 		return io.ErrUnexpectedEOF
@@ -190,6 +216,232 @@ func (c *codecImpl) decodeDBNode(b []byte, n *dbNode) error {
 	return nil
 }
 
+// encodeCompactRangeProof encodes [proof] such that the prefix each key shares
+// with the previous key in the same list is written as its length rather than
+// repeated.
+func (c *codecImpl) encodeCompactRangeProof(proof *RangeProof) []byte {
+	buf := &bytes.Buffer{}
+	c.encodeCompactProofPath(buf, proof.StartProof)
+	c.encodeCompactProofPath(buf, proof.EndProof)
+
+	c.encodeInt(buf, len(proof.KeyValues))
+	var previousKey []byte
+	for _, kv := range proof.KeyValues {
+		shared := getLengthOfCommonPrefix(path(previousKey), path(kv.Key))
+		c.encodeInt(buf, shared)
+		c.encodeByteSlice(buf, kv.Key[shared:])
+		c.encodeByteSlice(buf, kv.Value)
+		previousKey = kv.Key
+	}
+	return buf.Bytes()
+}
+
+// encodeCanonicalRangeProof encodes [proof] such that range proofs with the
+// same proof nodes and key/value pairs always have the same encoding. Proof
+// nodes are written in increasing order of their key and key/value pairs in
+// increasing order of their key, regardless of their order in [proof].
+func (c *codecImpl) encodeCanonicalRangeProof(proof *RangeProof) []byte {
+	buf := &bytes.Buffer{}
+	_ = buf.WriteByte(canonicalRangeProofVersion)
+	c.encodeCanonicalProofPath(buf, proof.StartProof)
+	c.encodeCanonicalProofPath(buf, proof.EndProof)
+
+	keyValues := slices.Clone(proof.KeyValues)
+	slices.SortStableFunc(keyValues, func(a, b KeyValue) bool {
+		return bytes.Compare(a.Key, b.Key) < 0
+	})
+	c.encodeInt(buf, len(keyValues))
+	for _, kv := range keyValues {
+		c.encodeByteSlice(buf, kv.Key)
+		c.encodeByteSlice(buf, kv.Value)
+	}
+	return buf.Bytes()
+}
+
+func (c *codecImpl) encodeCanonicalProofPath(dst *bytes.Buffer, proofPath []ProofNode) {
+	proofPath = slices.Clone(proofPath)
+	slices.SortStableFunc(proofPath, func(a, b ProofNode) bool {
+		return a.KeyPath.deserialize().Less(b.KeyPath.deserialize())
+	})
+
+	c.encodeInt(dst, len(proofPath))
+	for _, proofNode := range proofPath {
+		c.encodeSerializedPath(dst, proofNode.KeyPath)
+		c.encodeMaybeByteSlice(dst, proofNode.ValueOrHash)
+
+		c.encodeInt(dst, len(proofNode.Children))
+		for index := byte(0); index < NodeBranchFactor; index++ {
+			if childID, ok := proofNode.Children[index]; ok {
+				c.encodeInt(dst, int(index))
+				_, _ = dst.Write(childID[:])
+			}
+		}
+	}
+}
+
+func (c *codecImpl) encodeCompactProofPath(dst *bytes.Buffer, proofPath []ProofNode) {
+	c.encodeInt(dst, len(proofPath))
+	var previousKey path
+	for _, proofNode := range proofPath {
+		key := proofNode.KeyPath.deserialize()
+		shared := getLengthOfCommonPrefix(previousKey, key)
+		c.encodeInt(dst, shared)
+		c.encodeSerializedPath(dst, key[shared:].Serialize())
+		c.encodeMaybeByteSlice(dst, proofNode.ValueOrHash)
+
+		c.encodeInt(dst, len(proofNode.Children))
+		for index := byte(0); index < NodeBranchFactor; index++ {
+			if childID, ok := proofNode.Children[index]; ok {
+				c.encodeInt(dst, int(index))
+				_, _ = dst.Write(childID[:])
+			}
+		}
+		previousKey = key
+	}
+}
+
+func (c *codecImpl) decodeCompactRangeProof(b []byte, proof *RangeProof) error {
+	src := bytes.NewReader(b)
+
+	var err error
+	if proof.StartProof, err = c.decodeCompactProofPath(src); err != nil {
+		return err
+	}
+	if proof.EndProof, err = c.decodeCompactProofPath(src); err != nil {
+		return err
+	}
+
+	numKeyValues, err := c.decodeInt(src)
+	switch {
+	case err != nil:
+		return err
+	case numKeyValues < 0:
+		return errNegativeNumKeys
+	case numKeyValues > src.Len()/(minVarIntLen+2*minByteSliceLen):
+		return io.ErrUnexpectedEOF
+	}
+
+	proof.KeyValues = make([]KeyValue, numKeyValues)
+	var previousKey []byte
+	for i := range proof.KeyValues {
+		shared, err := c.decodeSharedPrefixLen(src, len(previousKey))
+		if err != nil {
+			return err
+		}
+		suffix, err := c.decodeByteSlice(src)
+		if err != nil {
+			return err
+		}
+		if shared < len(previousKey) && len(suffix) > 0 && suffix[0] == previousKey[shared] {
+			return errNonCanonicalPrefix
+		}
+		value, err := c.decodeByteSlice(src)
+		if err != nil {
+			return err
+		}
+
+		key := make([]byte, shared+len(suffix))
+		copy(key, previousKey[:shared])
+		copy(key[shared:], suffix)
+		proof.KeyValues[i] = KeyValue{
+			Key:   key,
+			Value: value,
+		}
+		previousKey = key
+	}
+	if src.Len() != 0 {
+		return errExtraSpace
+	}
+	return nil
+}
+
+func (c *codecImpl) decodeCompactProofPath(src *bytes.Reader) ([]ProofNode, error) {
+	numNodes, err := c.decodeInt(src)
+	switch {
+	case err != nil:
+		return nil, err
+	case numNodes < 0:
+		return nil, errNegativeNumNodes
+	case numNodes > src.Len()/(minVarIntLen+minSerializedPathLen+minMaybeByteSliceLen+minVarIntLen):
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	proofPath := make([]ProofNode, numNodes)
+	var previousKey path
+	for i := range proofPath {
+		shared, err := c.decodeSharedPrefixLen(src, len(previousKey))
+		if err != nil {
+			return nil, err
+		}
+		serializedSuffix, err := c.decodeSerializedPath(src)
+		if err != nil {
+			return nil, err
+		}
+		suffix := serializedSuffix.deserialize()
+		if shared < len(previousKey) && len(suffix) > 0 && suffix[0] == previousKey[shared] {
+			return nil, errNonCanonicalPrefix
+		}
+		key := previousKey[:shared] + suffix
+
+		valueOrHash, err := c.decodeMaybeByteSlice(src)
+		if err != nil {
+			return nil, err
+		}
+
+		numChildren, err := c.decodeInt(src)
+		switch {
+		case err != nil:
+			return nil, err
+		case numChildren < 0:
+			return nil, errNegativeNumChildren
+		case numChildren > NodeBranchFactor:
+			return nil, errTooManyChildren
+		case numChildren > src.Len()/hashValuesChildLen:
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		children := make(map[byte]ids.ID, numChildren)
+		previousChild := -1
+		for j := 0; j < numChildren; j++ {
+			index, err := c.decodeInt(src)
+			if err != nil {
+				return nil, err
+			}
+			if index <= previousChild || index >= NodeBranchFactor {
+				return nil, errChildIndexTooLarge
+			}
+			previousChild = index
+
+			childID, err := c.decodeID(src)
+			if err != nil {
+				return nil, err
+			}
+			children[byte(index)] = childID
+		}
+
+		proofPath[i] = ProofNode{
+			KeyPath:     key.Serialize(),
+			ValueOrHash: valueOrHash,
+			Children:    children,
+		}
+		previousKey = key
+	}
+	return proofPath, nil
+}
+
+// decodeSharedPrefixLen decodes the length of the prefix a key shares with the
+// previous key, which has length [previousKeyLen].
+func (c *codecImpl) decodeSharedPrefixLen(src *bytes.Reader, previousKeyLen int) (int, error) {
+	shared, err := c.decodeInt(src)
+	switch {
+	case err != nil:
+		return 0, err
+	case shared < 0 || shared > previousKeyLen:
+		return 0, errInvalidSharedPrefix
+	}
+	return shared, nil
+}
+
 func (*codecImpl) encodeBool(dst *bytes.Buffer, value bool) {
 	bytesValue := falseBytes
 	if value {
@@ -217,6 +217,27 @@ func FuzzCodecDBNodeCanonical(f *testing.F) {
 	)
 }
 
+func FuzzCodecCompactRangeProofCanonical(f *testing.F) {
+	f.Fuzz(
+		func(
+			t *testing.T,
+			b []byte,
+		) {
+			require := require.New(t)
+
+			codec := codec.(*codecImpl)
+			proof := &RangeProof{}
+			if err := codec.decodeCompactRangeProof(b, proof); err != nil {
+				return
+			}
+
+			// Encoding [proof] should be the same as [b].
+			buf := codec.encodeCompactRangeProof(proof)
+			require.Equal(b, buf)
+		},
+	)
+}
+
 func FuzzCodecDBNodeDeterministic(f *testing.F) {
 	f.Fuzz(
 		func(
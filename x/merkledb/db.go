@@ -6,9 +6,14 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math/rand"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 
@@ -21,20 +26,62 @@ import (
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/database/versiondb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/math"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 )
 
 const (
 	DefaultEvictionBatchSize = 100
-	RootPath                 = EmptyPath
+	// See [ReadRetryConfig].
+	DefaultReadRetryMaxBackoff = 100 * time.Millisecond
+	DefaultReadRetryMaxWait    = time.Second
+	RootPath                   = EmptyPath
 	// TODO: name better
 	rebuildViewSizeFractionOfCacheSize = 50
 	minRebuildViewSizePerCommit        = 1000
+	// At most 1/[maxPinnedFractionOfCacheSize] of [Config.NodeCacheSize]
+	// nodes can be pinned so that pinning can't defeat eviction.
+	maxPinnedFractionOfCacheSize = 4
+
+	// The number of times a range proof is generated, or a set of values is
+	// read, without blocking commits before commits are blocked to guarantee
+	// progress.
+	maxLockFreeReadAttempts = 3
+
+	// The number of random walks from the root EstimateRangeSize takes.
+	// Each walk descends from the root, at each node choosing uniformly
+	// among the children whose sub-tries may contain keys in the range, and
+	// weighs the size of each node in the range by the product of the number
+	// of choices made to reach it. Each walk is an unbiased estimate of the
+	// size of the range (Knuth's estimator) and the result is their average.
+	// Its relative standard error shrinks with the square root of the number
+	// of walks and grows with how unevenly keys are spread across the range.
+	// For keys that are spread roughly evenly, such as hashes, the estimate
+	// is typically within 10% of the exact size. For very skewed key
+	// distributions it may be off by a multiple.
+	estimateRangeSizeWalks = 128
+
+	// The number of eviction batches that can be waiting to be passed to
+	// [Config.OnEvict] before further batches are dropped.
+	onEvictQueueSize = 1024
+
+	// Stages of a commit passed to [Config.commitInterceptor].
+	// commitStageWriteBatch is before the changed nodes are written to disk.
+	// commitStageUpdateMemory is after the changed nodes are written to disk
+	// but before the in-memory state is updated.
+	commitStageWriteBatch   = "writeBatch"
+	commitStageUpdateMemory = "updateMemory"
+
+	// The number of node changes written between progress reports of a
+	// commit.
+	commitProgressInterval = 256
 )
 
 var (
@@ -46,11 +93,28 @@ var (
 	rootKey                 []byte
 	nodePrefix              = []byte("node")
 	metadataPrefix          = []byte("metadata")
+	valuePrefix             = []byte("value")
+	valueRefPrefix          = []byte("valueRef")
 	cleanShutdownKey        = []byte("cleanShutdown")
+	valueStoreLayoutKey     = []byte("valueStoreLayout")
+	inlineValuesLayout      = []byte{0}
+	separateValuesLayout    = []byte{1}
 	hadCleanShutdown        = []byte{1}
 	didNotHaveCleanShutdown = []byte{0}
 
-	errSameRoot = errors.New("start and end root are the same")
+	errSameRoot         = errors.New("start and end root are the same")
+	errRootMismatch     = errors.New("incrementally calculated root doesn't match the recalculated root")
+	errSampleOutOfRange = errors.New("sampled key index is out of range")
+	errNodeIDMismatch   = errors.New("stored node ID doesn't match the recalculated node ID")
+	errDanglingChild    = errors.New("child node not found")
+	errFlushFailed      = errors.New("periodic flush failed")
+	errNegativeRefCount = errors.New("value reference count is negative")
+
+	ErrNotEmpty           = errors.New("database isn't empty")
+	ErrUnsortedKeys       = errors.New("keys aren't in strictly increasing order")
+	ErrBatchingCommits    = errors.New("commits are already being batched")
+	ErrNotBatchingCommits = errors.New("commits aren't being batched")
+	ErrValueStoreMismatch = errors.New("value store layout doesn't match the database")
 )
 
 type ChangeProofer interface {
@@ -115,14 +179,315 @@ type MerkleDB interface {
 	ProofGetter
 	ChangeProofer
 	RangeProofer
+
+	// GetUnsafe returns the value associated with [key] without copying it.
+	// Unlike Get, which returns a copy the caller owns, the returned slice is
+	// the value stored in the trie. It must not be modified, and it's only
+	// valid until the next commit. If the node of [key] is cached, no memory
+	// is allocated.
+	// Returns [database.ErrNotFound] if [key] isn't in the database.
+	GetUnsafe(key []byte) ([]byte, error)
+
+	// ExtractRange writes the key/value pairs in [start, end) into a new
+	// merkle database backed by [dst]. The root of the new database is the
+	// root of a trie containing only those key/value pairs.
+	// If [start] is Nothing, there's no lower bound on the range.
+	// If [end] is Nothing, there's no upper bound on the range.
+	ExtractRange(ctx context.Context, start, end maybe.Maybe[[]byte], dst database.Database) error
+
+	// Len returns the number of keys in the database.
+	// The first call iterates over every key. Subsequent calls are O(1).
+	Len() (int, error)
+
+	// CachedRoot returns the root of the last change committed to the
+	// database without taking the database lock. It doesn't reflect the
+	// changes of views that haven't been committed, and may be one commit
+	// behind if a commit is in progress.
+	CachedRoot() ids.ID
+
+	// GetAbsenceProof returns a proof that no keys in the database are
+	// strictly between [a] and [b]. [a] and [b] may be in the database.
+	// The proof should be verified with [RangeProof.VerifyAbsence].
+	// Returns [ErrStartAfterEnd] if [a] >= [b].
+	GetAbsenceProof(ctx context.Context, a, b []byte) (*RangeProof, error)
+
+	// NewIteratorAtRoot returns an iterator over the key/value pairs of the
+	// trie as it was when it had root [rootID], starting at [start] and
+	// restricted to keys with [prefix].
+	// The returned iterator isn't affected by subsequent commits.
+	// Returns [ErrRootNotInHistory] if [rootID] isn't in the history.
+	NewIteratorAtRoot(rootID ids.ID, start, prefix []byte) (database.Iterator, error)
+
+	// ChangedKeysBetween returns the keys whose values differ between the
+	// trie with root [startRootID] and the trie with root [endRootID], sorted
+	// in increasing order. Unlike [GetChangeProof], the result isn't
+	// verifiable.
+	// Returns [ErrRootNotInHistory] if either root isn't in the history.
+	ChangedKeysBetween(startRootID, endRootID ids.ID) ([][]byte, error)
+
+	// CountPrefix returns the number of keys in the database that start with
+	// [prefix]. Only the keys with [prefix] are read.
+	CountPrefix(ctx context.Context, prefix []byte) (int, error)
+
+	// Update calls [fn] with a transaction and atomically commits the
+	// transaction's changes if [fn] returns nil. If [fn] returns an error,
+	// the changes are discarded and the error is returned.
+	// No other changes are committed to the database while [fn] runs, so
+	// [fn] must not write to the database except through the transaction.
+	Update(ctx context.Context, fn func(tx Txn) error) error
+
+	// Dump writes a human readable representation of every node in the trie
+	// to [w]. It's intended for debugging.
+	Dump(w io.Writer) error
+
+	// DumpN is like [Dump] but writes at most [maxNodes] nodes.
+	DumpN(w io.Writer, maxNodes int) error
+
+	// GetRangeProofWithBounds returns a proof of up to [maxLength] key-value
+	// pairs with keys in [bounds]. If [bounds.End] is excluded and is the
+	// first key at or after the start of [bounds], the proof contains only
+	// [bounds.End] to prove that there are no keys in [bounds].
+	// The proof should be verified with [RangeProof.VerifyWithBounds].
+	GetRangeProofWithBounds(ctx context.Context, bounds Bounds, maxLength int) (*RangeProof, error)
+
+	// GetMultiRangeProof returns a proof of the key-value pairs in each of
+	// [ranges], which must be sorted and disjoint, in the current trie. At
+	// most [maxTotalKeys] key-value pairs are included across all ranges.
+	// Ranges are proven in order, so once the limit is reached the remaining
+	// ranges are omitted from the proof.
+	// The proof should be verified with [MultiRangeProof.Verify].
+	// Returns [ErrRangesNotDisjoint] if [ranges] overlap or aren't sorted.
+	GetMultiRangeProof(ctx context.Context, ranges []Bounds, maxTotalKeys int) (*MultiRangeProof, error)
+
+	// PrefetchRange loads the nodes of the trie that may contain keys in
+	// [start, end] into the node cache so that subsequent reads of the range
+	// don't need to read from disk. At most as many nodes as fit in the cache
+	// are loaded. Commits are blocked while the nodes are loaded.
+	// If [start] is Nothing, there's no lower bound on the range.
+	// If [end] is Nothing, there's no upper bound on the range.
+	PrefetchRange(ctx context.Context, start, end maybe.Maybe[[]byte]) error
+
+	// EstimateRangeSize returns an estimate of the number of bytes the nodes
+	// with keys in [start, end] occupy on disk, without reading every node in
+	// the range. See [estimateRangeSizeWalks] for how the estimate is made and
+	// how accurate it is. Commits are blocked while the estimate is made.
+	// If [start] is Nothing, there's no lower bound on the range.
+	// If [end] is Nothing, there's no upper bound on the range.
+	EstimateRangeSize(ctx context.Context, start, end maybe.Maybe[[]byte]) (uint64, error)
+
+	// NewKeyIterator returns an iterator over the keys in the database,
+	// starting at [start] and restricted to keys with [prefix]. The iterator's
+	// Value is always nil, and values aren't decoded, so it's cheaper than
+	// [NewIteratorWithStartAndPrefix] when only the keys are needed.
+	NewKeyIterator(start, prefix []byte) database.Iterator
+
+	// SampleProof returns a proof of [sampleSize] consecutive keys selected
+	// by [seed], along with the selected keys. The selection is deterministic
+	// given [seed] and the current root, so auditors can request the same
+	// sample from multiple replicas and compare the results. If there are no
+	// more than [sampleSize] keys, every key is selected.
+	// The proof should be verified with [RangeProof.Verify] with the first
+	// selected key as the start and no end.
+	SampleProof(ctx context.Context, seed uint64, sampleSize int) (*RangeProof, [][]byte, error)
+
+	// VerifyIntegrity reads every node of the trie and returns an error if
+	// a node's ID doesn't match the ID recalculated from its descendants, if
+	// a child node is missing, or if the root recalculated from every
+	// key/value pair doesn't match the current root.
+	// This is much more expensive than [HealthCheck] and is intended to be
+	// used after suspected corruption. Commits are blocked while it runs.
+	VerifyIntegrity(ctx context.Context) error
+
+	// GetOrDefault returns a copy of the value associated with [key], or
+	// [def] if [key] isn't in the database. [def] is also returned if the
+	// value couldn't be read, such as when the database is closed.
+	GetOrDefault(key, def []byte) []byte
+
+	// CommitSeq returns the sequence number of the current state of the
+	// database. It's 0 when the database is opened and is incremented by
+	// each non-empty commit.
+	CommitSeq() uint64
+
+	// GetAtSeq returns a copy of the value associated with [key] as of the
+	// state with sequence number [seq], as returned by [CommitSeq].
+	// Returns [ErrSeqNotRetained] if the state is no longer in the history.
+	GetAtSeq(seq uint64, key []byte) ([]byte, error)
+
+	// KeysChangedSince returns the keys changed by commits made after [t], in
+	// order. Keys set to their existing value aren't included.
+	// Returns [ErrInsufficientHistory] if [t] is before the oldest commit in
+	// the history.
+	KeysChangedSince(t time.Time) ([][]byte, error)
+
+	// GetValuesConsistent returns copies of the values associated with
+	// [keys], all read from the same root. Unlike [GetValues], commits aren't
+	// blocked while the keys are read unless commits repeatedly happen
+	// during the reads.
+	GetValuesConsistent(ctx context.Context, keys [][]byte) ([][]byte, []error)
+
+	// GetMap returns a map from each of [keys] that is in the database to a
+	// copy of its value. Keys that aren't in the database are omitted.
+	// Commits are blocked while the keys are read.
+	GetMap(ctx context.Context, keys [][]byte) (map[string][]byte, error)
+
+	// GetRawNode returns the bytes of the node with key [key] as stored on
+	// disk. Nodes that haven't been written to disk yet are encoded as they
+	// will be written. If [Config.SeparateValueStore] is true, values that
+	// are stored separately are replaced by their hash.
+	// Returns [database.ErrNotFound] if there's no node with key [key].
+	GetRawNode(key []byte) ([]byte, error)
+
+	// CommitRangeProofVerified is like CommitRangeProof, but first verifies
+	// that [proof] proves its key/value pairs, and that there are no other
+	// keys between [start] and its largest key, in the trie with root
+	// [expectedRoot]. If it doesn't, the database isn't modified and
+	// [ErrProofVerificationFailed] is returned.
+	// Unlike [RangeProof.Verify], the subtries under the proof nodes that are
+	// entirely in the proven range are verified one at a time, in key order,
+	// and verification stops at the first invalid one. The error then wraps
+	// [ErrInvalidSubtrie] with the key of that subtrie.
+	CommitRangeProofVerified(ctx context.Context, start maybe.Maybe[[]byte], proof *RangeProof, expectedRoot ids.ID) error
+
+	// Flush writes the changes of all commits to disk. This is a no-op
+	// unless [Config.FlushPolicy] is [WriteBack].
+	Flush() error
+
+	// BeginBatchedCommits causes the node writes of subsequent commits to be
+	// buffered in memory until [EndBatchedCommits] is called, so that they're
+	// written to disk together. Each commit still updates the root, and reads
+	// reflect the commits made since [BeginBatchedCommits] was called.
+	// Commits made since [BeginBatchedCommits] was called are lost if the
+	// process crashes before [EndBatchedCommits] is called.
+	// Returns [ErrBatchingCommits] if commits are already being batched.
+	BeginBatchedCommits() error
+
+	// EndBatchedCommits writes the changes of the commits made since
+	// [BeginBatchedCommits] was called to disk in a single batch, or one
+	// batch per store if [Config.SeparateValueStore] is true, and stops
+	// batching commits. If [Config.FlushPolicy] is [WriteBack], the changes
+	// are still buffered until they're flushed.
+	// Returns [ErrNotBatchingCommits] if commits aren't being batched.
+	EndBatchedCommits() error
+
+	// BulkLoadSorted inserts the key/value pairs of [it] into the database,
+	// which must be empty, as a single commit. Since the keys are sorted, the
+	// trie is built bottom-up in one pass, which is much faster than
+	// inserting the same pairs with [Put] or a view. The resulting root is
+	// the same as if they had been inserted that way.
+	// Like other commits, the root is verified before anything is written if
+	// [Config.VerifyOnCommit] is true.
+	// Returns [ErrNotEmpty] if the database isn't empty and [ErrUnsortedKeys]
+	// if the keys of [it] aren't strictly increasing. On error, the database
+	// isn't modified.
+	BulkLoadSorted(ctx context.Context, it database.Iterator) error
+
+	// LastCommitStats returns the number of keys changed by the last commit
+	// and the number of nodes it wrote to disk. Every node on the path from
+	// the root to a changed key is rewritten, so [nodesWritten] is usually
+	// larger than [changedKeys]. Nodes without a value may be written after
+	// the commit returns, when they're evicted from the cache.
+	// Both are 0 if there haven't been any commits since the database was
+	// opened.
+	LastCommitStats() (changedKeys, nodesWritten int)
+
+	// Pin keeps the nodes of [keys] in the node cache until they're
+	// unpinned, regardless of how many other nodes are read. Pinned nodes
+	// don't count towards [Config.NodeCacheSize]. Keys that aren't in the
+	// database can be pinned, and their nodes are kept once they're added.
+	// Returns [ErrTooManyPinnedKeys] without pinning any of [keys] if more
+	// than a quarter of [Config.NodeCacheSize] keys would be pinned.
+	Pin(keys [][]byte) error
+
+	// Unpin allows the nodes of [keys] to be evicted from the node cache.
+	// Keys that aren't pinned are ignored.
+	Unpin(keys [][]byte)
+
+	// Snapshot returns a snapshot of the current state of the database. Its
+	// reads aren't affected by subsequent commits until it expires from the
+	// history. See [Snapshot].
+	Snapshot() (*Snapshot, error)
+
+	// Capabilities returns the optional features supported by the database.
+	Capabilities() Capabilities
+
+	// StartScrubber starts walking the trie in the background, verifying
+	// the ID of at most [ratePerSec] nodes per second, including the root, so
+	// that corruption is found before it's read. Nodes are read from disk
+	// without filling the node cache, except for cached intermediate nodes,
+	// whose copies on disk may be outdated. Each corrupt or missing node is
+	// reported to [Config.OnCorruption]. Once the whole trie has been walked,
+	// the walk starts over. The scrubber runs until [ctx] is cancelled,
+	// [StopScrubber] is called or the database is closed.
+	// Returns [ErrScrubberRunning] if the scrubber is already running.
+	StartScrubber(ctx context.Context, ratePerSec int) error
+
+	// StopScrubber stops the scrubber, if it's running, and waits for it to
+	// exit.
+	StopScrubber()
+
+	// GetDiffProof returns a proof of up to [maxKeys] of the key changes
+	// with keys >= [start] that occurred between [fromRootID] and
+	// [toRootID], so that the changes can be sent to a peer in bounded
+	// chunks. If [start] is Nothing, there's no lower bound on the keys.
+	// If the proof doesn't contain every remaining change, [next] is the
+	// smallest key after the keys covered by the proof, which should be
+	// passed as [start] to get the next chunk. Otherwise, [next] is Nothing.
+	// The proof should be verified with [start] and no end bound.
+	// Returns [ErrInsufficientHistory] if either root isn't in the history.
+	GetDiffProof(
+		ctx context.Context,
+		fromRootID ids.ID,
+		toRootID ids.ID,
+		start maybe.Maybe[[]byte],
+		maxKeys int,
+	) (proof *ChangeProof, next maybe.Maybe[[]byte], err error)
+
+	// EstimateRangeProofCost returns the number of proof nodes and the
+	// approximate serialized size, in bytes, of the range proof that
+	// GetRangeProof would return for the same arguments, without building
+	// the proof. The node count is exact.
+	EstimateRangeProofCost(
+		ctx context.Context,
+		start maybe.Maybe[[]byte],
+		end maybe.Maybe[[]byte],
+		maxKeys int,
+	) (nodes int, bytes int, err error)
 }
 
+// FlushPolicy determines when the changes of a commit are written to disk.
+type FlushPolicy int
+
+const (
+	// WriteThrough writes the changes of a commit to disk before the commit
+	// returns.
+	WriteThrough FlushPolicy = iota
+	// WriteBack keeps the changes of commits in memory until they're flushed
+	// by [MerkleDB.Flush], periodically, or on close. This makes bursts of
+	// commits faster, but commits since the last flush are lost if the
+	// process crashes.
+	WriteBack
+)
+
 type Config struct {
 	// The number of nodes that are evicted from the cache and written to
 	// disk at a time.
+	// If [AdaptiveEviction] is true, this is the initial number of nodes.
 	EvictionBatchSize int
+	// If true, the number of nodes written to disk at a time is tuned between
+	// [MinEvictionBatchSize] and [MaxEvictionBatchSize] based on how long
+	// previous writes took. Writes that are faster than
+	// [TargetEvictionLatency] grow the batch size and writes that are slower
+	// shrink it.
+	AdaptiveEviction      bool
+	MinEvictionBatchSize  int
+	MaxEvictionBatchSize  int
+	TargetEvictionLatency time.Duration
 	// The number of changes to the database that we store in memory in order to
 	// serve change proofs.
+	// Deleted keys are retained for as long as the change that deleted them is
+	// in the history, regardless of whether the deleted nodes are still cached
+	// or stored. Change proofs spanning changes that are no longer in the
+	// history fail with [ErrInsufficientHistory].
 	HistoryLength int
 	NodeCacheSize int
 	// If [Reg] is nil, metrics are collected locally but not exported through
@@ -130,11 +495,104 @@ type Config struct {
 	// This may be useful for testing.
 	Reg    prometheus.Registerer
 	Tracer trace.Tracer
+	// The maximum length of a value. Views containing a longer value can't be
+	// created, so writes of a longer value are rejected with
+	// [ErrValueTooLarge]. If 0, values aren't limited.
+	// Values committed from proofs, and values already in the database, aren't
+	// limited.
+	MaxValueLen int
+	// If non-nil, called with each key that is put into a view or written to
+	// the database before it enters the trie. If it returns an error, the
+	// view isn't created, or the write or batch is rejected as a whole, and
+	// the error is returned. Deleted keys aren't validated, nor are keys
+	// committed from proofs or already in the database.
+	KeyValidator func(key []byte) error
+	// The maximum number of keys a view may change to be committed. Commits
+	// of views that change more keys fail with [ErrCommitTooLarge] without
+	// modifying the database. This guards against buggy callers rewriting
+	// the entire trie. If 0, commits aren't limited.
+	MaxChangedKeysPerCommit int
+	// The maximum number of goroutines used to calculate the node IDs of a
+	// view, such as when the root of a view created by NewView is calculated.
+	// The IDs of independent subtries are calculated concurrently, so the
+	// resulting root doesn't depend on this value. Inserting the view's
+	// changes into the trie isn't concurrent. If 0, [runtime.NumCPU] is used.
+	ViewBuildConcurrency int
+	// If true, before each commit is written the root is recalculated from
+	// every key/value pair the database will contain and compared to the
+	// incrementally calculated root. If they don't match, nothing is written.
+	// This is very expensive and should only be used to catch bugs in tests.
+	VerifyOnCommit bool
+	// Retry policy for reads of nodes from the underlying database.
+	// By default, reads aren't retried.
+	ReadRetry ReadRetryConfig
+	// If true, values that are at least [HashLength] bytes long are stored
+	// separately from the trie nodes, keyed by their hash, and the nodes only
+	// store the hash. This keeps the nodes small when values are large.
+	// Roots aren't affected since nodes are hashed with the value's hash
+	// either way.
+	// Since multiple keys may have the same value, the number of keys with
+	// each value is tracked, and a value is removed once no key has it and no
+	// node on disk references it.
+	// This must not change between instantiations of a database. Opening a
+	// database with a different value than it was created with returns
+	// [ErrValueStoreMismatch].
+	SeparateValueStore bool
+	// If non-nil, called with the key of each node that is evicted from the
+	// node cache. This may be used to keep external caches coherent.
+	// Calls are best-effort and asynchronous: they're made in eviction order
+	// on a single goroutine after a batch of nodes has been evicted, so they
+	// don't hold any of the database's locks and may be delayed. If the
+	// callback falls behind by more than [onEvictQueueSize] batches, further
+	// batches are dropped and counted by the evict_notifications_dropped
+	// metric. Calls stop once the database is closed.
+	OnEvict func(key SerializedPath)
+	// If non-nil, called by the scrubber with the key of each node that it
+	// finds to be corrupt or missing. See [MerkleDB.StartScrubber].
+	// Calls are made on the scrubber's goroutine without holding any of the
+	// database's locks.
+	OnCorruption func(key SerializedPath, err error)
+	// Determines when the changes of a commit are written to disk.
+	// Defaults to [WriteThrough].
+	FlushPolicy FlushPolicy
+	// If [FlushPolicy] is [WriteBack] and this is positive, changes are
+	// flushed to disk this often. A failed flush is retried at the next
+	// interval, and the database is reported as unhealthy until a flush
+	// succeeds.
+	FlushInterval time.Duration
+
+	// If non-nil, called at each stage of writing a commit to disk.
+	// If it returns an error, the commit is aborted at that stage.
+	// Only used in tests to simulate failures mid-commit.
+	commitInterceptor func(stage string) error
+}
+
+// ReadRetryConfig configures how reads from the underlying database are
+// retried. [database.ErrNotFound] and [database.ErrClosed] are never retried.
+// Reads are retried while the database's read lock is held, so commits are
+// blocked until the read succeeds or gives up. [MaxWait] bounds how long that
+// takes.
+type ReadRetryConfig struct {
+	// The maximum number of times a read is attempted.
+	// If <= 1, reads aren't retried.
+	MaxAttempts int
+	// How long to wait before the first retry. The wait doubles after each
+	// retry, up to [MaxBackoff].
+	InitialBackoff time.Duration
+	// The longest wait before a retry.
+	// If <= 0, [DefaultReadRetryMaxBackoff] is used.
+	MaxBackoff time.Duration
+	// The longest a read waits across all of its retries. A read that would
+	// wait longer fails with the last error, even if it has attempts left.
+	// If <= 0, [DefaultReadRetryMaxWait] is used.
+	MaxWait time.Duration
 }
 
 // merkleDB can only be edited by committing changes from a trieView.
 type merkleDB struct {
 	// Must be held when reading/writing fields.
+	// Reads only take the read lock so they can run concurrently with each
+	// other. Writing the changes of a commit takes the write lock.
 	lock sync.RWMutex
 
 	// Must be held when preparing work to be committed to the DB.
@@ -149,6 +607,24 @@ type merkleDB struct {
 	// Stores data about the database's current state.
 	metadataDB database.Database
 
+	// Stores values that are at least [HashLength] bytes long, keyed by their
+	// hash. Nil unless [Config.SeparateValueStore] is true. If nil, values are
+	// stored in the nodes.
+	valueDB database.Database
+	// Stores the number of keys that have each value in [valueDB], keyed by
+	// the value's hash. Nil iff [valueDB] is nil.
+	valueRefDB database.Database
+	// The hashes of the values in [valueDB] that no key has. They're deleted
+	// once the nodes that referenced them have been overwritten on disk and
+	// no iterator may still read them.
+	// Only accessed while [lock] is held.
+	unreferencedValues set.Set[string]
+	// The number of iterators over [nodeDB] that haven't been released.
+	// Iterators read [nodeDB] as of when they were created, so they may parse
+	// nodes that reference unreferenced values. Those values aren't deleted
+	// while this is non-zero.
+	openIterators atomic.Int64
+
 	// If a value is nil, the corresponding key isn't in the trie.
 	// Note that a call to Put may cause a node to be evicted
 	// from the cache, which will call [OnEviction].
@@ -157,6 +633,10 @@ type merkleDB struct {
 	// Stores any error returned by [onEviction].
 	onEvictionErr     utils.Atomic[error]
 	evictionBatchSize int
+	// If non-nil, overrides [evictionBatchSize].
+	// Only accessed in [onEviction], which is called with the [nodeCache]
+	// lock held.
+	adaptiveEviction *adaptiveEvictionBatchSize
 
 	// Stores change lists. Used to serve change proofs and construct
 	// historical views of the trie.
@@ -171,9 +651,82 @@ type merkleDB struct {
 
 	// The root of this trie.
 	root *node
+	// The ID of [root]. Can be read without holding [lock].
+	cachedRootID utils.Atomic[ids.ID]
+
+	// The number of keys in this trie.
+	// Only valid if [keyCountKnown] is true. Lazily initialized by [Len] and
+	// updated on each commit afterwards.
+	// Only modified while [lock] is held and commits are prevented by
+	// [commitLock].
+	keyCount      int
+	keyCountKnown bool
 
 	// Valid children of this trie.
 	childViews []*trieView
+
+	// The number of times [commitChanges] has modified the trie. Used to detect
+	// commits that happen while reading the trie without [commitLock].
+	commitCount uint64
+
+	// The number of keys and nodes changed by the last commit.
+	// See [LastCommitStats].
+	lastCommitChangedKeys  int
+	lastCommitNodesWritten int
+
+	// See [Config.MaxValueLen].
+	maxValueLen int
+
+	// See [Config.KeyValidator].
+	keyValidator func(key []byte) error
+
+	// See [Config.MaxChangedKeysPerCommit].
+	maxChangedKeysPerCommit int
+
+	// See [Config.ViewBuildConcurrency].
+	viewBuildConcurrency int
+
+	// See [Config.VerifyOnCommit].
+	verifyOnCommit bool
+
+	// See [Config.ReadRetry].
+	readRetry ReadRetryConfig
+
+	// See [Config.OnEvict].
+	onEvict func(key SerializedPath)
+	// Batches of evicted keys waiting to be passed to [onEvict] by
+	// [dispatchEvicted]. Nil iff [onEvict] is nil.
+	onEvictQueue chan []path
+	// Closed when [db] is closed to stop [dispatchEvicted].
+	stopOnEvict chan struct{}
+
+	// See [Config.OnCorruption].
+	onCorruption func(key SerializedPath, err error)
+	// Must be held when reading/writing [scrubber].
+	scrubberLock sync.Mutex
+	// Nil unless the scrubber has been started and not stopped.
+	scrubber *scrubber
+
+	// Buffers the writes to the underlying database that haven't been
+	// flushed, in the order they must be flushed.
+	// Empty unless [Config.FlushPolicy] is [WriteBack].
+	writeBackDBs []*versiondb.Database
+	// Buffer the writes of the commits made between [BeginBatchedCommits]
+	// and [EndBatchedCommits], in the order they must be written. [nodeDB]
+	// and [valueDB] always write through them, so they never change.
+	batchedCommitDBs []*bufferedDB
+	// True iff commits are being batched.
+	batchingCommits bool
+
+	// Closed when [db] is closed to stop periodic flushes.
+	// Nil unless [Config.FlushInterval] is used.
+	stopFlushing chan struct{}
+	// The error of the last periodic flush, or nil if it succeeded.
+	// Reported by [HealthCheck].
+	flushErr error
+
+	// See [Config.commitInterceptor].
+	commitInterceptor func(stage string) error
 }
 
 // New returns a new merkle database.
@@ -192,18 +745,56 @@ func newDatabase(
 	metrics merkleMetrics,
 ) (*merkleDB, error) {
 	trieDB := &merkleDB{
-		metrics:           metrics,
-		nodeDB:            prefixdb.New(nodePrefix, db),
-		metadataDB:        prefixdb.New(metadataPrefix, db),
-		history:           newTrieHistory(config.HistoryLength),
-		tracer:            config.Tracer,
-		childViews:        make([]*trieView, 0, defaultPreallocationSize),
-		evictionBatchSize: config.EvictionBatchSize,
+		metrics:                 metrics,
+		nodeDB:                  prefixdb.New(nodePrefix, db),
+		metadataDB:              prefixdb.New(metadataPrefix, db),
+		history:                 newTrieHistory(config.HistoryLength),
+		tracer:                  config.Tracer,
+		childViews:              make([]*trieView, 0, defaultPreallocationSize),
+		evictionBatchSize:       config.EvictionBatchSize,
+		maxValueLen:             config.MaxValueLen,
+		keyValidator:            config.KeyValidator,
+		maxChangedKeysPerCommit: config.MaxChangedKeysPerCommit,
+		viewBuildConcurrency:    config.ViewBuildConcurrency,
+		readRetry:               config.ReadRetry,
+		onEvict:                 config.OnEvict,
+		onCorruption:            config.OnCorruption,
+		commitInterceptor:       config.commitInterceptor,
+	}
+	if trieDB.viewBuildConcurrency <= 0 {
+		trieDB.viewBuildConcurrency = numCPU
+	}
+	if trieDB.onEvict != nil {
+		trieDB.onEvictQueue = make(chan []path, onEvictQueueSize)
+		trieDB.stopOnEvict = make(chan struct{})
+	}
+	if trieDB.readRetry.MaxBackoff <= 0 {
+		trieDB.readRetry.MaxBackoff = DefaultReadRetryMaxBackoff
+	}
+	if trieDB.readRetry.MaxWait <= 0 {
+		trieDB.readRetry.MaxWait = DefaultReadRetryMaxWait
+	}
+	if config.SeparateValueStore {
+		trieDB.valueDB = prefixdb.New(valuePrefix, db)
+		trieDB.valueRefDB = prefixdb.New(valueRefPrefix, db)
+	}
+	if err := trieDB.checkValueStoreLayout(db); err != nil {
+		return nil, err
+	}
+
+	if config.AdaptiveEviction {
+		adaptiveEviction, err := newAdaptiveEvictionBatchSize(config)
+		if err != nil {
+			return nil, err
+		}
+		trieDB.adaptiveEviction = adaptiveEviction
+		metrics.SetEvictionBatchSize(adaptiveEviction.current)
 	}
 
 	// Note: trieDB.OnEviction is responsible for writing intermediary nodes to
 	// disk as they are evicted from the cache.
 	trieDB.nodeCache = newOnEvictCache[path](config.NodeCacheSize, trieDB.onEviction)
+	trieDB.nodeCache.maxPinned = config.NodeCacheSize / maxPinnedFractionOfCacheSize
 
 	root, err := trieDB.initializeRootIfNeeded()
 	if err != nil {
@@ -231,10 +822,183 @@ func newDatabase(
 	default:
 		return nil, err
 	}
+	trieDB.cachedRootID.Set(trieDB.getMerkleRoot())
+
+	// Only verify commits after rebuilding since the partially rebuilt trie
+	// doesn't contain every key/value pair on disk.
+	trieDB.verifyOnCommit = config.VerifyOnCommit
 
 	// mark that the db has not yet been cleanly closed
-	err = trieDB.metadataDB.Put(cleanShutdownKey, didNotHaveCleanShutdown)
-	return trieDB, err
+	if err := trieDB.metadataDB.Put(cleanShutdownKey, didNotHaveCleanShutdown); err != nil {
+		return nil, err
+	}
+
+	if config.FlushPolicy == WriteBack {
+		// Values are flushed before the nodes that reference them so that a
+		// node on disk never references a missing value.
+		if trieDB.valueDB != nil {
+			valueDB := versiondb.New(trieDB.valueDB)
+			trieDB.valueDB = valueDB
+			valueRefDB := versiondb.New(trieDB.valueRefDB)
+			trieDB.valueRefDB = valueRefDB
+			trieDB.writeBackDBs = append(trieDB.writeBackDBs, valueDB, valueRefDB)
+		}
+		nodeDB := versiondb.New(trieDB.nodeDB)
+		trieDB.nodeDB = nodeDB
+		trieDB.writeBackDBs = append(trieDB.writeBackDBs, nodeDB)
+
+		if config.FlushInterval > 0 {
+			trieDB.stopFlushing = make(chan struct{})
+			go trieDB.flushPeriodically(config.FlushInterval)
+		}
+	}
+
+	// Batched commits are written to the stores above, so they're written
+	// to disk on the next flush if [Config.FlushPolicy] is [WriteBack].
+	if trieDB.valueDB != nil {
+		valueDB := newBufferedDB(trieDB.valueDB)
+		trieDB.valueDB = valueDB
+		valueRefDB := newBufferedDB(trieDB.valueRefDB)
+		trieDB.valueRefDB = valueRefDB
+		trieDB.batchedCommitDBs = append(trieDB.batchedCommitDBs, valueDB, valueRefDB)
+	}
+	nodeDB := newBufferedDB(trieDB.nodeDB)
+	trieDB.nodeDB = nodeDB
+	trieDB.batchedCommitDBs = append(trieDB.batchedCommitDBs, nodeDB)
+
+	if trieDB.onEvictQueue != nil {
+		go trieDB.dispatchEvicted()
+	}
+	return trieDB, nil
+}
+
+// flushPeriodically flushes [db] every [interval] until [db] is closed.
+func (db *merkleDB) flushPeriodically(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !db.periodicFlush() {
+				return
+			}
+		case <-db.stopFlushing:
+			return
+		}
+	}
+}
+
+// periodicFlush flushes [db]. If the flush fails, the buffered writes are
+// kept so that they're retried on the next flush, and the error is reported
+// by [HealthCheck] until a flush succeeds.
+// Returns false if [db] is closed.
+func (db *merkleDB) periodicFlush() bool {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return false
+	}
+	db.flushErr = db.flush()
+	if db.flushErr != nil {
+		db.metrics.PeriodicFlushFailed()
+	}
+	return true
+}
+
+func (db *merkleDB) Flush() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	if err := db.flush(); err != nil {
+		return err
+	}
+	db.flushErr = nil
+	return nil
+}
+
+// flush writes the buffered writes in [db.writeBackDBs] to disk.
+// Assumes [db.lock] is held.
+func (db *merkleDB) flush() error {
+	if err := db.commitWriteBackDBs(); err != nil {
+		return err
+	}
+	// The nodes that referenced [db.unreferencedValues] have been overwritten
+	// on disk, unless they're still buffered by a batched commit.
+	if db.batchingCommits || db.unreferencedValues.Len() == 0 {
+		return nil
+	}
+	if err := db.deleteUnreferencedValues(); err != nil {
+		return err
+	}
+	return db.commitWriteBackDBs()
+}
+
+// Assumes [db.lock] is held.
+func (db *merkleDB) commitWriteBackDBs() error {
+	for _, writeBackDB := range db.writeBackDBs {
+		if err := writeBackDB.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *merkleDB) BeginBatchedCommits() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	switch {
+	case db.closed:
+		return database.ErrClosed
+	case db.batchingCommits:
+		return ErrBatchingCommits
+	}
+
+	for _, batchedCommitDB := range db.batchedCommitDBs {
+		batchedCommitDB.startBuffering()
+	}
+	db.batchingCommits = true
+	return nil
+}
+
+func (db *merkleDB) EndBatchedCommits() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	return db.endBatchedCommits()
+}
+
+// endBatchedCommits writes the buffered writes in [db.batchedCommitDBs] to
+// the underlying stores and stops buffering them.
+// Assumes [db.lock] is held.
+func (db *merkleDB) endBatchedCommits() error {
+	if !db.batchingCommits {
+		return ErrNotBatchingCommits
+	}
+
+	// Values are written before the nodes that reference them so that a node
+	// on disk never references a missing value.
+	for _, batchedCommitDB := range db.batchedCommitDBs {
+		if err := batchedCommitDB.commitBuffer(); err != nil {
+			return err
+		}
+	}
+	db.batchingCommits = false
+
+	// If the nodes are buffered by [db.writeBackDBs], the unreferenced values
+	// are deleted once they're flushed.
+	if len(db.writeBackDBs) != 0 {
+		return nil
+	}
+	return db.deleteUnreferencedValues()
 }
 
 // Deletes every intermediate node and rebuilds them by re-adding every key/value.
@@ -268,7 +1032,7 @@ func (db *merkleDB) rebuild(ctx context.Context) error {
 		key := it.Key()
 		path := path(key)
 		value := it.Value()
-		n, err := parseNode(path, value)
+		n, err := db.parseNode(path, value)
 		if err != nil {
 			return err
 		}
@@ -289,6 +1053,12 @@ func (db *merkleDB) rebuild(ctx context.Context) error {
 	if err := view.commitToDB(ctx); err != nil {
 		return err
 	}
+	// The reference counts of values were updated as if every key was new.
+	if db.valueDB != nil {
+		if err := db.recountValueRefs(); err != nil {
+			return err
+		}
+	}
 	return db.nodeDB.Compact(nil, nil)
 }
 
@@ -315,7 +1085,71 @@ func (db *merkleDB) CommitChangeProof(ctx context.Context, proof *ChangeProof) e
 	return view.commitToDB(ctx)
 }
 
+func (db *merkleDB) CommitRangeProofVerified(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	proof *RangeProof,
+	expectedRoot ids.ID,
+) error {
+	// The proof is verified without an upper bound because [CommitRangeProof]
+	// deletes all keys after [start] if the proof has no key/value pairs.
+	smallestProvenPath, largestProvenPath, err := proof.provenRange(start, maybe.Nothing[[]byte]())
+	if err != nil {
+		return &proofVerificationError{err: err}
+	}
+	// Each subtrie of the proof is verified, in key order, before the rest of
+	// the proof so that invalid key/value pairs are reported with the key of
+	// the subtrie they're in, without hashing the key/value pairs after it.
+	subtries, err := proof.verifySubtries(ctx, smallestProvenPath, largestProvenPath)
+	if err != nil {
+		return &proofVerificationError{err: err}
+	}
+	// The verified subtries are added to the view by ID, so only the proof
+	// nodes and the key/value pairs on the edges of the range are hashed to
+	// check the root.
+	view, err := proof.buildView(ctx, smallestProvenPath, largestProvenPath, subtries)
+	if err != nil {
+		return &proofVerificationError{err: err}
+	}
+	root, err := view.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if root != expectedRoot {
+		return &proofVerificationError{
+			err: fmt.Errorf("%w: [%s], expected: [%s]", ErrInvalidProof, root, expectedRoot),
+		}
+	}
+	return db.CommitRangeProof(ctx, start, proof)
+}
+
+// proofVerificationError is returned when a proof fails verification before
+// being committed. It matches both [ErrProofVerificationFailed] and the
+// reason the proof failed verification with [errors.Is], which can't be done
+// with [fmt.Errorf] before Go 1.20.
+type proofVerificationError struct {
+	err error
+}
+
+func (e *proofVerificationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrProofVerificationFailed, e.err)
+}
+
+func (*proofVerificationError) Is(target error) bool {
+	return target == ErrProofVerificationFailed
+}
+
+func (e *proofVerificationError) Unwrap() error {
+	return e.err
+}
+
 func (db *merkleDB) CommitRangeProof(ctx context.Context, start maybe.Maybe[[]byte], proof *RangeProof) error {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.CommitRangeProof", oteltrace.WithAttributes(
+		attribute.Int("keyCount", len(proof.KeyValues)),
+		attribute.Int("rangeSize", keyValuesSize(proof.KeyValues)),
+	))
+	defer span.End()
+
 	db.commitLock.Lock()
 	defer db.commitLock.Unlock()
 
@@ -357,102 +1191,594 @@ func (db *merkleDB) CommitRangeProof(ctx context.Context, start maybe.Maybe[[]by
 	return view.commitToDB(ctx)
 }
 
-func (db *merkleDB) Compact(start []byte, limit []byte) error {
-	return db.nodeDB.Compact(start, limit)
-}
+func (db *merkleDB) BulkLoadSorted(ctx context.Context, it database.Iterator) error {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.BulkLoadSorted")
+	defer span.End()
 
-func (db *merkleDB) Close() error {
 	db.commitLock.Lock()
 	defer db.commitLock.Unlock()
 
-	db.lock.Lock()
-	defer db.lock.Unlock()
-
 	if db.closed {
 		return database.ErrClosed
 	}
+	// [db.root] is only replaced while [db.commitLock] is held.
+	if db.root.hasValue() || len(db.root.children) > 0 {
+		return ErrNotEmpty
+	}
 
-	db.closed = true
-
-	defer func() {
-		_ = db.metadataDB.Close()
-		_ = db.nodeDB.Close()
-	}()
-
-	if err := db.onEvictionErr.Get(); err != nil {
-		// If there was an error during cache eviction,
-		// [db.nodeCache] and [db.nodeDB] are in an inconsistent state.
-		// Do not write cached nodes to disk or mark clean shutdown.
+	nodeBatch := db.nodeDB.NewBatch()
+	var (
+		valueBatch     database.Batch
+		valueRefDeltas map[string]int
+	)
+	if db.valueDB != nil {
+		valueBatch = db.valueDB.NewBatch()
+		valueRefDeltas = make(map[string]int)
+	}
+	changes := newChangeSummary(defaultPreallocationSize)
+	// The loaded key/value pairs, if the root is verified before it's written.
+	var ops []database.BatchOp
+
+	// finish calculates the ID of [n], whose children have all been added,
+	// and writes it to the batches.
+	finish := func(n *node) error {
+		if err := n.calculateID(db.metrics); err != nil {
+			return err
+		}
+		if valueBatch != nil {
+			if err := writeValueToBatch(valueBatch, n); err != nil {
+				return err
+			}
+			addValueRefDelta(valueRefDeltas, n, 1)
+		}
+		db.metrics.IOKeyWrite()
+		if err := db.writeNodeToBatch(nodeBatch, n); err != nil {
+			return err
+		}
+		changes.nodes[n.key] = &change[*node]{after: n}
 		return nil
 	}
 
-	// Flush [nodeCache] to persist intermediary nodes to disk.
-	if err := db.nodeCache.Flush(); err != nil {
-		// There was an error during cache eviction.
-		// Don't commit to disk.
-		return err
-	}
+	// [stack] holds the nodes on the path from the root to the last inserted
+	// key whose children may not all have been added yet. Each node is the
+	// parent of the next one once it's finished.
+	var (
+		root     = newNode(nil, RootPath)
+		stack    = []*node{root}
+		prevKey  path
+		keyCount int
+	)
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	// Successfully wrote intermediate nodes.
-	return db.metadataDB.Put(cleanShutdownKey, hadCleanShutdown)
-}
+		key, value := it.Key(), it.Value()
+		if err := db.validatePut(key, value); err != nil {
+			return err
+		}
 
-func (db *merkleDB) Get(key []byte) ([]byte, error) {
-	// this is a duplicate because the database interface doesn't support
-	// contexts, which are used for tracing
-	return db.GetValue(context.Background(), key)
-}
+		k := newPath(key)
+		if keyCount > 0 && k.Compare(prevKey) <= 0 {
+			return fmt.Errorf("%w: %x after %x", ErrUnsortedKeys, key, prevKey.Serialize().Value)
+		}
 
-func (db *merkleDB) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error) {
-	_, span := db.tracer.Start(ctx, "MerkleDB.GetValues", oteltrace.WithAttributes(
-		attribute.Int("keyCount", len(keys)),
-	))
-	defer span.End()
+		// Finish the nodes that aren't on the path to [k]. If [k] diverges
+		// from the last inserted key below a node on the stack, the finished
+		// nodes become children of a new branch node at the divergence.
+		commonLen := getLengthOfCommonPrefix(prevKey, k)
+		for len(stack[len(stack)-1].key) > commonLen {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if err := finish(n); err != nil {
+				return err
+			}
 
-	// Lock to ensure no commit happens during the reads.
-	db.lock.RLock()
-	defer db.lock.RUnlock()
+			parent := stack[len(stack)-1]
+			if len(parent.key) < commonLen {
+				parent = newNode(nil, k[:commonLen])
+				stack = append(stack, parent)
+			}
+			parent.addChild(n)
+		}
 
-	values := make([][]byte, len(keys))
-	errors := make([]error, len(keys))
-	for i, key := range keys {
-		values[i], errors[i] = db.getValueCopy(newPath(key))
+		val := maybe.Some(slices.Clone(value))
+		if len(k) == 0 {
+			root.setValue(val)
+		} else {
+			n := newNode(nil, k)
+			n.setValue(val)
+			stack = append(stack, n)
+		}
+		changes.values[k] = &change[maybe.Maybe[[]byte]]{after: val}
+		if db.verifyOnCommit {
+			ops = append(ops, database.BatchOp{
+				Key:   slices.Clone(key),
+				Value: val.Value(),
+			})
+		}
+
+		prevKey = k
+		keyCount++
+	}
+	if err := it.Error(); err != nil {
+		return err
 	}
-	return values, errors
-}
 
-// GetValue returns the value associated with [key].
-// Returns database.ErrNotFound if it doesn't exist.
-func (db *merkleDB) GetValue(ctx context.Context, key []byte) ([]byte, error) {
-	_, span := db.tracer.Start(ctx, "MerkleDB.GetValue")
-	defer span.End()
+	for len(stack) > 1 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if err := finish(n); err != nil {
+			return err
+		}
+		stack[len(stack)-1].addChild(n)
+	}
+	if err := finish(root); err != nil {
+		return err
+	}
+	changes.nodes[RootPath].before = db.root
+	changes.rootID = root.id
 
-	db.lock.RLock()
-	defer db.lock.RUnlock()
+	// Verified before anything is written so that a mismatched root is never
+	// committed. Any key/value pairs already on disk are included, although
+	// there shouldn't be any since the database is empty.
+	if db.verifyOnCommit {
+		dbIt := db.NewIterator()
+		err := verifyRootOf(ctx, root.id, dbIt, ops)
+		dbIt.Release()
+		if err != nil {
+			return err
+		}
+	}
 
-	return db.getValueCopy(newPath(key))
-}
+	db.lock.Lock()
+	defer db.lock.Unlock()
 
-// getValueCopy returns a copy of the value for the given [key].
-// Returns database.ErrNotFound if it doesn't exist.
-// Assumes [db.lock] is read locked.
-func (db *merkleDB) getValueCopy(key path) ([]byte, error) {
-	val, err := db.getValueWithoutLock(key)
-	if err != nil {
-		return nil, err
+	if db.closed {
+		return database.ErrClosed
 	}
-	return slices.Clone(val), nil
-}
-
-// getValue returns the value for the given [key].
-// Returns database.ErrNotFound if it doesn't exist.
-// Assumes [db.lock] isn't held.
-func (db *merkleDB) getValue(key path) ([]byte, error) {
-	db.lock.RLock()
-	defer db.lock.RUnlock()
 
-	return db.getValueWithoutLock(key)
-}
+	// The cache may hold misses for keys that are about to be written.
+	// Since the database is empty, flushing it doesn't write any nodes that
+	// would overwrite the new ones.
+	if err := db.nodeCache.Flush(); err != nil {
+		return err
+	}
+	var (
+		valueRefBatch  database.Batch
+		valueRefCounts map[string]uint64
+	)
+	if valueBatch != nil {
+		valueRefBatch = db.valueRefDB.NewBatch()
+		var err error
+		valueRefCounts, err = db.writeValueRefCounts(valueRefBatch, valueRefDeltas)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.interceptCommit(commitStageWriteBatch); err != nil {
+		return err
+	}
+	if valueBatch != nil {
+		if err := valueBatch.Write(); err != nil {
+			return err
+		}
+		if err := valueRefBatch.Write(); err != nil {
+			return err
+		}
+	}
+	if err := nodeBatch.Write(); err != nil {
+		return err
+	}
+	db.recordValueRefCounts(valueRefCounts)
+
+	if err := db.interceptCommit(commitStageUpdateMemory); err != nil {
+		return err
+	}
+
+	db.invalidateChildrenExcept(nil)
+	db.commitCount++
+	db.root = root
+	db.cachedRootID.Set(root.id)
+	if db.keyCountKnown {
+		db.keyCount = keyCount
+	}
+	db.recordCommitStats(changes)
+	db.history.record(changes)
+	return nil
+}
+
+func (db *merkleDB) Update(ctx context.Context, fn func(tx Txn) error) error {
+	db.commitLock.Lock()
+	defer db.commitLock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	tx := newTxn(db)
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	ops := tx.ops()
+	if err := db.validateOps(ops); err != nil {
+		return err
+	}
+	// Don't need to lock [view] because nobody else has a reference to it.
+	view, err := db.newUntrackedView(ops)
+	if err != nil {
+		return err
+	}
+	return view.commitToDB(ctx)
+}
+
+func (db *merkleDB) ExtractRange(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	dst database.Database,
+) error {
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0 {
+		return ErrStartAfterEnd
+	}
+
+	// Prevent commits to [db] while the range is being copied.
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	extractedDB, err := newDatabase(
+		ctx,
+		dst,
+		Config{
+			EvictionBatchSize:  db.evictionBatchSize,
+			HistoryLength:      db.history.maxHistoryLen,
+			NodeCacheSize:      db.nodeCache.maxSize,
+			Tracer:             db.tracer,
+			MaxValueLen:        db.maxValueLen,
+			KeyValidator:       db.keyValidator,
+			ReadRetry:          db.readRetry,
+			SeparateValueStore: db.valueDB != nil,
+		},
+		&mockMetrics{},
+	)
+	if err != nil {
+		return err
+	}
+
+	viewSizeLimit := math.Max(
+		db.nodeCache.maxSize/rebuildViewSizeFractionOfCacheSize,
+		minRebuildViewSizePerCommit,
+	)
+	if err := db.extractRange(start, end, extractedDB, viewSizeLimit); err != nil {
+		_ = extractedDB.Close()
+		return err
+	}
+	return extractedDB.Close()
+}
+
+// Copies the key/value pairs in [start, end) into [dst], committing at most
+// [viewSizeLimit] key/value pairs at a time.
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) extractRange(
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	dst *merkleDB,
+	viewSizeLimit int,
+) error {
+	it := db.NewIteratorWithStart(start.Value())
+	defer it.Release()
+
+	currentOps := make([]database.BatchOp, 0, viewSizeLimit)
+	for it.Next() {
+		key := it.Key()
+		if end.HasValue() && bytes.Compare(key, end.Value()) >= 0 {
+			break
+		}
+
+		if len(currentOps) >= viewSizeLimit {
+			if err := dst.commitBatch(currentOps); err != nil {
+				return err
+			}
+			currentOps = make([]database.BatchOp, 0, viewSizeLimit)
+		}
+
+		currentOps = append(currentOps, database.BatchOp{
+			Key:   key,
+			Value: slices.Clone(it.Value()),
+		})
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return dst.commitBatch(currentOps)
+}
+
+func (db *merkleDB) Compact(start []byte, limit []byte) error {
+	return db.nodeDB.Compact(start, limit)
+}
+
+func (db *merkleDB) Close() error {
+	db.commitLock.Lock()
+	defer db.commitLock.Unlock()
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	db.closed = true
+	if db.stopFlushing != nil {
+		close(db.stopFlushing)
+	}
+	if db.stopOnEvict != nil {
+		close(db.stopOnEvict)
+	}
+
+	defer func() {
+		_ = db.metadataDB.Close()
+		_ = db.nodeDB.Close()
+	}()
+
+	if err := db.onEvictionErr.Get(); err != nil {
+		// If there was an error during cache eviction,
+		// [db.nodeCache] and [db.nodeDB] are in an inconsistent state.
+		// Do not write cached nodes to disk or mark clean shutdown.
+		return nil
+	}
+
+	// Flush [nodeCache] to persist intermediary nodes to disk.
+	if err := db.nodeCache.Flush(); err != nil {
+		// There was an error during cache eviction.
+		// Don't commit to disk.
+		return err
+	}
+
+	if db.batchingCommits {
+		if err := db.endBatchedCommits(); err != nil {
+			return err
+		}
+	}
+
+	if err := db.flush(); err != nil {
+		return err
+	}
+
+	// Successfully wrote intermediate nodes.
+	return db.metadataDB.Put(cleanShutdownKey, hadCleanShutdown)
+}
+
+func (db *merkleDB) Get(key []byte) ([]byte, error) {
+	// this is a duplicate because the database interface doesn't support
+	// contexts, which are used for tracing
+	return db.GetValue(context.Background(), key)
+}
+
+func (db *merkleDB) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error) {
+	_, span := db.tracer.Start(ctx, "MerkleDB.GetValues", oteltrace.WithAttributes(
+		attribute.Int("keyCount", len(keys)),
+	))
+	defer span.End()
+
+	// Lock to ensure no commit happens during the reads.
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	values := make([][]byte, len(keys))
+	errors := make([]error, len(keys))
+	for i, key := range keys {
+		values[i], errors[i] = db.getValueCopy(newPath(key))
+	}
+	return values, errors
+}
+
+func (db *merkleDB) GetMap(ctx context.Context, keys [][]byte) (map[string][]byte, error) {
+	_, span := db.tracer.Start(ctx, "MerkleDB.GetMap", oteltrace.WithAttributes(
+		attribute.Int("keyCount", len(keys)),
+	))
+	defer span.End()
+
+	// Lock to ensure no commit happens during the reads.
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		value, err := db.getValueCopy(newPath(key))
+		if err == database.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[string(key)] = value
+	}
+	return values, nil
+}
+
+func (db *merkleDB) GetRawNode(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	n, err := db.getNode(newPath(key))
+	if err != nil {
+		return nil, err
+	}
+	return db.encodeNode(n), nil
+}
+
+func (db *merkleDB) GetValuesConsistent(ctx context.Context, keys [][]byte) ([][]byte, []error) {
+	_, span := db.tracer.Start(ctx, "MerkleDB.GetValuesConsistent", oteltrace.WithAttributes(
+		attribute.Int("keyCount", len(keys)),
+	))
+	defer span.End()
+
+	var (
+		values     [][]byte
+		errs       []error
+		consistent bool
+	)
+	for attempt := 0; attempt < maxLockFreeReadAttempts && !consistent; attempt++ {
+		values, errs, consistent = db.tryGetValuesConsistent(keys)
+	}
+	if !consistent {
+		db.commitLock.RLock()
+		values, errs, _ = db.tryGetValuesConsistent(keys)
+		db.commitLock.RUnlock()
+	}
+	return values, errs
+}
+
+// tryGetValuesConsistent returns copies of the values associated with [keys]
+// and whether no commits happened while they were being read. If a commit
+// happened, the returned values and errors should be discarded.
+// Assumes [db.lock] isn't held.
+func (db *merkleDB) tryGetValuesConsistent(keys [][]byte) ([][]byte, []error, bool) {
+	db.lock.RLock()
+	commitCount := db.commitCount
+	db.lock.RUnlock()
+
+	values := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		db.lock.RLock()
+		values[i], errs[i] = db.getValueCopy(newPath(key))
+		db.lock.RUnlock()
+	}
+
+	db.lock.RLock()
+	consistent := commitCount == db.commitCount
+	db.lock.RUnlock()
+	return values, errs, consistent
+}
+
+// GetValue returns the value associated with [key].
+// Returns database.ErrNotFound if it doesn't exist.
+func (db *merkleDB) GetValue(ctx context.Context, key []byte) ([]byte, error) {
+	_, span := db.tracer.Start(ctx, "MerkleDB.GetValue")
+	defer span.End()
+
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.getValueCopy(newPath(key))
+}
+
+func (db *merkleDB) GetUnsafe(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	// The cache doesn't retain the path it's queried with, so the path is
+	// written into a pooled buffer rather than allocated.
+	buffer := pathBufferPool.Get().(*[]byte)
+	n, isCached := db.nodeCache.Get(newPathInBuffer(buffer, key))
+	pathBufferPool.Put(buffer)
+
+	if isCached {
+		db.metrics.DBNodeCacheHit()
+	} else {
+		// The path is retained by the cache if the node is read from disk.
+		var err error
+		n, err = db.getNode(newPath(key))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if n == nil || n.value.IsNothing() {
+		return nil, database.ErrNotFound
+	}
+	return n.value.Value(), nil
+}
+
+func (db *merkleDB) GetOrDefault(key, def []byte) []byte {
+	value, err := db.Get(key)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+func (db *merkleDB) CommitSeq() uint64 {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	return db.history.nextInsertNumber - 1
+}
+
+func (db *merkleDB) GetAtSeq(seq uint64, key []byte) ([]byte, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	rootID, err := db.history.getRootAtSeq(seq)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBounds := maybe.Some(key)
+	view, err := db.getHistoricalViewForRange(rootID, keyBounds, keyBounds)
+	if err != nil {
+		return nil, err
+	}
+	return view.getValueCopy(newPath(key))
+}
+
+func (db *merkleDB) KeysChangedSince(t time.Time) ([][]byte, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	changedKeySet, err := db.history.getKeysChangedSince(t)
+	if err != nil {
+		return nil, err
+	}
+	changedKeys := changedKeySet.List()
+	utils.Sort(changedKeys)
+
+	keys := make([][]byte, len(changedKeys))
+	for i, key := range changedKeys {
+		keys[i] = key.Serialize().Value
+	}
+	return keys, nil
+}
+
+// getValueCopy returns a copy of the value for the given [key].
+// Returns database.ErrNotFound if it doesn't exist.
+// Assumes [db.lock] is read locked.
+func (db *merkleDB) getValueCopy(key path) ([]byte, error) {
+	val, err := db.getValueWithoutLock(key)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(val), nil
+}
+
+// getValue returns the value for the given [key].
+// Returns database.ErrNotFound if it doesn't exist.
+// Assumes [db.lock] isn't held.
+func (db *merkleDB) getValue(key path) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.getValueWithoutLock(key)
+}
 
 // getValueWithoutLock returns the value for the given [key].
 // Returns database.ErrNotFound if it doesn't exist.
@@ -472,6 +1798,224 @@ func (db *merkleDB) getValueWithoutLock(key path) ([]byte, error) {
 	return n.value.Value(), nil
 }
 
+func (db *merkleDB) Len() (int, error) {
+	// Prevent commits so the count reflects a single state of the trie.
+	// [db.lock] isn't held while iterating so that reads aren't blocked.
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return 0, database.ErrClosed
+	}
+	return db.len()
+}
+
+// len returns the number of keys in [db].
+// Assumes [db.commitLock] is read locked and [db.lock] isn't held.
+func (db *merkleDB) len() (int, error) {
+	db.lock.RLock()
+	knownCount, known := db.keyCount, db.keyCountKnown
+	db.lock.RUnlock()
+	if known {
+		return knownCount, nil
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if err := it.Error(); err != nil {
+		return 0, err
+	}
+
+	db.lock.Lock()
+	db.keyCount = count
+	db.keyCountKnown = true
+	db.lock.Unlock()
+	return count, nil
+}
+
+func (db *merkleDB) CountPrefix(ctx context.Context, prefix []byte) (int, error) {
+	_, span := db.tracer.Start(ctx, "MerkleDB.CountPrefix")
+	defer span.End()
+
+	// Prevent commits so the count reflects a single state of the trie.
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return 0, database.ErrClosed
+	}
+
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+	return count, it.Error()
+}
+
+func (db *merkleDB) PrefetchRange(ctx context.Context, start, end maybe.Maybe[[]byte]) error {
+	_, span := db.tracer.Start(ctx, "MerkleDB.PrefetchRange")
+	defer span.End()
+
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0 {
+		return ErrStartAfterEnd
+	}
+
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	var (
+		startPath = maybe.Bind(start, newPath)
+		endPath   = maybe.Bind(end, newPath)
+		// Loading more nodes than fit in the cache would evict the nodes
+		// that were just loaded.
+		remaining = db.nodeCache.maxSize
+		stack     = []*node{db.root}
+	)
+	for len(stack) > 0 && remaining > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for index, child := range n.children {
+			childPath := n.key + path(index) + child.compressedPath
+			if !subtrieInRange(childPath, startPath, endPath) {
+				continue
+			}
+
+			childNode, err := db.getNode(childPath)
+			if err != nil {
+				return err
+			}
+			stack = append(stack, childNode)
+
+			remaining--
+			if remaining == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// subtrieInRange returns true iff a key with prefix [p] may be in the range
+// [start, end].
+func subtrieInRange(p path, start, end maybe.Maybe[path]) bool {
+	// Every key with prefix [p] is >= [p].
+	if end.HasValue() && p.Compare(end.Value()) > 0 {
+		return false
+	}
+	// Every key with prefix [p] is < [start] iff [p] < [start] and [start]
+	// doesn't have prefix [p].
+	if start.HasValue() && p.Less(start.Value()) && !start.Value().HasPrefix(p) {
+		return false
+	}
+	return true
+}
+
+func (db *merkleDB) EstimateRangeSize(ctx context.Context, start, end maybe.Maybe[[]byte]) (uint64, error) {
+	_, span := db.tracer.Start(ctx, "MerkleDB.EstimateRangeSize")
+	defer span.End()
+
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0 {
+		return 0, ErrStartAfterEnd
+	}
+
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return 0, database.ErrClosed
+	}
+
+	var (
+		startPath = maybe.Bind(start, newPath)
+		endPath   = maybe.Bind(end, newPath)
+		// The walks are seeded by the root so that the estimate of a
+		// given trie is deterministic.
+		rootID = db.getMerkleRoot()
+		source = rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(rootID[:])))) // #nosec G404
+		total  float64
+	)
+	for i := 0; i < estimateRangeSizeWalks; i++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		size, err := db.estimateRangeSizeWalk(startPath, endPath, source)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	return uint64(total/estimateRangeSizeWalks + 0.5), nil
+}
+
+// estimateRangeSizeWalk takes a random walk from the root and returns the
+// resulting estimate of the size of the nodes with keys in [start, end].
+// See [estimateRangeSizeWalks].
+// Assumes [db.lock] is read locked.
+func (db *merkleDB) estimateRangeSizeWalk(start, end maybe.Maybe[path], source *rand.Rand) (float64, error) {
+	var (
+		n        = db.root
+		weight   = 1.0
+		estimate float64
+		// The children of [n] that may contain keys in the range.
+		childPaths = make([]path, 0, NodeBranchFactor)
+	)
+	for {
+		if (start.IsNothing() || n.key.Compare(start.Value()) >= 0) &&
+			(end.IsNothing() || n.key.Compare(end.Value()) <= 0) {
+			estimate += weight * float64(db.nodeDiskSize(n))
+		}
+
+		childPaths = childPaths[:0]
+		for index, child := range n.children {
+			childPath := n.key + path(index) + child.compressedPath
+			if subtrieInRange(childPath, start, end) {
+				childPaths = append(childPaths, childPath)
+			}
+		}
+		if len(childPaths) == 0 {
+			return estimate, nil
+		}
+
+		// Sort so the choice only depends on [source].
+		slices.Sort(childPaths)
+		weight *= float64(len(childPaths))
+
+		var err error
+		n, err = db.getNode(childPaths[source.Intn(len(childPaths))])
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// nodeDiskSize returns the number of bytes [n] occupies on disk, including its
+// key and, if it's stored separately, its value.
+func (db *merkleDB) nodeDiskSize(n *node) int {
+	size := len(n.key.Bytes()) + len(db.encodeNode(n))
+	if db.valueDB != nil && storesValueSeparately(n.value) {
+		size += len(n.valueDigest.Value()) + len(n.value.Value())
+	}
+	return size
+}
+
 func (db *merkleDB) GetMerkleRoot(ctx context.Context) (ids.ID, error) {
 	_, span := db.tracer.Start(ctx, "MerkleDB.GetMerkleRoot")
 	defer span.End()
@@ -486,7 +2030,15 @@ func (db *merkleDB) GetMerkleRoot(ctx context.Context) (ids.ID, error) {
 	return db.getMerkleRoot(), nil
 }
 
-// Assumes [db.lock] is read locked.
+func (db *merkleDB) CachedRoot() ids.ID {
+	return db.cachedRootID.Get()
+}
+
+// getMerkleRoot returns the root ID without taking any locks, so it can be
+// used by operations that already hold [db.lock].
+// The root ID is calculated when changes are committed, so the trie isn't
+// walked.
+// Assumes [db.lock] or [db.commitLock] is read locked.
 func (db *merkleDB) getMerkleRoot() ids.ID {
 	return db.root.id
 }
@@ -518,10 +2070,159 @@ func (db *merkleDB) GetRangeProof(
 	end maybe.Maybe[[]byte],
 	maxLength int,
 ) (*RangeProof, error) {
+	return db.getRangeProofAtRoot(ctx, db.getCurrentRoot(), start, end, maxLength)
+}
+
+func (db *merkleDB) GetRangeProofWithBounds(
+	ctx context.Context,
+	bounds Bounds,
+	maxLength int,
+) (*RangeProof, error) {
+	return db.getRangeProofWithBoundsAtRoot(ctx, db.getCurrentRoot(), bounds, maxLength)
+}
+
+func (db *merkleDB) GetMultiRangeProof(
+	ctx context.Context,
+	ranges []Bounds,
+	maxTotalKeys int,
+) (*MultiRangeProof, error) {
+	if maxTotalKeys <= 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxTotalKeys)
+	}
+	if err := validateRanges(ranges); err != nil {
+		return nil, err
+	}
+
+	// All the ranges are proven at the same root, even if there are commits
+	// while the proofs are generated.
+	var (
+		rootID    = db.getCurrentRoot()
+		proof     = &MultiRangeProof{}
+		remaining = maxTotalKeys
+	)
+	for _, bounds := range ranges {
+		if remaining == 0 {
+			break
+		}
+		rangeProof, err := db.getRangeProofWithBoundsAtRoot(ctx, rootID, bounds, remaining)
+		if err != nil {
+			return nil, err
+		}
+		proof.Ranges = append(proof.Ranges, bounds)
+		proof.Proofs = append(proof.Proofs, rangeProof)
+		remaining -= len(rangeProof.KeyValues)
+	}
+	return proof, nil
+}
+
+func (db *merkleDB) getRangeProofWithBoundsAtRoot(
+	ctx context.Context,
+	rootID ids.ID,
+	bounds Bounds,
+	maxLength int,
+) (*RangeProof, error) {
+	start := bounds.inclusiveStart()
+	proof, err := db.getRangeProofAtRoot(ctx, rootID, start, bounds.End, maxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// If the proof includes the excluded end, remove it by regenerating the
+	// proof without the last key. If it's the only key, it's left in the
+	// proof to prove that there are no keys in [bounds].
+	numKeys := len(proof.KeyValues)
+	if numKeys <= 1 || !bounds.excludesEnd(proof.KeyValues[numKeys-1].Key) {
+		return proof, nil
+	}
+	return db.getRangeProofAtRoot(ctx, rootID, start, bounds.End, numKeys-1)
+}
+
+func (db *merkleDB) GetAbsenceProof(ctx context.Context, a, b []byte) (*RangeProof, error) {
+	if bytes.Compare(a, b) >= 0 {
+		return nil, ErrStartAfterEnd
+	}
+
+	// If the gap is empty, the only key in [absenceProofStart(a), b] is [b],
+	// if it exists. If the gap isn't empty, the first key in the gap is
+	// returned so the proof fails verification.
+	return db.GetRangeProof(
+		ctx,
+		maybe.Some(absenceProofStart(a)),
+		maybe.Some(b),
+		1,
+	)
+}
+
+func (db *merkleDB) SampleProof(ctx context.Context, seed uint64, sampleSize int) (*RangeProof, [][]byte, error) {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.SampleProof", oteltrace.WithAttributes(
+		attribute.Int("sampleSize", sampleSize),
+	))
+	defer span.End()
+
+	if sampleSize <= 0 {
+		return nil, nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, sampleSize)
+	}
+
+	rootID, start, err := db.getSampleStart(seed, sampleSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The proof is generated at [rootID] so that the sample is consistent
+	// even if changes are committed after the start was chosen.
+	proof, err := db.getRangeProofAtRoot(ctx, rootID, start, maybe.Nothing[[]byte](), sampleSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([][]byte, len(proof.KeyValues))
+	for i, kv := range proof.KeyValues {
+		keys[i] = kv.Key
+	}
+	return proof, keys, nil
+}
+
+// getSampleStart returns the current root and the first key of the sample
+// selected by [seed] at that root. The first key is chosen uniformly among the
+// keys that are followed by at least [sampleSize]-1 keys.
+// Returns Nothing if the database has no more than [sampleSize] keys.
+func (db *merkleDB) getSampleStart(seed uint64, sampleSize int) (ids.ID, maybe.Maybe[[]byte], error) {
+	// Prevent commits so the keys are read from the trie with the returned
+	// root.
 	db.commitLock.RLock()
 	defer db.commitLock.RUnlock()
 
-	return db.getRangeProofAtRoot(ctx, db.getMerkleRoot(), start, end, maxLength)
+	if db.closed {
+		return ids.Empty, maybe.Nothing[[]byte](), database.ErrClosed
+	}
+
+	rootID := db.getCurrentRoot()
+	numKeys, err := db.len()
+	if err != nil {
+		return ids.Empty, maybe.Nothing[[]byte](), err
+	}
+	if numKeys <= sampleSize {
+		return rootID, maybe.Nothing[[]byte](), nil
+	}
+
+	seedBytes := make([]byte, wrappers.LongLen+len(rootID))
+	binary.BigEndian.PutUint64(seedBytes, seed)
+	copy(seedBytes[wrappers.LongLen:], rootID[:])
+	hash := hashing.ComputeHash256(seedBytes)
+	offset := binary.BigEndian.Uint64(hash) % uint64(numKeys-sampleSize+1)
+
+	it := db.NewKeyIterator(nil, nil)
+	defer it.Release()
+
+	for i := uint64(0); it.Next(); i++ {
+		if i == offset {
+			return rootID, maybe.Some(it.Key()), nil
+		}
+	}
+	if err := it.Error(); err != nil {
+		return ids.Empty, maybe.Nothing[[]byte](), err
+	}
+	return ids.Empty, maybe.Nothing[[]byte](), fmt.Errorf("%w: expected %d keys", errSampleOutOfRange, numKeys)
 }
 
 func (db *merkleDB) GetRangeProofAtRoot(
@@ -531,32 +2232,97 @@ func (db *merkleDB) GetRangeProofAtRoot(
 	end maybe.Maybe[[]byte],
 	maxLength int,
 ) (*RangeProof, error) {
-	db.commitLock.RLock()
-	defer db.commitLock.RUnlock()
+	return db.getRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+}
+
+// getCurrentRoot returns the root ID of the last committed change.
+// Assumes [db.lock] isn't held.
+func (db *merkleDB) getCurrentRoot() ids.ID {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.getMerkleRoot()
+}
+
+// getRangeProofAtRoot returns a range proof of the trie with root [rootID].
+//
+// The proof is generated without blocking commits. If a commit happens while
+// the proof is being generated, the proof may not be consistent with
+// [rootID], so it's generated again from the history. After
+// [maxLockFreeReadAttempts] attempts, commits are blocked until the
+// proof is generated.
+//
+// Assumes [db.commitLock] and [db.lock] aren't held.
+func (db *merkleDB) getRangeProofAtRoot(
+	ctx context.Context,
+	rootID ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*RangeProof, error) {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.GetRangeProof", oteltrace.WithAttributes(
+		attribute.Int("maxLength", maxLength),
+	))
+	defer span.End()
 
-	return db.getRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+	if maxLength <= 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
+	}
+
+	var (
+		proof      *RangeProof
+		consistent bool
+		err        error
+	)
+	for attempt := 0; attempt < maxLockFreeReadAttempts && !consistent; attempt++ {
+		proof, consistent, err = db.tryGetRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+	}
+	if !consistent {
+		db.commitLock.RLock()
+		proof, _, err = db.tryGetRangeProofAtRoot(ctx, rootID, start, end, maxLength)
+		db.commitLock.RUnlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.Int("keyCount", len(proof.KeyValues)),
+		attribute.Int("rangeSize", keyValuesSize(proof.KeyValues)),
+	)
+	return proof, nil
 }
 
-// Assumes [db.commitLock] is read locked.
-func (db *merkleDB) getRangeProofAtRoot(
+// tryGetRangeProofAtRoot returns a range proof of the trie with root [rootID]
+// and whether no commits happened while the proof was being generated. If a
+// commit happened, the returned proof and error should be discarded.
+// Assumes [db.lock] isn't held.
+func (db *merkleDB) tryGetRangeProofAtRoot(
 	ctx context.Context,
 	rootID ids.ID,
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
 	maxLength int,
-) (*RangeProof, error) {
+) (*RangeProof, bool, error) {
+	db.lock.RLock()
 	if db.closed {
-		return nil, database.ErrClosed
-	}
-	if maxLength <= 0 {
-		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
+		db.lock.RUnlock()
+		return nil, true, database.ErrClosed
 	}
-
+	commitCount := db.commitCount
 	historicalView, err := db.getHistoricalViewForRange(rootID, start, end)
+	db.lock.RUnlock()
 	if err != nil {
-		return nil, err
+		return nil, true, err
 	}
-	return historicalView.GetRangeProof(ctx, start, end, maxLength)
+
+	// [historicalView] reads the nodes it didn't change from [db], so the
+	// proof is only consistent with [rootID] if no commits happened.
+	proof, err := historicalView.GetRangeProof(ctx, start, end, maxLength)
+
+	db.lock.RLock()
+	consistent := commitCount == db.commitCount
+	db.lock.RUnlock()
+	return proof, consistent, err
 }
 
 func (db *merkleDB) GetChangeProof(
@@ -567,6 +2333,11 @@ func (db *merkleDB) GetChangeProof(
 	end maybe.Maybe[[]byte],
 	maxLength int,
 ) (*ChangeProof, error) {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.GetChangeProof", oteltrace.WithAttributes(
+		attribute.Int("maxLength", maxLength),
+	))
+	defer span.End()
+
 	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) == 1 {
 		return nil, ErrStartAfterEnd
 	}
@@ -596,6 +2367,7 @@ func (db *merkleDB) GetChangeProof(
 		KeyChanges: make([]KeyChange, 0, len(changedKeys)),
 	}
 
+	rangeSize := 0
 	for _, key := range changedKeys {
 		change := changes.values[key]
 		serializedKey := key.Serialize().Value
@@ -605,7 +2377,12 @@ func (db *merkleDB) GetChangeProof(
 			// create a copy so edits of the []byte don't affect the db
 			Value: maybe.Bind(change.after, slices.Clone[[]byte]),
 		})
+		rangeSize += len(serializedKey) + len(change.after.Value())
 	}
+	span.SetAttributes(
+		attribute.Int("keyCount", len(result.KeyChanges)),
+		attribute.Int("rangeSize", rangeSize),
+	)
 
 	largestKey := end
 	if len(result.KeyChanges) > 0 {
@@ -655,10 +2432,107 @@ func (db *merkleDB) GetChangeProof(
 	return result, nil
 }
 
+func (db *merkleDB) GetDiffProof(
+	ctx context.Context,
+	fromRootID ids.ID,
+	toRootID ids.ID,
+	start maybe.Maybe[[]byte],
+	maxKeys int,
+) (*ChangeProof, maybe.Maybe[[]byte], error) {
+	proof, err := db.GetChangeProof(ctx, fromRootID, toRootID, start, maybe.Nothing[[]byte](), maxKeys)
+	if err != nil {
+		return nil, maybe.Nothing[[]byte](), err
+	}
+
+	// If there are fewer than [maxKeys] changes, there are no changes after
+	// the last one.
+	if len(proof.KeyChanges) < maxKeys {
+		return proof, maybe.Nothing[[]byte](), nil
+	}
+	lastKey := proof.KeyChanges[len(proof.KeyChanges)-1].Key
+	return proof, maybe.Some(absenceProofStart(lastKey)), nil
+}
+
+func (db *merkleDB) EstimateRangeProofCost(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxKeys int,
+) (int, int, error) {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.EstimateRangeProofCost", oteltrace.WithAttributes(
+		attribute.Int("maxKeys", maxKeys),
+	))
+	defer span.End()
+
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return 0, 0, database.ErrClosed
+	}
+
+	view, err := db.newUntrackedView(nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	// Don't need to lock [view] because nobody else has a reference to it.
+	return view.estimateRangeProofCost(ctx, start, end, maxKeys)
+}
+
+func (db *merkleDB) ChangedKeysBetween(startRootID, endRootID ids.ID) ([][]byte, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	for _, rootID := range []ids.ID{startRootID, endRootID} {
+		if _, ok := db.history.lastChanges[rootID]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrRootNotInHistory, rootID)
+		}
+	}
+	if startRootID == endRootID {
+		return nil, nil
+	}
+
+	changes, err := db.history.getValueChanges(
+		startRootID,
+		endRootID,
+		maybe.Nothing[[]byte](),
+		maybe.Nothing[[]byte](),
+		math.Max(db.history.numValueChanges(), 1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	changedKeys := make([]path, 0, len(changes.values))
+	for key, valueChange := range changes.values {
+		// Skip changes that set a key to its existing value.
+		if valueChange.before.HasValue() == valueChange.after.HasValue() &&
+			bytes.Equal(valueChange.before.Value(), valueChange.after.Value()) {
+			continue
+		}
+		changedKeys = append(changedKeys, key)
+	}
+	utils.Sort(changedKeys)
+
+	keys := make([][]byte, len(changedKeys))
+	for i, key := range changedKeys {
+		keys[i] = key.Serialize().Value
+	}
+	return keys, nil
+}
+
 // NewView returns a new view on top of this trie.
 // Changes made to the view will only be reflected in the original trie if Commit is called.
 // Assumes [db.commitLock] and [db.lock] aren't held.
 func (db *merkleDB) NewView(_ context.Context, batchOps []database.BatchOp) (TrieView, error) {
+	if err := db.validateOps(batchOps); err != nil {
+		return nil, err
+	}
+
 	// ensure the db doesn't change while creating the new view
 	db.commitLock.RLock()
 	defer db.commitLock.RUnlock()
@@ -691,6 +2565,34 @@ func (db *merkleDB) newUntrackedView(batchOps []database.BatchOp) (*trieView, er
 	return newView, nil
 }
 
+// validatePut returns an error if [value] isn't allowed to be put at [key].
+// Only the key/value pairs provided by users are validated. Key/value pairs
+// from proofs or already in the database aren't, so that changing the limits
+// doesn't prevent existing data from being synced or rebuilt.
+func (db *merkleDB) validatePut(key, value []byte) error {
+	if db.maxValueLen > 0 && len(value) > db.maxValueLen {
+		return fmt.Errorf("%w: %d > %d", ErrValueTooLarge, len(value), db.maxValueLen)
+	}
+	if db.keyValidator != nil {
+		return db.keyValidator(key)
+	}
+	return nil
+}
+
+// validateOps returns an error if any of the puts in [ops] is invalid.
+// See [validatePut].
+func (db *merkleDB) validateOps(ops []database.BatchOp) error {
+	for _, op := range ops {
+		if op.Delete {
+			continue
+		}
+		if err := db.validatePut(op.Key, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (db *merkleDB) Has(k []byte) (bool, error) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -713,6 +2615,9 @@ func (db *merkleDB) HealthCheck(ctx context.Context) (interface{}, error) {
 	if db.closed {
 		return nil, database.ErrClosed
 	}
+	if db.flushErr != nil {
+		return nil, fmt.Errorf("%w: %s", errFlushFailed, db.flushErr)
+	}
 	return db.nodeDB.HealthCheck(ctx)
 }
 
@@ -723,6 +2628,7 @@ func (db *merkleDB) NewBatch() database.Batch {
 }
 
 func (db *merkleDB) NewIterator() database.Iterator {
+	db.openIterators.Add(1)
 	return &iterator{
 		nodeIter: db.nodeDB.NewIterator(),
 		db:       db,
@@ -730,81 +2636,417 @@ func (db *merkleDB) NewIterator() database.Iterator {
 }
 
 func (db *merkleDB) NewIteratorWithStart(start []byte) database.Iterator {
+	db.openIterators.Add(1)
 	return &iterator{
 		nodeIter: db.nodeDB.NewIteratorWithStart(newPath(start).Bytes()),
 		db:       db,
 	}
-}
+}
+
+func (db *merkleDB) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	db.openIterators.Add(1)
+	return &iterator{
+		nodeIter: db.nodeDB.NewIteratorWithPrefix(newPath(prefix).Bytes()),
+		db:       db,
+	}
+}
+
+func (db *merkleDB) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	startBytes := newPath(start).Bytes()
+	prefixBytes := newPath(prefix).Bytes()
+	db.openIterators.Add(1)
+	return &iterator{
+		nodeIter: db.nodeDB.NewIteratorWithStartAndPrefix(startBytes, prefixBytes),
+		db:       db,
+	}
+}
+
+func (db *merkleDB) NewKeyIterator(start, prefix []byte) database.Iterator {
+	startBytes := newPath(start).Bytes()
+	prefixBytes := newPath(prefix).Bytes()
+	return &keyIterator{
+		nodeIter: db.nodeDB.NewIteratorWithStartAndPrefix(startBytes, prefixBytes),
+		db:       db,
+	}
+}
+
+// Note that the key/value pairs of the returned iterator are read into memory
+// when it is created.
+func (db *merkleDB) NewIteratorAtRoot(rootID ids.ID, start, prefix []byte) (database.Iterator, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	historicalView, err := db.getHistoricalViewForRange(rootID, maybe.Some(start), maybe.Nothing[[]byte]())
+	if errors.Is(err, ErrInsufficientHistory) {
+		return nil, fmt.Errorf("%w: %s", ErrRootNotInHistory, rootID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Since we hold [db.commitLock], the trie can't change while the key/value
+	// pairs are being read.
+	it := historicalView.NewIteratorWithStartAndPrefix(start, prefix)
+	defer it.Release()
+
+	var keyValues []KeyValue
+	for it.Next() {
+		keyValues = append(keyValues, KeyValue{
+			Key:   it.Key(),
+			Value: slices.Clone(it.Value()),
+		})
+	}
+	return &keyValueIterator{
+		keyValues: keyValues,
+	}, it.Error()
+}
+
+// If [node] is an intermediary node, puts it in [nodeDB].
+// Note this is called by [db.nodeCache] with its lock held, so
+// the movement of [node] from [db.nodeCache] to [db.nodeDB] is atomic.
+// As soon as [db.nodeCache] no longer has [node], [db.nodeDB] does.
+// Non-nil error is fatal -- causes [db] to close.
+func (db *merkleDB) onEviction(key path, n *node) error {
+	var evictedKeys []path
+	if db.onEvict != nil {
+		evictedKeys = []path{key}
+		defer func() {
+			db.notifyEvicted(evictedKeys)
+		}()
+	}
+
+	// the evicted node isn't an intermediary node, so skip writing.
+	if n == nil || n.hasValue() {
+		return nil
+	}
+
+	batch := db.nodeDB.NewBatch()
+	if err := db.writeNodeToBatch(batch, n); err != nil {
+		return err
+	}
+
+	evictionBatchSize := db.evictionBatchSize
+	if db.adaptiveEviction != nil {
+		evictionBatchSize = db.adaptiveEviction.current
+	}
+
+	// Evict the oldest [evictionBatchSize] nodes from the cache
+	// and write them to disk. We write a batch of them, rather than
+	// just [n], so that we don't immediately evict and write another
+	// node, because each time this method is called we do a disk write.
+	var err error
+	for removedCount := 0; removedCount < evictionBatchSize; removedCount++ {
+		key, n, exists := db.nodeCache.removeOldest()
+		if !exists {
+			// The cache is empty.
+			break
+		}
+		if db.onEvict != nil {
+			evictedKeys = append(evictedKeys, key)
+		}
+		if n == nil || n.hasValue() {
+			// only persist intermediary nodes
+			continue
+		}
+		// Note this must be = not := since we check
+		// [err] outside the loop.
+		if err = db.writeNodeToBatch(batch, n); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		startTime := time.Now()
+		err = batch.Write()
+		if db.adaptiveEviction != nil {
+			newBatchSize := db.adaptiveEviction.update(time.Since(startTime))
+			db.metrics.SetEvictionBatchSize(newBatchSize)
+		}
+	}
+	if err != nil {
+		db.onEvictionErr.Set(err)
+		_ = db.nodeDB.Close()
+		go db.Close()
+		return err
+	}
+	return nil
+}
+
+// Queues [keys] to be passed to [db.onEvict] by [dispatchEvicted], so that
+// it's never called while holding any of [db]'s locks.
+// If the queue is full, [keys] are dropped rather than blocking eviction.
+// Assumes [db.onEvict] is non-nil.
+func (db *merkleDB) notifyEvicted(keys []path) {
+	select {
+	case db.onEvictQueue <- keys:
+	default:
+		db.metrics.EvictNotificationsDropped(len(keys))
+	}
+}
+
+// Calls [db.onEvict] with each queued evicted key, in the order they were
+// evicted, until [db] is closed.
+func (db *merkleDB) dispatchEvicted() {
+	for {
+		select {
+		case keys := <-db.onEvictQueue:
+			for _, key := range keys {
+				db.onEvict(key.Serialize())
+			}
+		case <-db.stopOnEvict:
+			return
+		}
+	}
+}
+
+// Writes [n] to [batch]. Assumes [n] is non-nil.
+// If [db.valueDB] is non-nil, [n]'s value must have been written with
+// [writeValueToBatch].
+func (db *merkleDB) writeNodeToBatch(batch database.Batch, n *node) error {
+	return batch.Put(n.key.Bytes(), db.marshalNode(n))
+}
+
+// Writes [n]'s value to [batch] if it's stored separately from [n].
+// Assumes [n] is non-nil and [db.valueDB] is non-nil.
+func writeValueToBatch(batch database.Batch, n *node) error {
+	if !storesValueSeparately(n.value) {
+		return nil
+	}
+	return batch.Put(n.valueDigest.Value(), n.value.Value())
+}
+
+// Adds [delta] to the reference count of [n]'s value in [deltas] if it's
+// stored separately from [n].
+func addValueRefDelta(deltas map[string]int, n *node, delta int) {
+	if n == nil || !storesValueSeparately(n.value) {
+		return
+	}
+	deltas[string(n.valueDigest.Value())] += delta
+}
+
+// Writes the reference counts of the values whose counts change by [deltas]
+// to [batch] and returns them. Values that are no longer referenced have their
+// count deleted.
+// Assumes [db.lock] is held and [db.valueRefDB] is non-nil.
+func (db *merkleDB) writeValueRefCounts(batch database.Batch, deltas map[string]int) (map[string]uint64, error) {
+	counts := make(map[string]uint64, len(deltas))
+	for valueHash, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+
+		count, err := database.GetUInt64(db.valueRefDB, []byte(valueHash))
+		if err != nil && err != database.ErrNotFound {
+			return nil, err
+		}
+		if delta < 0 && uint64(-delta) > count {
+			return nil, fmt.Errorf("%w: %x", errNegativeRefCount, valueHash)
+		}
+		count = uint64(int64(count) + int64(delta))
+		counts[valueHash] = count
+
+		if count == 0 {
+			err = batch.Delete([]byte(valueHash))
+		} else {
+			err = database.PutUInt64(batch, []byte(valueHash), count)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return counts, nil
+}
+
+// Records which values are unreferenced once [counts], as returned by
+// [writeValueRefCounts], have been written along with the nodes that changed
+// them.
+// Assumes [db.lock] is held.
+func (db *merkleDB) recordValueRefCounts(counts map[string]uint64) {
+	for valueHash, count := range counts {
+		if count == 0 {
+			db.unreferencedValues.Add(valueHash)
+		} else {
+			db.unreferencedValues.Remove(valueHash)
+		}
+	}
+}
+
+// Deletes the values in [db.unreferencedValues] from [db.valueDB].
+// Values are only deleted once the nodes that referenced them have been
+// overwritten on disk so that a node on disk never references a missing
+// value. If any iterator is open, the values are kept until a later call
+// since the iterator may still parse the nodes that referenced them.
+// Assumes [db.lock] is held.
+func (db *merkleDB) deleteUnreferencedValues() error {
+	if db.unreferencedValues.Len() == 0 || db.openIterators.Load() != 0 {
+		return nil
+	}
+	batch := db.valueDB.NewBatch()
+	for valueHash := range db.unreferencedValues {
+		if err := batch.Delete([]byte(valueHash)); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	db.unreferencedValues.Clear()
+	return nil
+}
+
+// Recalculates the reference count of each value in [db.valueDB] from the
+// nodes in [db.nodeDB], and deletes the values that no node references.
+// Assumes [db.valueDB] and [db.valueRefDB] are non-nil and the nodes that
+// reference values have been written to [db.nodeDB].
+func (db *merkleDB) recountValueRefs() error {
+	counts := make(map[string]uint64)
+	nodeIt := db.nodeDB.NewIterator()
+	defer nodeIt.Release()
+	for nodeIt.Next() {
+		// [parseNode] doesn't read values that are stored separately, so
+		// their hash is the node's value.
+		n, err := parseNode(path(nodeIt.Key()), nodeIt.Value())
+		if err != nil {
+			return err
+		}
+		if storesValueSeparately(n.value) {
+			counts[string(n.value.Value())]++
+		}
+	}
+	if err := nodeIt.Error(); err != nil {
+		return err
+	}
+
+	refBatch := db.valueRefDB.NewBatch()
+	if err := database.Clear(db.valueRefDB, refBatch); err != nil {
+		return err
+	}
+	for valueHash, count := range counts {
+		if err := database.PutUInt64(refBatch, []byte(valueHash), count); err != nil {
+			return err
+		}
+	}
+	if err := refBatch.Write(); err != nil {
+		return err
+	}
 
-func (db *merkleDB) NewIteratorWithPrefix(prefix []byte) database.Iterator {
-	return &iterator{
-		nodeIter: db.nodeDB.NewIteratorWithPrefix(newPath(prefix).Bytes()),
-		db:       db,
+	valueBatch := db.valueDB.NewBatch()
+	valueIt := db.valueDB.NewIterator()
+	defer valueIt.Release()
+	for valueIt.Next() {
+		if _, ok := counts[string(valueIt.Key())]; ok {
+			continue
+		}
+		if err := valueBatch.Delete(slices.Clone(valueIt.Key())); err != nil {
+			return err
+		}
+	}
+	if err := valueIt.Error(); err != nil {
+		return err
+	}
+	if err := valueBatch.Write(); err != nil {
+		return err
 	}
+	db.unreferencedValues.Clear()
+	return nil
 }
 
-func (db *merkleDB) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
-	startBytes := newPath(start).Bytes()
-	prefixBytes := newPath(prefix).Bytes()
-	return &iterator{
-		nodeIter: db.nodeDB.NewIteratorWithStartAndPrefix(startBytes, prefixBytes),
-		db:       db,
+// Returns [ErrValueStoreMismatch] if [db]'s value store layout doesn't match
+// the one it was created with, and records the layout if it wasn't recorded.
+// [baseDB] is the database [db] is stored in.
+func (db *merkleDB) checkValueStoreLayout(baseDB database.Database) error {
+	layout := inlineValuesLayout
+	if db.valueDB != nil {
+		layout = separateValuesLayout
 	}
-}
 
-// If [node] is an intermediary node, puts it in [nodeDB].
-// Note this is called by [db.nodeCache] with its lock held, so
-// the movement of [node] from [db.nodeCache] to [db.nodeDB] is atomic.
-// As soon as [db.nodeCache] no longer has [node], [db.nodeDB] does.
-// Non-nil error is fatal -- causes [db] to close.
-func (db *merkleDB) onEviction(n *node) error {
-	// the evicted node isn't an intermediary node, so skip writing.
-	if n == nil || n.hasValue() {
+	storedLayout, err := db.metadataDB.Get(valueStoreLayoutKey)
+	switch err {
+	case nil:
+		if !bytes.Equal(storedLayout, layout) {
+			return fmt.Errorf("%w: SeparateValueStore is %t", ErrValueStoreMismatch, db.valueDB != nil)
+		}
 		return nil
+	case database.ErrNotFound:
+	default:
+		return err
 	}
 
-	batch := db.nodeDB.NewBatch()
-	if err := writeNodeToBatch(batch, n); err != nil {
+	// The layout is recorded when the database is created, unless it was
+	// created before the layout was recorded. Such a database stored values
+	// separately iff it has any separately stored values, since the layouts
+	// are the same otherwise. It didn't count the references to them either.
+	noSeparateValues, err := database.IsEmpty(prefixdb.New(valuePrefix, baseDB))
+	if err != nil {
 		return err
 	}
-
-	// Evict the oldest [evictionBatchSize] nodes from the cache
-	// and write them to disk. We write a batch of them, rather than
-	// just [n], so that we don't immediately evict and write another
-	// node, because each time this method is called we do a disk write.
-	var err error
-	for removedCount := 0; removedCount < db.evictionBatchSize; removedCount++ {
-		_, n, exists := db.nodeCache.removeOldest()
-		if !exists {
-			// The cache is empty.
-			break
-		}
-		if n == nil || n.hasValue() {
-			// only persist intermediary nodes
-			continue
+	if !noSeparateValues {
+		if db.valueDB == nil {
+			return fmt.Errorf("%w: SeparateValueStore is false", ErrValueStoreMismatch)
 		}
-		// Note this must be = not := since we check
-		// [err] outside the loop.
-		if err = writeNodeToBatch(batch, n); err != nil {
-			break
+		if err := db.recountValueRefs(); err != nil {
+			return err
 		}
 	}
-	if err == nil {
-		err = batch.Write()
+	return db.metadataDB.Put(valueStoreLayoutKey, layout)
+}
+
+// Returns the bytes of [n] as stored in [db.nodeDB].
+func (db *merkleDB) marshalNode(n *node) []byte {
+	if db.valueDB == nil {
+		return n.marshal()
+	}
+	// Values that are at least [HashLength] bytes long are replaced by their
+	// hash. Shorter values are their own digest.
+	return codec.encodeDBNode(&dbNode{
+		value:    n.valueDigest,
+		children: n.children,
+	})
+}
+
+// Returns the bytes of [n] as stored in [db.nodeDB].
+// Unlike [db.marshalNode], the bytes aren't cached in [n], so this is safe to
+// call while only holding a read lock.
+func (db *merkleDB) encodeNode(n *node) []byte {
+	nodeValue := n.value
+	if db.valueDB != nil {
+		nodeValue = n.valueDigest
+	}
+	return codec.encodeDBNode(&dbNode{
+		value:    nodeValue,
+		children: n.children,
+	})
+}
+
+// Parses [nodeBytes], as stored in [db.nodeDB], to a node with key [key].
+// If [db.valueDB] is non-nil and the node's value is stored separately, the
+// value is read from [db.valueDB].
+func (db *merkleDB) parseNode(key path, nodeBytes []byte) (*node, error) {
+	n, err := parseNode(key, nodeBytes)
+	if err != nil || db.valueDB == nil || !storesValueSeparately(n.value) {
+		return n, err
 	}
+
+	// [n.value] is the hash of the value.
+	valueHash := n.value.Value()
+	value, err := db.valueDB.Get(valueHash)
 	if err != nil {
-		db.onEvictionErr.Set(err)
-		_ = db.nodeDB.Close()
-		go db.Close()
-		return err
+		return nil, fmt.Errorf("failed to get value of node %x: %w", key.Bytes(), err)
 	}
-	return nil
+	n.value = maybe.Some(value)
+	n.valueDigest = maybe.Some(valueHash)
+	// [nodeBytes] doesn't contain the value.
+	n.nodeBytes = nil
+	return n, nil
 }
 
-// Writes [n] to [batch]. Assumes [n] is non-nil.
-func writeNodeToBatch(batch database.Batch, n *node) error {
-	nodeBytes := n.marshal()
-	return batch.Put(n.key.Bytes(), nodeBytes)
+// Returns true iff [value] is stored separately from its node when
+// [Config.SeparateValueStore] is true.
+func storesValueSeparately(value maybe.Maybe[[]byte]) bool {
+	return value.HasValue() && len(value.Value()) >= HashLength
 }
 
 func (db *merkleDB) Put(k, v []byte) error {
@@ -819,6 +3061,9 @@ func (db *merkleDB) PutContext(ctx context.Context, k, v []byte) error {
 	if db.closed {
 		return database.ErrClosed
 	}
+	if err := db.validatePut(k, v); err != nil {
+		return err
+	}
 
 	view, err := db.newUntrackedView([]database.BatchOp{
 		{
@@ -863,6 +3108,9 @@ func (db *merkleDB) commitBatch(ops []database.BatchOp) error {
 	if db.closed {
 		return database.ErrClosed
 	}
+	if err := db.validateOps(ops); err != nil {
+		return err
+	}
 
 	view, err := db.newUntrackedView(ops)
 	if err != nil {
@@ -871,9 +3119,34 @@ func (db *merkleDB) commitBatch(ops []database.BatchOp) error {
 	return view.commitToDB(context.Background())
 }
 
+// commitOptions configures a commit. The zero value is a plain commit.
+type commitOptions struct {
+	// If non-nil, called with the number of node changes written so far and
+	// the total number of node changes while [db.lock] is held, so it must
+	// not block.
+	progress func(nodesFlushed, totalNodes int)
+	// If non-zero, the commit is abandoned with [ErrCommitDeadlineExceeded]
+	// if the changes haven't started being written to disk by [deadline].
+	deadline time.Time
+}
+
+// deadlineExceeded returns a non-nil error iff [opts.deadline] has passed.
+func (opts commitOptions) deadlineExceeded() error {
+	if opts.deadline.IsZero() || time.Now().Before(opts.deadline) {
+		return nil
+	}
+	return fmt.Errorf("%w: deadline was %s", ErrCommitDeadlineExceeded, opts.deadline)
+}
+
 // commitChanges commits the changes in [trieToCommit] to [db].
+// If the commit is abandoned because of [opts.deadline], nothing is written
+// and neither [db] nor its views are modified.
 // Assumes [trieToCommit]'s node IDs have been calculated.
-func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) error {
+func (db *merkleDB) commitChanges(
+	ctx context.Context,
+	trieToCommit *trieView,
+	opts commitOptions,
+) error {
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
@@ -890,6 +3163,12 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 		return ErrParentNotDatabase
 	}
 
+	// The caller calculates the node IDs before [db.lock] is taken because
+	// calculating them may read from [db]. This only checks that it did.
+	if _, err := trieToCommit.getMerkleRoot(); err != nil {
+		return err
+	}
+
 	changes := trieToCommit.changes
 	_, span := db.tracer.Start(ctx, "MerkleDB.commitChanges", oteltrace.WithAttributes(
 		attribute.Int("nodesChanged", len(changes.nodes)),
@@ -897,13 +3176,9 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 	))
 	defer span.End()
 
-	// invalidate all child views except for the view being committed
-	db.invalidateChildrenExcept(trieToCommit)
-
-	// move any child views of the committed trie onto the db
-	db.moveChildViewsToDB(trieToCommit)
-
 	if len(changes.nodes) == 0 {
+		db.updateChildViews(trieToCommit)
+		db.recordCommitStats(changes)
 		return nil
 	}
 
@@ -913,11 +3188,40 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 	}
 
 	batch := db.nodeDB.NewBatch()
+	// Values are written before the nodes that reference them so that a
+	// node on disk never references a missing value.
+	var (
+		valueBatch     database.Batch
+		valueRefDeltas map[string]int
+	)
+	if db.valueDB != nil {
+		valueBatch = db.valueDB.NewBatch()
+		valueRefDeltas = make(map[string]int)
+	}
 
+	var (
+		totalNodes   = len(changes.nodes)
+		nodesFlushed int
+	)
 	_, nodesSpan := db.tracer.Start(ctx, "MerkleDB.commitChanges.writeNodes")
 	for key, nodeChange := range changes.nodes {
+		if nodesFlushed > 0 && nodesFlushed%commitProgressInterval == 0 {
+			if err := opts.deadlineExceeded(); err != nil {
+				nodesSpan.End()
+				return err
+			}
+			// The last report is made once the batch has been written.
+			if opts.progress != nil {
+				opts.progress(nodesFlushed, totalNodes)
+			}
+		}
+		nodesFlushed++
+
 		if nodeChange.after == nil {
 			db.metrics.IOKeyWrite()
+			if valueBatch != nil {
+				addValueRefDelta(valueRefDeltas, nodeChange.before, -1)
+			}
 			if err := batch.Delete(key.Bytes()); err != nil {
 				nodesSpan.End()
 				return err
@@ -930,7 +3234,15 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 			// Otherwise, intermediary nodes are persisted on cache eviction or
 			// shutdown.
 			db.metrics.IOKeyWrite()
-			if err := writeNodeToBatch(batch, nodeChange.after); err != nil {
+			if valueBatch != nil {
+				if err := writeValueToBatch(valueBatch, nodeChange.after); err != nil {
+					nodesSpan.End()
+					return err
+				}
+				addValueRefDelta(valueRefDeltas, nodeChange.before, -1)
+				addValueRefDelta(valueRefDeltas, nodeChange.after, 1)
+			}
+			if err := db.writeNodeToBatch(batch, nodeChange.after); err != nil {
 				nodesSpan.End()
 				return err
 			}
@@ -938,16 +3250,69 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 	}
 	nodesSpan.End()
 
+	var (
+		valueRefBatch  database.Batch
+		valueRefCounts map[string]uint64
+	)
+	if valueBatch != nil {
+		valueRefBatch = db.valueRefDB.NewBatch()
+		var err error
+		valueRefCounts, err = db.writeValueRefCounts(valueRefBatch, valueRefDeltas)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.interceptCommit(commitStageWriteBatch); err != nil {
+		return err
+	}
+	// The batches are written atomically and can't be interrupted, so this is
+	// the last point at which the commit can be abandoned.
+	if err := opts.deadlineExceeded(); err != nil {
+		return err
+	}
+
 	_, commitSpan := db.tracer.Start(ctx, "MerkleDB.commitChanges.dbCommit")
+	if valueBatch != nil {
+		if err := valueBatch.Write(); err != nil {
+			commitSpan.End()
+			return err
+		}
+		if err := valueRefBatch.Write(); err != nil {
+			commitSpan.End()
+			return err
+		}
+	}
 	err := batch.Write()
 	commitSpan.End()
 	if err != nil {
 		return err
 	}
+	db.recordValueRefCounts(valueRefCounts)
+	if opts.progress != nil {
+		opts.progress(totalNodes, totalNodes)
+	}
+
+	if err := db.interceptCommit(commitStageUpdateMemory); err != nil {
+		return err
+	}
 
 	// Only modify in-memory state after the commit succeeds
 	// so that we don't need to clean up on error.
+	db.updateChildViews(trieToCommit)
 	db.root = rootChange.after
+	db.cachedRootID.Set(db.root.id)
+
+	if db.keyCountKnown {
+		for _, valueChange := range changes.values {
+			switch {
+			case valueChange.before.IsNothing() && valueChange.after.HasValue():
+				db.keyCount++
+			case valueChange.before.HasValue() && valueChange.after.IsNothing():
+				db.keyCount--
+			}
+		}
+	}
 
 	for key, nodeChange := range changes.nodes {
 		if err := db.nodeCache.Put(key, nodeChange.after); err != nil {
@@ -955,10 +3320,215 @@ func (db *merkleDB) commitChanges(ctx context.Context, trieToCommit *trieView) e
 		}
 	}
 
+	db.recordCommitStats(changes)
 	db.history.record(changes)
+
+	// If the nodes are buffered, the unreferenced values are deleted once the
+	// nodes are written to disk. The commit has succeeded either way, so if
+	// the values can't be deleted they're retried after the next commit.
+	if !db.batchingCommits && len(db.writeBackDBs) == 0 {
+		_ = db.deleteUnreferencedValues()
+	}
 	return nil
 }
 
+// recordCommitStats records the number of keys and nodes changed by the
+// commit of [changes].
+// Assumes [db.lock] is held.
+func (db *merkleDB) recordCommitStats(changes *changeSummary) {
+	db.lastCommitChangedKeys = len(changes.values)
+	db.lastCommitNodesWritten = len(changes.nodes)
+	db.metrics.NodesWrittenPerCommit(db.lastCommitNodesWritten)
+}
+
+func (db *merkleDB) LastCommitStats() (changedKeys, nodesWritten int) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	return db.lastCommitChangedKeys, db.lastCommitNodesWritten
+}
+
+func (db *merkleDB) Pin(keys [][]byte) error {
+	// [db.lock] is held so that a commit can't replace a node between it
+	// being read and it being put into the cache below.
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	paths := make([]path, len(keys))
+	for i, key := range keys {
+		paths[i] = newPath(key)
+	}
+	if err := db.nodeCache.Pin(paths); err != nil {
+		return err
+	}
+
+	// Load the pinned nodes that aren't in the cache yet.
+	for _, key := range paths {
+		if _, err := db.getNode(key); err != nil && err != database.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *merkleDB) Unpin(keys [][]byte) {
+	paths := make([]path, len(keys))
+	for i, key := range keys {
+		paths[i] = newPath(key)
+	}
+	// Eviction errors are fatal and close the database, so they're reported
+	// by subsequent operations.
+	_ = db.nodeCache.Unpin(paths)
+}
+
+// updateChildViews invalidates the child views of [db] other than
+// [committedTrie] and moves the child views of [committedTrie] onto [db].
+// Assumes [db.lock] is held.
+func (db *merkleDB) updateChildViews(committedTrie *trieView) {
+	// invalidate all child views except for the view being committed
+	db.invalidateChildrenExcept(committedTrie)
+	db.commitCount++
+
+	// move any child views of the committed trie onto the db
+	db.moveChildViewsToDB(committedTrie)
+}
+
+func (db *merkleDB) VerifyIntegrity(ctx context.Context) error {
+	ctx, span := db.tracer.Start(ctx, "MerkleDB.VerifyIntegrity")
+	defer span.End()
+
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	if err := db.verifyTrie(ctx); err != nil {
+		return err
+	}
+	// The trie may be self-consistent but missing key/value pairs on disk,
+	// so the root is also recalculated from the key/value pairs.
+	return verifyRoot(ctx, db)
+}
+
+// verifyTrie recalculates the ID of every node in the trie from its
+// descendants and returns an error if it doesn't match the stored ID.
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) verifyTrie(ctx context.Context) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	rootID, err := db.verifySubtrie(ctx, db.root)
+	if err != nil {
+		return err
+	}
+	if rootID != db.root.id {
+		return fmt.Errorf("%w: root %s, recalculated root %s", errNodeIDMismatch, db.root.id, rootID)
+	}
+	return nil
+}
+
+// verifySubtrie returns the ID of [n] recalculated from its descendants.
+// Returns an error if a descendant's recalculated ID doesn't match the ID
+// stored in its parent or if a descendant is missing.
+// Assumes [db.lock] is read locked.
+func (db *merkleDB) verifySubtrie(ctx context.Context, n *node) (ids.ID, error) {
+	if err := ctx.Err(); err != nil {
+		return ids.Empty, err
+	}
+
+	recalculated := n.clone()
+	for index, entry := range n.children {
+		childKey := n.key + path(index) + entry.compressedPath
+		childNode, err := db.getNode(childKey)
+		if err == database.ErrNotFound {
+			return ids.Empty, fmt.Errorf("%w: %x", errDanglingChild, childKey.Serialize().Value)
+		}
+		if err != nil {
+			return ids.Empty, err
+		}
+
+		childID, err := db.verifySubtrie(ctx, childNode)
+		if err != nil {
+			return ids.Empty, err
+		}
+		if childID != entry.id {
+			return ids.Empty, fmt.Errorf(
+				"%w: node %x, stored %s, recalculated %s",
+				errNodeIDMismatch,
+				childKey.Serialize().Value,
+				entry.id,
+				childID,
+			)
+		}
+		recalculated.addChildWithoutNode(index, entry.compressedPath, childID)
+	}
+
+	recalculated.setValue(n.value)
+	if err := recalculated.calculateID(db.metrics); err != nil {
+		return ids.Empty, err
+	}
+	return recalculated.id, nil
+}
+
+// verifyRoot recalculates the root from every key/value pair in [trie] and
+// returns an error if it doesn't match the root of [trie].
+// Assumes the commitLock of [trie]'s database is held.
+func verifyRoot(ctx context.Context, trie ReadOnlyTrie) error {
+	root, err := trie.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	it := trie.NewIterator()
+	defer it.Release()
+
+	return verifyRootOf(ctx, root, it, nil)
+}
+
+// verifyRootOf recalculates the root from the key/value pairs of [it],
+// overwritten by [ops], and returns an error if it doesn't match [root].
+func verifyRootOf(ctx context.Context, root ids.ID, it database.Iterator, ops []database.BatchOp) error {
+	var allOps []database.BatchOp
+	for it.Next() {
+		allOps = append(allOps, database.BatchOp{
+			Key:   it.Key(),
+			Value: it.Value(),
+		})
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	allOps = append(allOps, ops...)
+
+	view, err := getStandaloneTrieView(ctx, allOps)
+	if err != nil {
+		return err
+	}
+	recalculatedRoot, err := view.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	if root != recalculatedRoot {
+		return fmt.Errorf("%w: root %s, recalculated root %s", errRootMismatch, root, recalculatedRoot)
+	}
+	return nil
+}
+
+// interceptCommit calls [db.commitInterceptor], if set, with [stage].
+func (db *merkleDB) interceptCommit(stage string) error {
+	if db.commitInterceptor == nil {
+		return nil
+	}
+	return db.commitInterceptor(stage)
+}
+
 // moveChildViewsToDB removes any child views from the trieToCommit and moves them to the db
 // assumes [db.lock] is held
 func (db *merkleDB) moveChildViewsToDB(trieToCommit *trieView) {
@@ -978,6 +3548,18 @@ func (*merkleDB) CommitToDB(context.Context) error {
 	return nil
 }
 
+// CommitToDBWithProgress is a no-op for db since it is already in sync with
+// itself. This exists to satisfy the TrieView interface.
+func (*merkleDB) CommitToDBWithProgress(context.Context, func(int, int)) error {
+	return nil
+}
+
+// CommitToDBWithDeadline is a no-op for db since it is already in sync with
+// itself. This exists to satisfy the TrieView interface.
+func (*merkleDB) CommitToDBWithDeadline(context.Context, time.Time) error {
+	return nil
+}
+
 // This is defined on merkleDB instead of ChangeProof
 // because it accesses database internals.
 // Assumes [db.lock] isn't held.
@@ -1140,7 +3722,7 @@ func (db *merkleDB) initializeRootIfNeeded() (ids.ID, error) {
 	nodeBytes, err := db.nodeDB.Get(rootKey)
 	if err == nil {
 		// Root already exists, so parse it and set the in-mem copy
-		db.root, err = parseNode(RootPath, nodeBytes)
+		db.root, err = db.parseNode(RootPath, nodeBytes)
 		if err != nil {
 			return ids.Empty, err
 		}
@@ -1163,7 +3745,7 @@ func (db *merkleDB) initializeRootIfNeeded() (ids.ID, error) {
 
 	// write the newly constructed root to the DB
 	batch := db.nodeDB.NewBatch()
-	rootBytes := db.root.marshal()
+	rootBytes := db.marshalNode(db.root)
 	if err := batch.Put(rootKey, rootBytes); err != nil {
 		return ids.Empty, err
 	}
@@ -1249,23 +3831,27 @@ func (db *merkleDB) getNode(key path) (*node, error) {
 		if n == nil {
 			return nil, database.ErrNotFound
 		}
+		db.metrics.DBNodeCacheHitDepth(len(key))
 		return n, nil
 	}
 
 	db.metrics.DBNodeCacheMiss()
 	db.metrics.IOKeyRead()
-	rawBytes, err := db.nodeDB.Get(key.Bytes())
+	rawBytes, err := db.readNode(key)
 	if err != nil {
 		if err == database.ErrNotFound {
 			// Cache the miss.
 			if err := db.nodeCache.Put(key, nil); err != nil {
 				return nil, err
 			}
+			// Missing nodes are expected, so callers compare against
+			// [database.ErrNotFound] directly.
+			return nil, err
 		}
-		return nil, err
+		return nil, fmt.Errorf("failed to load node at path 0x%s (cache miss): %w", key.hex(), err)
 	}
 
-	node, err := parseNode(key, rawBytes)
+	node, err := db.parseNode(key, rawBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -1273,3 +3859,40 @@ func (db *merkleDB) getNode(key path) (*node, error) {
 	err = db.nodeCache.Put(key, node)
 	return node, err
 }
+
+// keyValuesSize returns the total number of key and value bytes in [keyValues].
+func keyValuesSize(keyValues []KeyValue) int {
+	size := 0
+	for _, kv := range keyValues {
+		size += len(kv.Key) + len(kv.Value)
+	}
+	return size
+}
+
+// readNode returns the bytes of the node with the given [key] from
+// [db.nodeDB], retrying failed reads according to [db.readRetry].
+// This may be called with [db.lock] held, so the total time spent waiting to
+// retry is bounded by [db.readRetry.MaxWait].
+func (db *merkleDB) readNode(key path) ([]byte, error) {
+	var (
+		backoff = db.readRetry.InitialBackoff
+		waited  time.Duration
+	)
+	for attempt := 1; ; attempt++ {
+		rawBytes, err := db.nodeDB.Get(key.Bytes())
+		if err == nil ||
+			err == database.ErrNotFound ||
+			err == database.ErrClosed ||
+			attempt >= db.readRetry.MaxAttempts {
+			return rawBytes, err
+		}
+
+		backoff = math.Min(backoff, db.readRetry.MaxBackoff)
+		if waited+backoff > db.readRetry.MaxWait {
+			return rawBytes, err
+		}
+		time.Sleep(backoff)
+		waited += backoff
+		backoff *= 2
+	}
+}
@@ -6,20 +6,29 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"math/rand"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
 	"github.com/ava-labs/avalanchego/utils/units"
 )
 
@@ -66,6 +75,57 @@ func Test_MerkleDB_Get_Safety(t *testing.T) {
 	require.NotEqual(val, n.value.Value())
 }
 
+func Test_MerkleDB_GetUnsafe(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		require.NoError(batch.Put(key, []byte(fmt.Sprintf("value%d", i))))
+	}
+	require.NoError(batch.Put(nil, []byte("empty key")))
+	require.NoError(batch.Write())
+	require.NoError(db.Delete([]byte("key0")))
+
+	requireValues := func() {
+		for i := 1; i < 100; i++ {
+			value, err := db.GetUnsafe([]byte(fmt.Sprintf("key%d", i)))
+			require.NoError(err)
+			require.Equal([]byte(fmt.Sprintf("value%d", i)), value)
+		}
+		value, err := db.GetUnsafe(nil)
+		require.NoError(err)
+		require.Equal([]byte("empty key"), value)
+
+		_, err = db.GetUnsafe([]byte("key0"))
+		require.ErrorIs(err, database.ErrNotFound)
+		_, err = db.GetUnsafe([]byte("key"))
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+	requireValues()
+
+	// The value isn't copied.
+	value, err := db.GetUnsafe([]byte("key1"))
+	require.NoError(err)
+	n, err := db.getNode(newPath([]byte("key1")))
+	require.NoError(err)
+	require.Same(&n.value.Value()[0], &value[0])
+
+	// Values are read from disk when their nodes aren't cached.
+	require.NoError(db.Close())
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	requireValues()
+
+	require.NoError(db.Close())
+	_, err = db.GetUnsafe([]byte("key1"))
+	require.ErrorIs(err, database.ErrClosed)
+}
+
 func Test_MerkleDB_GetValues_Safety(t *testing.T) {
 	require := require.New(t)
 
@@ -86,6 +146,360 @@ func Test_MerkleDB_GetValues_Safety(t *testing.T) {
 	require.Equal([]byte{0, 1, 2}, vals[0])
 }
 
+func Test_MerkleDB_GetMap(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0}, []byte{0, 1, 2}))
+	require.NoError(db.Put([]byte{1}, []byte{}))
+	require.NoError(db.Put([]byte{2}, []byte{2}))
+
+	vals, err := db.GetMap(context.Background(), [][]byte{{0}, {1}, {3}, {0, 0}, {}})
+	require.NoError(err)
+	require.Equal(map[string][]byte{
+		string([]byte{0}): {0, 1, 2},
+		string([]byte{1}): {},
+	}, vals)
+
+	// editing the returned values shouldn't affect the db
+	vals[string([]byte{0})][0] = 1
+	vals, err = db.GetMap(context.Background(), [][]byte{{0}})
+	require.NoError(err)
+	require.Equal(map[string][]byte{
+		string([]byte{0}): {0, 1, 2},
+	}, vals)
+
+	vals, err = db.GetMap(context.Background(), nil)
+	require.NoError(err)
+	require.Empty(vals)
+
+	require.NoError(db.Close())
+	_, err = db.GetMap(context.Background(), [][]byte{{0}})
+	require.ErrorIs(err, database.ErrClosed)
+}
+
+func Test_MerkleDB_GetRawNode(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+
+	rawNode, err := db.GetRawNode([]byte("key"))
+	require.NoError(err)
+
+	// The raw node is the node as stored on disk.
+	diskNode, err := db.nodeDB.Get(newPath([]byte("key")).Bytes())
+	require.NoError(err)
+	require.Equal(diskNode, rawNode)
+
+	n, err := parseNode(newPath([]byte("key")), rawNode)
+	require.NoError(err)
+	require.Equal(maybe.Some([]byte("value")), n.value)
+	require.Len(n.children, 1)
+	expectedNode, err := db.getNode(newPath([]byte("key")))
+	require.NoError(err)
+	require.Equal(expectedNode.children, n.children)
+
+	_, err = db.GetRawNode([]byte("key2"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(db.Close())
+	_, err = db.GetRawNode([]byte("key"))
+	require.ErrorIs(err, database.ErrClosed)
+}
+
+func Test_MerkleDB_BulkLoadSorted(t *testing.T) {
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+
+	// [kvs] iterates in sorted order and includes the empty key and keys
+	// that are prefixes of other keys.
+	kvs := memdb.New()
+	require.NoError(t, kvs.Put(nil, []byte("root value")))
+	for i := 0; i < 1_000; i++ {
+		key := make([]byte, r.Intn(8)+1)
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(2*HashLength)+1)
+		_, _ = r.Read(value)
+		require.NoError(t, kvs.Put(key, value))
+		require.NoError(t, kvs.Put(key[:len(key)-1], value))
+	}
+
+	for _, separateValueStore := range []bool{false, true} {
+		t.Run(fmt.Sprintf("separateValueStore=%t", separateValueStore), func(t *testing.T) {
+			require := require.New(t)
+
+			newConfig := func() Config {
+				config := newDefaultConfig()
+				config.SeparateValueStore = separateValueStore
+				return config
+			}
+
+			// The expected root is the root after inserting the key/value
+			// pairs normally.
+			expectedDB, err := newDB(context.Background(), memdb.New(), newConfig())
+			require.NoError(err)
+			batch := expectedDB.NewBatch()
+			it := kvs.NewIterator()
+			for it.Next() {
+				require.NoError(batch.Put(it.Key(), it.Value()))
+			}
+			it.Release()
+			require.NoError(batch.Write())
+			expectedRoot, err := expectedDB.GetMerkleRoot(context.Background())
+			require.NoError(err)
+
+			baseDB := memdb.New()
+			db, err := newDB(context.Background(), baseDB, newConfig())
+			require.NoError(err)
+			startRoot, err := db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+
+			it = kvs.NewIterator()
+			require.NoError(db.BulkLoadSorted(context.Background(), it))
+			it.Release()
+
+			root, err := db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+			require.Equal(expectedRoot, root)
+			require.NoError(db.VerifyIntegrity(context.Background()))
+
+			numKeys, err := db.Len()
+			require.NoError(err)
+			expectedNumKeys, err := expectedDB.Len()
+			require.NoError(err)
+			require.Equal(expectedNumKeys, numKeys)
+
+			// The bulk load is in the history like any other commit.
+			changeProof, err := db.GetChangeProof(context.Background(), startRoot, root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+			require.NoError(err)
+			require.Len(changeProof.KeyChanges, 10)
+
+			// The trie survives a restart.
+			require.NoError(db.Close())
+			db, err = newDB(context.Background(), baseDB, newConfig())
+			require.NoError(err)
+			root, err = db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+			require.Equal(expectedRoot, root)
+			require.NoError(db.VerifyIntegrity(context.Background()))
+
+			it = kvs.NewIterator()
+			for it.Next() {
+				value, err := db.Get(it.Key())
+				require.NoError(err)
+				require.Equal(it.Value(), value)
+			}
+			it.Release()
+
+			// Only empty databases can be bulk loaded.
+			it = kvs.NewIterator()
+			err = db.BulkLoadSorted(context.Background(), it)
+			it.Release()
+			require.ErrorIs(err, ErrNotEmpty)
+		})
+	}
+}
+
+func Test_MerkleDB_BulkLoadSorted_Unsorted(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// Populate the cache with a miss for a key that is loaded below.
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(err, database.ErrNotFound)
+	startRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	it := &sliceIterator{
+		keys:   [][]byte{[]byte("key1"), []byte("key3"), []byte("key2")},
+		values: [][]byte{[]byte("value1"), []byte("value3"), []byte("value2")},
+	}
+	err = db.BulkLoadSorted(context.Background(), it)
+	require.ErrorIs(err, ErrUnsortedKeys)
+
+	// The database wasn't modified.
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(startRoot, root)
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Duplicate keys aren't allowed either.
+	it = &sliceIterator{
+		keys:   [][]byte{[]byte("key1"), []byte("key1")},
+		values: [][]byte{[]byte("value1"), []byte("value1")},
+	}
+	err = db.BulkLoadSorted(context.Background(), it)
+	require.ErrorIs(err, ErrUnsortedKeys)
+
+	it = &sliceIterator{
+		keys:   [][]byte{[]byte("key1"), []byte("key2")},
+		values: [][]byte{[]byte("value1"), []byte("value2")},
+	}
+	require.NoError(db.BulkLoadSorted(context.Background(), it))
+	value, err := db.Get([]byte("key1"))
+	require.NoError(err)
+	require.Equal([]byte("value1"), value)
+}
+
+func Test_MerkleDB_BulkLoadSorted_VerifyOnCommit(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.VerifyOnCommit = true
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	newIterator := func() database.Iterator {
+		return &sliceIterator{
+			keys:   [][]byte{[]byte("key1"), []byte("key2")},
+			values: [][]byte{[]byte("value1"), []byte("value2")},
+		}
+	}
+
+	// Write a key/value pair to disk without updating the trie.
+	n := newNode(nil, newPath([]byte("unexpected")))
+	n.setValue(maybe.Some([]byte("value")))
+	require.NoError(db.nodeDB.Put(n.key.Bytes(), n.marshal()))
+
+	// The bulk load is rejected before anything is written.
+	err = db.BulkLoadSorted(context.Background(), newIterator())
+	require.ErrorIs(err, errRootMismatch)
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(db.nodeDB.Delete(n.key.Bytes()))
+	require.NoError(db.BulkLoadSorted(context.Background(), newIterator()))
+	value, err := db.Get([]byte("key1"))
+	require.NoError(err)
+	require.Equal([]byte("value1"), value)
+}
+
+func Test_MerkleDB_BulkLoadSorted_CommitInterceptor(t *testing.T) {
+	require := require.New(t)
+
+	errFailedCommit := errors.New("failed commit")
+	var stages []string
+	config := newDefaultConfig()
+	config.commitInterceptor = func(stage string) error {
+		stages = append(stages, stage)
+		if stage == commitStageWriteBatch {
+			return errFailedCommit
+		}
+		return nil
+	}
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+	numNodes, err := database.Count(db.nodeDB)
+	require.NoError(err)
+
+	it := &sliceIterator{
+		keys:   [][]byte{[]byte("key1"), []byte("key2")},
+		values: [][]byte{[]byte("value1"), []byte("value2")},
+	}
+	err = db.BulkLoadSorted(context.Background(), it)
+	require.ErrorIs(err, errFailedCommit)
+	require.Equal([]string{commitStageWriteBatch}, stages)
+
+	// Nothing was written.
+	newNumNodes, err := database.Count(db.nodeDB)
+	require.NoError(err)
+	require.Equal(numNodes, newNumNodes)
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+// sliceIterator iterates over [keys] and [values] in the given order, which
+// may not be sorted.
+type sliceIterator struct {
+	keys, values [][]byte
+	index        int
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.index >= len(it.keys) {
+		return false
+	}
+	it.index++
+	return true
+}
+
+func (*sliceIterator) Error() error {
+	return nil
+}
+
+func (it *sliceIterator) Key() []byte {
+	return it.keys[it.index-1]
+}
+
+func (it *sliceIterator) Value() []byte {
+	return it.values[it.index-1]
+}
+
+func (*sliceIterator) Release() {}
+
+func Test_MerkleDB_Pin(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.Reg = nil
+	config.NodeCacheSize = 100
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+	metrics := db.metrics.(*mockMetrics)
+
+	pinnedKey := []byte("pinned")
+	require.NoError(db.Put(pinnedKey, []byte("value")))
+	require.NoError(db.Pin([][]byte{pinnedKey}))
+
+	// Reading many other keys would evict [pinnedKey] if it wasn't pinned.
+	flood := func() {
+		batch := db.NewBatch()
+		for i := 0; i < 2*config.NodeCacheSize; i++ {
+			require.NoError(batch.Put([]byte(strconv.Itoa(i)), []byte{1}))
+		}
+		require.NoError(batch.Write())
+		for i := 0; i < 2*config.NodeCacheSize; i++ {
+			_, err := db.Get([]byte(strconv.Itoa(i)))
+			require.NoError(err)
+		}
+	}
+	flood()
+
+	cacheHits, cacheMisses := metrics.dbNodeCacheHit, metrics.dbNodeCacheMiss
+	value, err := db.Get(pinnedKey)
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	require.Equal(cacheHits+1, metrics.dbNodeCacheHit)
+	require.Equal(cacheMisses, metrics.dbNodeCacheMiss)
+
+	// At most a quarter of the cache can be pinned.
+	keys := make([][]byte, config.NodeCacheSize/maxPinnedFractionOfCacheSize)
+	for i := range keys {
+		keys[i] = []byte{byte(i)}
+	}
+	err = db.Pin(keys)
+	require.ErrorIs(err, ErrTooManyPinnedKeys)
+	require.NoError(db.Pin(keys[1:]))
+
+	// Once unpinned, the key can be evicted.
+	db.Unpin([][]byte{pinnedKey})
+	flood()
+	cacheMisses = metrics.dbNodeCacheMiss
+	_, err = db.Get(pinnedKey)
+	require.NoError(err)
+	require.Equal(cacheMisses+1, metrics.dbNodeCacheMiss)
+
+	require.NoError(db.Close())
+	err = db.Pin(keys)
+	require.ErrorIs(err, database.ErrClosed)
+}
+
 func Test_MerkleDB_DB_Interface(t *testing.T) {
 	for _, test := range database.Tests {
 		db, err := getBasicDB()
@@ -105,6 +519,174 @@ func Benchmark_MerkleDB_DBInterface(b *testing.B) {
 	}
 }
 
+func Benchmark_MerkleDB_BulkLoadSorted(b *testing.B) {
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	kvs := memdb.New()
+	for i := 0; i < 10_000; i++ {
+		key := make([]byte, 32)
+		_, _ = r.Read(key)
+		value := make([]byte, 32)
+		_, _ = r.Read(value)
+		require.NoError(b, kvs.Put(key, value))
+	}
+
+	b.Run("put", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			db, err := getBasicDB()
+			require.NoError(b, err)
+			it := kvs.NewIterator()
+			for it.Next() {
+				require.NoError(b, db.Put(it.Key(), it.Value()))
+			}
+			it.Release()
+		}
+	})
+	b.Run("bulk load", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			db, err := getBasicDB()
+			require.NoError(b, err)
+			it := kvs.NewIterator()
+			require.NoError(b, db.BulkLoadSorted(context.Background(), it))
+			it.Release()
+		}
+	})
+}
+
+func Benchmark_MerkleDB_NewView_Concurrency(b *testing.B) {
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	ops := make([]database.BatchOp, 0, 10_000)
+	for i := 0; i < 10_000; i++ {
+		key := make([]byte, 32)
+		_, _ = r.Read(key)
+		value := make([]byte, 32)
+		_, _ = r.Read(value)
+		ops = append(ops, database.BatchOp{Key: key, Value: value})
+	}
+
+	for _, concurrency := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			config := newDefaultConfig()
+			config.ViewBuildConcurrency = concurrency
+			db, err := newDB(context.Background(), memdb.New(), config)
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				view, err := db.NewView(context.Background(), ops)
+				require.NoError(b, err)
+				_, err = view.GetMerkleRoot(context.Background())
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func Benchmark_MerkleDB_GetUnsafe(b *testing.B) {
+	db, err := getBasicDB()
+	require.NoError(b, err)
+	key := []byte("hot key")
+	require.NoError(b, db.Put(key, make([]byte, 256)))
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = db.Get(key)
+		}
+	})
+	b.Run("GetUnsafe", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = db.GetUnsafe(key)
+		}
+	})
+}
+
+func Benchmark_MerkleDB_KeyIterator(b *testing.B) {
+	db, err := getBasicDB()
+	require.NoError(b, err)
+
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	batch := db.NewBatch()
+	for i := 0; i < 10_000; i++ {
+		key := make([]byte, 32)
+		_, _ = r.Read(key)
+		value := make([]byte, 4*units.KiB)
+		_, _ = r.Read(value)
+		require.NoError(b, batch.Put(key, value))
+	}
+	require.NoError(b, batch.Write())
+
+	iterators := []struct {
+		name        string
+		newIterator func() database.Iterator
+	}{
+		{
+			name:        "keys",
+			newIterator: func() database.Iterator { return db.NewKeyIterator(nil, nil) },
+		},
+		{
+			name:        "keys and values",
+			newIterator: db.NewIterator,
+		},
+	}
+	for _, iterator := range iterators {
+		b.Run(iterator.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				it := iterator.newIterator()
+				for it.Next() {
+				}
+				require.NoError(b, it.Error())
+				it.Release()
+			}
+		})
+	}
+}
+
+// Benchmark_MerkleDB_CommitWithConcurrentRangeProofs measures the latency of
+// commits while range proofs are continuously generated by other goroutines.
+func Benchmark_MerkleDB_CommitWithConcurrentRangeProofs(b *testing.B) {
+	const numKeys = 10_000
+	for _, numReaders := range []int{0, 1, 4, 16} {
+		b.Run(fmt.Sprintf("readers=%d", numReaders), func(b *testing.B) {
+			db, err := getBasicDB()
+			require.NoError(b, err)
+
+			ops := make([]database.BatchOp, numKeys)
+			for i := range ops {
+				key := hashing.ComputeHash256([]byte(strconv.Itoa(i)))
+				ops[i] = database.BatchOp{
+					Key:   key,
+					Value: key,
+				}
+			}
+			require.NoError(b, db.commitBatch(ops))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			eg, egCtx := errgroup.WithContext(ctx)
+			for i := 0; i < numReaders; i++ {
+				eg.Go(func() error {
+					for egCtx.Err() == nil {
+						if _, err := db.GetRangeProof(egCtx, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 1_000); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := hashing.ComputeHash256([]byte(strconv.Itoa(i % numKeys)))
+				require.NoError(b, db.Put(key, []byte(strconv.Itoa(i))))
+			}
+			b.StopTimer()
+
+			cancel()
+			require.NoError(b, eg.Wait())
+		})
+	}
+}
+
 func Test_MerkleDB_DB_Load_Root_From_DB(t *testing.T) {
 	require := require.New(t)
 	rdb := memdb.New()
@@ -146,6 +728,86 @@ func Test_MerkleDB_DB_Load_Root_From_DB(t *testing.T) {
 	require.Equal(root, reloadedRoot)
 }
 
+func Test_MerkleDB_DB_Load_Root_After_Failed_Commit(t *testing.T) {
+	errFailedCommit := errors.New("failed commit")
+
+	tests := []struct {
+		name             string
+		stage            string
+		expectPostCommit bool
+	}{
+		{
+			name:             "fail before writing nodes",
+			stage:            commitStageWriteBatch,
+			expectPostCommit: false,
+		},
+		{
+			name:             "fail after writing nodes",
+			stage:            commitStageUpdateMemory,
+			expectPostCommit: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			rdb := memdb.New()
+			defer rdb.Close()
+
+			config := newDefaultConfig()
+			failStage := ""
+			config.commitInterceptor = func(stage string) error {
+				if stage == failStage {
+					return errFailedCommit
+				}
+				return nil
+			}
+			db, err := New(context.Background(), rdb, config)
+			require.NoError(err)
+
+			// Populate initial set of keys
+			keyCount := 100
+			ops := make([]database.BatchOp, 0, keyCount)
+			for i := 0; i < keyCount; i++ {
+				k := []byte(strconv.Itoa(i))
+				ops = append(ops, database.BatchOp{Key: k, Value: hashing.ComputeHash256(k)})
+			}
+			view, err := db.NewView(context.Background(), ops)
+			require.NoError(err)
+			require.NoError(view.CommitToDB(context.Background()))
+
+			preCommitRoot, err := db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+
+			// Modify, add, and remove keys.
+			ops = []database.BatchOp{
+				{Key: []byte("0"), Value: []byte("new value")},
+				{Key: []byte("new key"), Value: []byte("value")},
+				{Key: []byte("1"), Delete: true},
+			}
+			view, err = db.NewView(context.Background(), ops)
+			require.NoError(err)
+			postCommitRoot, err := view.GetMerkleRoot(context.Background())
+			require.NoError(err)
+			require.NotEqual(preCommitRoot, postCommitRoot)
+
+			failStage = tt.stage
+			err = view.CommitToDB(context.Background())
+			require.ErrorIs(err, errFailedCommit)
+
+			// Reload the DB without closing it to simulate a crash.
+			db, err = New(context.Background(), rdb, newDefaultConfig())
+			require.NoError(err)
+			reloadedRoot, err := db.GetMerkleRoot(context.Background())
+			require.NoError(err)
+			if tt.expectPostCommit {
+				require.Equal(postCommitRoot, reloadedRoot)
+			} else {
+				require.Equal(preCommitRoot, reloadedRoot)
+			}
+		})
+	}
+}
+
 func Test_MerkleDB_DB_Rebuild(t *testing.T) {
 	require := require.New(t)
 
@@ -294,7 +956,7 @@ func Test_MerkleDB_Commit_Proof_To_Empty_Trie(t *testing.T) {
 	require.Equal(oldRoot, freshRoot)
 }
 
-func Test_MerkleDB_Commit_Proof_To_Filled_Trie(t *testing.T) {
+func Test_MerkleDB_CommitRangeProofVerified(t *testing.T) {
 	require := require.New(t)
 
 	db, err := getBasicDB()
@@ -304,187 +966,2181 @@ func Test_MerkleDB_Commit_Proof_To_Filled_Trie(t *testing.T) {
 	require.NoError(batch.Put([]byte("key2"), []byte("2")))
 	require.NoError(batch.Put([]byte("key3"), []byte("3")))
 	require.NoError(batch.Write())
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
 
-	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1")), maybe.Some([]byte("key3")), 10)
+	baseDB := memdb.New()
+	freshDB, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	require.NoError(freshDB.Put([]byte("key25"), []byte("5")))
+	initialRoot, err := freshDB.GetMerkleRoot(context.Background())
 	require.NoError(err)
+	initialBaseDB := dumpDB(t, baseDB)
 
-	freshDB, err := getBasicDB()
+	// Corrupt a value in the proof.
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1")), maybe.Nothing[[]byte](), 2)
 	require.NoError(err)
-	batch = freshDB.NewBatch()
+	require.Len(proof.KeyValues, 2)
+	proof.KeyValues[1].Value = []byte("4")
+
+	err = freshDB.CommitRangeProofVerified(context.Background(), maybe.Some([]byte("key1")), proof, root)
+	require.ErrorIs(err, ErrProofVerificationFailed)
+
+	// The proof is refused for a different root.
+	proof, err = db.GetRangeProof(context.Background(), maybe.Some([]byte("key1")), maybe.Nothing[[]byte](), 2)
+	require.NoError(err)
+	err = freshDB.CommitRangeProofVerified(context.Background(), maybe.Some([]byte("key1")), proof, ids.GenerateTestID())
+	require.ErrorIs(err, ErrProofVerificationFailed)
+	require.ErrorIs(err, ErrInvalidProof)
+
+	// The database wasn't modified.
+	freshRoot, err := freshDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(initialRoot, freshRoot)
+	require.Equal(initialBaseDB, dumpDB(t, baseDB))
+
+	// The uncorrupted proof is committed.
+	require.NoError(freshDB.CommitRangeProofVerified(context.Background(), maybe.Some([]byte("key1")), proof, root))
+	value, err := freshDB.Get([]byte("key2"))
+	require.NoError(err)
+	require.Equal([]byte("2"), value)
+
+	proof, err = db.GetRangeProof(context.Background(), maybe.Some([]byte("key21")), maybe.Nothing[[]byte](), 2)
+	require.NoError(err)
+	require.NoError(freshDB.CommitRangeProofVerified(context.Background(), maybe.Some([]byte("key21")), proof, root))
+	_, err = freshDB.Get([]byte("key25"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	freshRoot, err = freshDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, freshRoot)
+}
+
+func Test_MerkleDB_CommitRangeProofVerified_InvalidSubtrie(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	// Each key is in its own subtrie under the root.
+	for i := byte(0); i < 10; i++ {
+		require.NoError(db.Put([]byte{i << 4}, []byte{i}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	freshDB, err := getBasicDB()
+	require.NoError(err)
+	initialRoot, err := freshDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Corrupt two values in the middle of the proof.
+	proof, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.Len(proof.KeyValues, 10)
+	require.Equal([]byte{0x50}, proof.KeyValues[5].Key)
+	proof.KeyValues[5].Value = []byte{0}
+	proof.KeyValues[7].Value = []byte{0}
+
+	// Verification stops at the first subtrie containing a corrupt value.
+	err = freshDB.CommitRangeProofVerified(context.Background(), maybe.Nothing[[]byte](), proof, root)
+	require.ErrorIs(err, ErrProofVerificationFailed)
+	require.ErrorIs(err, ErrInvalidSubtrie)
+	require.ErrorContains(err, ErrInvalidSubtrie.Error()+": 5")
+
+	// Extra key/value pairs are also reported.
+	proof, err = db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	proof.KeyValues = append(proof.KeyValues[:6], append([]KeyValue{{Key: []byte{0x58}, Value: []byte{0}}}, proof.KeyValues[6:]...)...)
+	err = freshDB.CommitRangeProofVerified(context.Background(), maybe.Nothing[[]byte](), proof, root)
+	require.ErrorIs(err, ErrProofVerificationFailed)
+	require.ErrorIs(err, ErrInvalidSubtrie)
+	require.ErrorContains(err, ErrInvalidSubtrie.Error()+": 5")
+
+	// The database wasn't modified.
+	freshRoot, err := freshDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(initialRoot, freshRoot)
+
+	// The valid proof is committed.
+	proof, err = db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.NoError(freshDB.CommitRangeProofVerified(context.Background(), maybe.Nothing[[]byte](), proof, root))
+	freshRoot, err = freshDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, freshRoot)
+}
+
+func Test_MerkleDB_CommitRangeProofVerified_Random(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 500; i++ {
+		key := make([]byte, r.Intn(8))
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(64))
+		_, _ = r.Read(value)
+		require.NoError(db.Put(key, value))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	for i := 0; i < 50; i++ {
+		start := maybe.Nothing[[]byte]()
+		if r.Intn(4) != 0 {
+			startBytes := make([]byte, r.Intn(8))
+			_, _ = r.Read(startBytes)
+			start = maybe.Some(startBytes)
+		}
+		proof, err := db.GetRangeProof(context.Background(), start, maybe.Nothing[[]byte](), r.Intn(200)+1)
+		require.NoError(err)
+
+		freshDB, err := getBasicDB()
+		require.NoError(err)
+		require.NoError(freshDB.CommitRangeProofVerified(context.Background(), start, proof, root))
+	}
+}
+
+func Test_MerkleDB_Commit_Proof_To_Filled_Trie(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key1"), []byte("1")))
+	require.NoError(batch.Put([]byte("key2"), []byte("2")))
+	require.NoError(batch.Put([]byte("key3"), []byte("3")))
+	require.NoError(batch.Write())
+
+	proof, err := db.GetRangeProof(context.Background(), maybe.Some([]byte("key1")), maybe.Some([]byte("key3")), 10)
+	require.NoError(err)
+
+	freshDB, err := getBasicDB()
+	require.NoError(err)
+	batch = freshDB.NewBatch()
 	require.NoError(batch.Put([]byte("key1"), []byte("3")))
 	require.NoError(batch.Put([]byte("key2"), []byte("4")))
 	require.NoError(batch.Put([]byte("key3"), []byte("5")))
 	require.NoError(batch.Put([]byte("key25"), []byte("5")))
 	require.NoError(batch.Write())
 
-	require.NoError(freshDB.CommitRangeProof(context.Background(), maybe.Some([]byte("key1")), proof))
+	require.NoError(freshDB.CommitRangeProof(context.Background(), maybe.Some([]byte("key1")), proof))
+
+	value, err := freshDB.Get([]byte("key2"))
+	require.NoError(err)
+	require.Equal([]byte("2"), value)
+
+	freshRoot, err := freshDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	oldRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(oldRoot, freshRoot)
+}
+
+func Test_MerkleDB_GetValues(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	writeBasicBatch(t, db)
+	keys := [][]byte{{0}, {1}, {2}, {10}}
+	values, errors := db.GetValues(context.Background(), keys)
+	require.Len(values, len(keys))
+	require.Len(errors, len(keys))
+
+	// first 3 have values
+	// last was not found
+	require.NoError(errors[0])
+	require.NoError(errors[1])
+	require.NoError(errors[2])
+	require.ErrorIs(errors[3], database.ErrNotFound)
+
+	require.Equal([]byte{0}, values[0])
+	require.Equal([]byte{1}, values[1])
+	require.Equal([]byte{2}, values[2])
+	require.Nil(values[3])
+}
+
+func Test_MerkleDB_InsertNil(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key0"), nil))
+	require.NoError(batch.Write())
+
+	value, err := db.Get([]byte("key0"))
+	require.NoError(err)
+	require.Nil(value)
+
+	value, err = getNodeValue(db, "key0")
+	require.NoError(err)
+	require.Nil(value)
+}
+
+func Test_MerkleDB_InsertAndRetrieve(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// value hasn't been inserted so shouldn't exist
+	value, err := db.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Nil(value)
+
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	value, err = db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func Test_MerkleDB_HealthCheck(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	val, err := db.HealthCheck(context.Background())
+	require.NoError(err)
+	require.Nil(val)
+}
+
+func Test_MerkleDB_Overwrite(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("value0")))
+
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value0"), value)
+
+	require.NoError(db.Put([]byte("key"), []byte("value1")))
+
+	value, err = db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value1"), value)
+}
+
+func Test_MerkleDB_Delete(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("value0")))
+
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value0"), value)
+
+	require.NoError(db.Delete([]byte("key")))
+
+	value, err = db.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Nil(value)
+}
+
+func Test_MerkleDB_DeleteMissingKey(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Delete([]byte("key")))
+}
+
+// Test that untracked views aren't persisted to [db.childViews].
+func TestDatabaseNewUntrackedView(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// Create a new untracked view.
+	view, err := db.newUntrackedView([]database.BatchOp{{Key: []byte{1}, Value: []byte{1}}})
+	require.NoError(err)
+	require.Empty(db.childViews)
+
+	// Commit the view
+	require.NoError(view.CommitToDB(context.Background()))
+
+	// The untracked view should not be tracked by the parent database.
+	require.Empty(db.childViews)
+}
+
+// Test that tracked views are persisted to [db.childViews].
+func TestDatabaseNewViewTracked(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// Create a new tracked view.
+	view, err := db.NewView(context.Background(), []database.BatchOp{{Key: []byte{1}, Value: []byte{1}}})
+	require.NoError(err)
+	require.Len(db.childViews, 1)
+
+	// Commit the view
+	require.NoError(view.CommitToDB(context.Background()))
+
+	// The untracked view should be tracked by the parent database.
+	require.Contains(db.childViews, view)
+	require.Len(db.childViews, 1)
+}
+
+func TestDatabaseExtractRange(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(db.Put([]byte{byte(i)}, []byte{byte(i), byte(i)}))
+	}
+
+	dstDB := memdb.New()
+	require.NoError(db.ExtractRange(
+		context.Background(),
+		maybe.Some([]byte{3}),
+		maybe.Some([]byte{7}),
+		dstDB,
+	))
+
+	extractedDB, err := New(context.Background(), dstDB, newDefaultConfig())
+	require.NoError(err)
+
+	// Keys in [start, end) were copied.
+	expectedDB, err := getBasicDB()
+	require.NoError(err)
+	for i := 3; i < 7; i++ {
+		key := []byte{byte(i)}
+		value, err := extractedDB.Get(key)
+		require.NoError(err)
+		require.Equal([]byte{byte(i), byte(i)}, value)
+
+		require.NoError(expectedDB.Put(key, value))
+	}
+
+	// Keys outside of [start, end) weren't copied.
+	for _, i := range []int{0, 2, 7, 9} {
+		_, err := extractedDB.Get([]byte{byte(i)})
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+
+	// The extracted root is the root of a trie with only the range's keys.
+	expectedRoot, err := expectedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	extractedRoot, err := extractedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, extractedRoot)
+
+	// The source database is unchanged.
+	value, err := db.Get([]byte{9})
+	require.NoError(err)
+	require.Equal([]byte{9, 9}, value)
+
+	err = db.ExtractRange(
+		context.Background(),
+		maybe.Some([]byte{7}),
+		maybe.Some([]byte{3}),
+		memdb.New(),
+	)
+	require.ErrorIs(err, ErrStartAfterEnd)
+}
+
+// Ensures readers can run concurrently with each other and with a committing
+// writer. Should be run with -race.
+func TestDatabaseConcurrentReadsAndCommits(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	const (
+		numKeys    = 64
+		numReaders = 8
+		numCommits = 100
+	)
+	for i := 0; i < numKeys; i++ {
+		require.NoError(db.Put([]byte{byte(i)}, []byte{byte(i)}))
+	}
+
+	ctx := context.Background()
+	eg, egCtx := errgroup.WithContext(ctx)
+	done := make(chan struct{})
+	eg.Go(func() error {
+		defer close(done)
+
+		for i := 0; i < numCommits; i++ {
+			key := []byte{byte(i % numKeys)}
+			view, err := db.NewView(ctx, []database.BatchOp{
+				{
+					Key:   key,
+					Value: []byte{byte(i)},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if err := view.CommitToDB(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	for i := 0; i < numReaders; i++ {
+		eg.Go(func() error {
+			for {
+				select {
+				case <-done:
+					return nil
+				case <-egCtx.Done():
+					return nil
+				default:
+				}
+
+				key := []byte{byte(rand.Intn(numKeys))} // #nosec G404
+				if _, err := db.Get(key); err != nil {
+					return err
+				}
+				if _, errs := db.GetValues(ctx, [][]byte{key}); errs[0] != nil {
+					return errs[0]
+				}
+				if _, err := db.GetMerkleRoot(ctx); err != nil {
+					return err
+				}
+				if _, err := db.GetProof(ctx, key); err != nil {
+					return err
+				}
+				if _, err := db.GetRangeProof(ctx, maybe.Some(key), maybe.Nothing[[]byte](), 10); err != nil {
+					return err
+				}
+			}
+		})
+	}
+	require.NoError(eg.Wait())
+
+	// All keys are still readable after the commits.
+	for i := 0; i < numKeys; i++ {
+		_, err := db.Get([]byte{byte(i)})
+		require.NoError(err)
+	}
+}
+
+func TestDatabaseRangeProofsConsistentDuringCommits(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	const (
+		numKeys    = 256
+		numReaders = 8
+		numCommits = 100
+	)
+	for i := 0; i < numKeys; i++ {
+		require.NoError(db.Put([]byte{byte(i)}, []byte{byte(i)}))
+	}
+
+	ctx := context.Background()
+	eg, egCtx := errgroup.WithContext(ctx)
+	done := make(chan struct{})
+	eg.Go(func() error {
+		defer close(done)
+
+		for i := 0; i < numCommits; i++ {
+			if err := db.Put([]byte{byte(i % numKeys)}, []byte{byte(i), 1}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	for i := 0; i < numReaders; i++ {
+		eg.Go(func() error {
+			for {
+				select {
+				case <-done:
+					return nil
+				case <-egCtx.Done():
+					return nil
+				default:
+				}
+
+				rootID, err := db.GetMerkleRoot(ctx)
+				if err != nil {
+					return err
+				}
+				proof, err := db.GetRangeProofAtRoot(ctx, rootID, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), numKeys)
+				if err != nil {
+					return err
+				}
+				if err := proof.Verify(ctx, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), rootID); err != nil {
+					return err
+				}
+			}
+		})
+	}
+	require.NoError(eg.Wait())
+}
+
+var errTransient = errors.New("transient error")
+
+// flakyDB fails the next [failures] calls to Get with [errTransient].
+type flakyDB struct {
+	database.Database
+
+	failures int
+	gets     int
+}
+
+func (db *flakyDB) Get(key []byte) ([]byte, error) {
+	db.gets++
+	if db.failures > 0 {
+		db.failures--
+		return nil, errTransient
+	}
+	return db.Database.Get(key)
+}
+
+func TestDatabaseReadRetry(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key0"), []byte("value0")))
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+	require.NoError(db.Close())
+
+	flaky := &flakyDB{Database: baseDB}
+	config := newDefaultConfig()
+	config.ReadRetry = ReadRetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}
+	db, err = newDB(context.Background(), flaky, config)
+	require.NoError(err)
+
+	// The read succeeds on the third attempt.
+	flaky.failures = 2
+	flaky.gets = 0
+	value, err := db.Get([]byte("key0"))
+	require.NoError(err)
+	require.Equal([]byte("value0"), value)
+	require.Equal(3, flaky.gets)
+
+	// Missing keys aren't retried.
+	flaky.gets = 0
+	_, err = db.Get([]byte("missing"))
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Equal(1, flaky.gets)
+
+	// The error is returned once the attempts are exhausted.
+	flaky.failures = 3
+	flaky.gets = 0
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(err, errTransient)
+	require.Equal(3, flaky.gets)
+	require.NoError(db.Close())
+
+	// The error is returned once another retry would exceed the total wait.
+	config = newDefaultConfig()
+	config.ReadRetry = ReadRetryConfig{
+		MaxAttempts:    100,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		MaxWait:        5 * time.Millisecond,
+	}
+	db, err = newDB(context.Background(), flaky, config)
+	require.NoError(err)
+
+	// Waits 1ms, 2ms, and 2ms before giving up.
+	flaky.failures = 100
+	flaky.gets = 0
+	_, err = db.Get([]byte("key1"))
+	require.ErrorIs(err, errTransient)
+	require.Equal(4, flaky.gets)
+}
+
+func TestDatabaseNodeLoadError(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0x1a, 0x2b}, []byte("value")))
+	require.NoError(db.Close())
+
+	flaky := &flakyDB{Database: baseDB}
+	db, err = newDB(context.Background(), flaky, newDefaultConfig())
+	require.NoError(err)
+
+	// The error includes the path of the node that couldn't be loaded.
+	flaky.failures = 1
+	_, err = db.Get([]byte{0x1a, 0x2b})
+	require.ErrorIs(err, errTransient)
+	require.ErrorContains(err, "failed to load node at path 0x1a2b (cache miss)")
+
+	// Missing nodes aren't wrapped.
+	_, err = db.Get([]byte{0x1a, 0x2c})
+	require.Equal(database.ErrNotFound, err)
+}
+
+func TestDatabaseSeparateValueStore(t *testing.T) {
+	require := require.New(t)
+
+	inlineDB, err := getBasicDB()
+	require.NoError(err)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	expected := map[string][]byte{}
+	for i := 0; i < 500; i++ {
+		key := make([]byte, r.Intn(4))
+		_, _ = r.Read(key)
+		if r.Intn(4) == 0 {
+			require.NoError(inlineDB.Delete(key))
+			require.NoError(db.Delete(key))
+			delete(expected, string(key))
+			continue
+		}
+
+		// Mix values that are stored separately with ones that aren't.
+		value := make([]byte, r.Intn(2*HashLength)+1)
+		_, _ = r.Read(value)
+		require.NoError(inlineDB.Put(key, value))
+		require.NoError(db.Put(key, value))
+		expected[string(key)] = value
+	}
+
+	expectedRoot, err := inlineDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+
+	// Reopen the database so that nodes are read from disk.
+	require.NoError(db.Close())
+	config = newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err = newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	root, err = db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+
+	for key, expectedValue := range expected {
+		value, err := db.Get([]byte(key))
+		require.NoError(err)
+		require.Equal(expectedValue, value)
+
+		// Nodes on disk don't contain long values.
+		nodeBytes, err := db.nodeDB.Get(newPath([]byte(key)).Bytes())
+		require.NoError(err)
+		if len(expectedValue) >= HashLength {
+			require.NotContains(string(nodeBytes), string(expectedValue))
+		}
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+	numKeys := 0
+	for it.Next() {
+		require.Equal(expected[string(it.Key())], it.Value())
+		numKeys++
+	}
+	require.NoError(it.Error())
+	require.Len(expected, numKeys)
+
+	// Only the values that are still referenced are stored.
+	referencedValues := set.Set[string]{}
+	for _, value := range expected {
+		if len(value) >= HashLength {
+			referencedValues.Add(string(hashing.ComputeHash256(value)))
+		}
+	}
+	numValues, err := database.Count(db.valueDB)
+	require.NoError(err)
+	require.Equal(referencedValues.Len(), numValues)
+}
+
+func TestDatabaseSeparateValueStoreDeletesUnreferencedValues(t *testing.T) {
+	for name, flushPolicy := range map[string]FlushPolicy{
+		"write through": WriteThrough,
+		"write back":    WriteBack,
+	} {
+		flushPolicy := flushPolicy
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			config := newDefaultConfig()
+			config.SeparateValueStore = true
+			config.FlushPolicy = flushPolicy
+			db, err := newDB(context.Background(), memdb.New(), config)
+			require.NoError(err)
+
+			value1 := bytes.Repeat([]byte{1}, HashLength)
+			value2 := bytes.Repeat([]byte{2}, HashLength)
+			hash1 := hashing.ComputeHash256(value1)
+			hash2 := hashing.ComputeHash256(value2)
+
+			requireStored := func(valueHash []byte, expected bool) {
+				has, err := db.valueDB.Has(valueHash)
+				require.NoError(err)
+				require.Equal(expected, has)
+			}
+
+			// Both keys reference [value1].
+			require.NoError(db.Put([]byte{0}, value1))
+			require.NoError(db.Put([]byte{1}, value1))
+			require.NoError(db.Flush())
+			requireStored(hash1, true)
+
+			// [value1] is still referenced by the other key.
+			require.NoError(db.Put([]byte{0}, value2))
+			require.NoError(db.Flush())
+			requireStored(hash1, true)
+			requireStored(hash2, true)
+
+			// Nothing references [value1].
+			require.NoError(db.Delete([]byte{1}))
+			if flushPolicy == WriteBack {
+				// The nodes that referenced it haven't been written yet.
+				requireStored(hash1, true)
+			}
+			require.NoError(db.Flush())
+			requireStored(hash1, false)
+			requireStored(hash2, true)
+
+			// [value1] is stored again when it's referenced again.
+			require.NoError(db.Put([]byte{1}, value1))
+			require.NoError(db.Flush())
+			requireStored(hash1, true)
+
+			value, err := db.Get([]byte{0})
+			require.NoError(err)
+			require.Equal(value2, value)
+			value, err = db.Get([]byte{1})
+			require.NoError(err)
+			require.Equal(value1, value)
+		})
+	}
+}
+
+func TestDatabaseSeparateValueStoreBatchedCommits(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	value := bytes.Repeat([]byte{1}, HashLength)
+	valueHash := hashing.ComputeHash256(value)
+	require.NoError(db.Put([]byte{0}, value))
+
+	require.NoError(db.BeginBatchedCommits())
+	require.NoError(db.Delete([]byte{0}))
+
+	// The deleted node is still on disk until the batch is written.
+	has, err := db.valueDB.Has(valueHash)
+	require.NoError(err)
+	require.True(has)
+
+	require.NoError(db.EndBatchedCommits())
+	has, err = db.valueDB.Has(valueHash)
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestDatabaseSeparateValueStoreOpenIterator(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	value := bytes.Repeat([]byte{1}, HashLength)
+	valueHash := hashing.ComputeHash256(value)
+	require.NoError(db.Put([]byte{0}, value))
+
+	// The iterator reads the node that references [value] after it's no
+	// longer referenced, so it isn't deleted while the iterator is open.
+	it := db.NewIterator()
+	require.NoError(db.Delete([]byte{0}))
+	require.True(it.Next())
+	require.Equal(value, it.Value())
+	require.False(it.Next())
+	require.NoError(it.Error())
+	it.Release()
+	it.Release()
+
+	has, err := db.valueDB.Has(valueHash)
+	require.NoError(err)
+	require.True(has)
+
+	// It's deleted by the next commit once the iterator is released.
+	require.NoError(db.Put([]byte{1}, []byte{1}))
+	has, err = db.valueDB.Has(valueHash)
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestDatabaseSeparateValueStoreRecount(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	value1 := bytes.Repeat([]byte{1}, HashLength)
+	value2 := bytes.Repeat([]byte{2}, HashLength)
+	require.NoError(db.Put([]byte{0}, value1))
+	require.NoError(db.Put([]byte{1}, value1))
+	require.NoError(db.Put([]byte{2}, value2))
+	require.NoError(db.Close())
+
+	// Simulate a database created before references were counted, which has
+	// a value that nothing references.
+	unreferencedValue := bytes.Repeat([]byte{3}, HashLength)
+	unreferencedHash := hashing.ComputeHash256(unreferencedValue)
+	valueDB := prefixdb.New(valuePrefix, baseDB)
+	require.NoError(valueDB.Put(unreferencedHash, unreferencedValue))
+	valueRefDB := prefixdb.New(valueRefPrefix, baseDB)
+	require.NoError(database.Clear(valueRefDB, valueRefDB))
+	require.NoError(prefixdb.New(metadataPrefix, baseDB).Delete(valueStoreLayoutKey))
+
+	config = newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err = newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	has, err := db.valueDB.Has(unreferencedHash)
+	require.NoError(err)
+	require.False(has)
+	count, err := database.GetUInt64(db.valueRefDB, hashing.ComputeHash256(value1))
+	require.NoError(err)
+	require.Equal(uint64(2), count)
+	count, err = database.GetUInt64(db.valueRefDB, hashing.ComputeHash256(value2))
+	require.NoError(err)
+	require.Equal(uint64(1), count)
+
+	value, err := db.Get([]byte{1})
+	require.NoError(err)
+	require.Equal(value1, value)
+}
+
+func TestDatabaseSeparateValueStoreMismatch(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.SeparateValueStore = true
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0}, []byte{1}))
+	require.NoError(db.Close())
+
+	_, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.ErrorIs(err, ErrValueStoreMismatch)
+
+	baseDB = memdb.New()
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	config = newDefaultConfig()
+	config.SeparateValueStore = true
+	_, err = newDB(context.Background(), baseDB, config)
+	require.ErrorIs(err, ErrValueStoreMismatch)
+}
+
+func TestDatabasePrefetchRange(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	for i := 0; i < 1024; i++ {
+		require.NoError(db.Put([]byte{byte(i >> 8), byte(i)}, []byte{byte(i)}))
+	}
+	require.NoError(db.Close())
+
+	metrics := &mockMetrics{}
+	db, err = newDatabase(context.Background(), baseDB, newDefaultConfig(), metrics)
+	require.NoError(err)
+
+	require.NoError(db.PrefetchRange(
+		context.Background(),
+		maybe.Some([]byte{1, 0}),
+		maybe.Some([]byte{1, 255}),
+	))
+
+	metrics.lock.Lock()
+	require.Positive(metrics.dbNodeCacheMiss)
+	metrics.dbNodeCacheHit = 0
+	metrics.dbNodeCacheMiss = 0
+	metrics.lock.Unlock()
+
+	// Reads in the range hit the cache.
+	for i := 0; i < 256; i++ {
+		_, err := db.Get([]byte{1, byte(i)})
+		require.NoError(err)
+	}
+	metrics.lock.Lock()
+	require.Positive(metrics.dbNodeCacheHit)
+	require.Zero(metrics.dbNodeCacheMiss)
+	metrics.lock.Unlock()
+
+	// Nodes outside of the range weren't loaded.
+	_, err = db.Get([]byte{3, 0})
+	require.NoError(err)
+	metrics.lock.Lock()
+	require.Positive(metrics.dbNodeCacheMiss)
+	metrics.lock.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = db.PrefetchRange(ctx, maybe.Nothing[[]byte](), maybe.Nothing[[]byte]())
+	require.ErrorIs(err, context.Canceled)
+
+	err = db.PrefetchRange(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{0}))
+	require.ErrorIs(err, ErrStartAfterEnd)
+}
+
+func TestDatabasePrefetchRangeLimitedByCacheSize(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	for i := 0; i < 1024; i++ {
+		require.NoError(db.Put([]byte{byte(i >> 8), byte(i)}, []byte{byte(i)}))
+	}
+	require.NoError(db.Close())
+
+	const cacheSize = 10
+	config := newDefaultConfig()
+	config.NodeCacheSize = cacheSize
+	metrics := &mockMetrics{}
+	db, err = newDatabase(context.Background(), baseDB, config, metrics)
+	require.NoError(err)
+
+	require.NoError(db.PrefetchRange(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte]()))
+
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	require.Equal(int64(cacheSize), metrics.dbNodeCacheMiss)
+}
+
+func TestDatabaseEstimateRangeSize(t *testing.T) {
+	db, err := getBasicDB()
+	require.NoError(t, err)
+
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	for i := 0; i < 2048; i++ {
+		key := make([]byte, 32)
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(64))
+		_, _ = r.Read(value)
+		require.NoError(t, db.Put(key, value))
+	}
+
+	tests := []struct {
+		name  string
+		start maybe.Maybe[[]byte]
+		end   maybe.Maybe[[]byte]
+	}{
+		{
+			name:  "whole trie",
+			start: maybe.Nothing[[]byte](),
+			end:   maybe.Nothing[[]byte](),
+		},
+		{
+			name:  "half",
+			start: maybe.Some([]byte{0x40}),
+			end:   maybe.Some([]byte{0xbf, 0xff}),
+		},
+		{
+			name:  "small range",
+			start: maybe.Some([]byte{0x10}),
+			end:   maybe.Some([]byte{0x1f}),
+		},
+		{
+			name:  "no upper bound",
+			start: maybe.Some([]byte{0xe0}),
+			end:   maybe.Nothing[[]byte](),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			exact := exactRangeSize(t, db, tt.start, tt.end)
+			require.Positive(exact)
+
+			estimate, err := db.EstimateRangeSize(context.Background(), tt.start, tt.end)
+			require.NoError(err)
+			require.InDelta(exact, estimate, 0.2*float64(exact))
+		})
+	}
+}
+
+func TestDatabaseEstimateRangeSizeEmptyRange(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 256; i++ {
+		require.NoError(db.Put([]byte{byte(i), 0}, []byte{byte(i)}))
+	}
+
+	// There are no keys in the range.
+	estimate, err := db.EstimateRangeSize(context.Background(), maybe.Some([]byte{0x10, 1}), maybe.Some([]byte{0x10, 2}))
+	require.NoError(err)
+	require.Zero(estimate)
+
+	_, err = db.EstimateRangeSize(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{0}))
+	require.ErrorIs(err, ErrStartAfterEnd)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = db.EstimateRangeSize(ctx, maybe.Nothing[[]byte](), maybe.Nothing[[]byte]())
+	require.ErrorIs(err, context.Canceled)
+}
+
+// exactRangeSize returns the number of bytes the nodes of [db] with keys in
+// [start, end] occupy on disk by visiting every node.
+func exactRangeSize(t *testing.T, db *merkleDB, start, end maybe.Maybe[[]byte]) uint64 {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		startPath = maybe.Bind(start, newPath)
+		endPath   = maybe.Bind(end, newPath)
+		size      uint64
+		stack     = []*node{db.root}
+	)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if (startPath.IsNothing() || n.key.Compare(startPath.Value()) >= 0) &&
+			(endPath.IsNothing() || n.key.Compare(endPath.Value()) <= 0) {
+			size += uint64(db.nodeDiskSize(n))
+		}
+		for index, child := range n.children {
+			childNode, err := db.getNode(n.key + path(index) + child.compressedPath)
+			require.NoError(t, err)
+			stack = append(stack, childNode)
+		}
+	}
+	return size
+}
+
+func TestDatabaseLen(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	count, err := db.Len()
+	require.NoError(err)
+	require.Zero(count)
+
+	require.NoError(db.Put([]byte("key0"), []byte("value0")))
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+	// Nil values are present in the trie.
+	require.NoError(db.Put([]byte("key2"), nil))
+
+	count, err = db.Len()
+	require.NoError(err)
+	require.Equal(3, count)
+
+	// Overwriting a key doesn't change the count.
+	require.NoError(db.Put([]byte("key0"), []byte("value2")))
+	// Deleting a key that doesn't exist doesn't change the count.
+	require.NoError(db.Delete([]byte("key3")))
+
+	count, err = db.Len()
+	require.NoError(err)
+	require.Equal(3, count)
+
+	require.NoError(db.Delete([]byte("key1")))
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key3"), []byte("value3")))
+	require.NoError(batch.Put([]byte("key4"), []byte("value4")))
+	require.NoError(batch.Delete([]byte("key2")))
+	require.NoError(batch.Write())
+
+	count, err = db.Len()
+	require.NoError(err)
+	require.Equal(3, count)
+
+	// The incrementally maintained count matches a full scan.
+	db.keyCountKnown = false
+	count, err = db.Len()
+	require.NoError(err)
+	require.Equal(3, count)
+
+	require.NoError(db.Close())
+	_, err = db.Len()
+	require.ErrorIs(err, database.ErrClosed)
+}
+
+func TestDatabaseLenConcurrent(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	const numKeys = 100
+	for i := 0; i < numKeys; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		require.NoError(db.Put(key, key))
+	}
+
+	// Keys are counted while other goroutines read and commit.
+	var eg errgroup.Group
+	for i := 0; i < 4; i++ {
+		eg.Go(func() error {
+			for j := 0; j < 10; j++ {
+				db.lock.Lock()
+				db.keyCountKnown = false
+				db.lock.Unlock()
+				if _, err := db.Len(); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		for i := 0; i < numKeys; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if _, err := db.Get(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		for i := numKeys; i < 2*numKeys; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if err := db.Put(key, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(eg.Wait())
+
+	count, err := db.Len()
+	require.NoError(err)
+	require.Equal(2*numKeys, count)
+}
+
+func TestDatabaseCachedRoot(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	requireCachedRootCurrent := func() {
+		root, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		require.Equal(root, db.CachedRoot())
+	}
+	requireCachedRootCurrent()
+
+	emptyRoot := db.CachedRoot()
+	require.NoError(db.Put([]byte("key0"), []byte("value0")))
+	require.NotEqual(emptyRoot, db.CachedRoot())
+	requireCachedRootCurrent()
+
+	// Uncommitted views don't change the cached root.
+	previousRoot := db.CachedRoot()
+	view, err := db.NewView(context.Background(), []database.BatchOp{
+		{Key: []byte("key1"), Value: []byte("value1")},
+	})
+	require.NoError(err)
+	_, err = view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(previousRoot, db.CachedRoot())
+
+	require.NoError(view.CommitToDB(context.Background()))
+	require.NotEqual(previousRoot, db.CachedRoot())
+	requireCachedRootCurrent()
+
+	require.NoError(db.Delete([]byte("key0")))
+	requireCachedRootCurrent()
+}
+
+func TestDatabaseNewIteratorAtRoot(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("a"), []byte("1")))
+	require.NoError(batch.Put([]byte("b0"), []byte("2")))
+	require.NoError(batch.Put([]byte("b1"), []byte("3")))
+	require.NoError(batch.Put([]byte("c"), []byte("4")))
+	require.NoError(batch.Write())
+
+	pinnedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Create an iterator before the next commit.
+	it, err := db.NewIteratorAtRoot(pinnedRoot, nil, []byte("b"))
+	require.NoError(err)
+
+	batch = db.NewBatch()
+	require.NoError(batch.Put([]byte("b0"), []byte("5")))
+	require.NoError(batch.Put([]byte("b2"), []byte("6")))
+	require.NoError(batch.Delete([]byte("b1")))
+	require.NoError(batch.Write())
+
+	expected := []KeyValue{
+		{Key: []byte("b0"), Value: []byte("2")},
+		{Key: []byte("b1"), Value: []byte("3")},
+	}
+	requireIterates := func(expected []KeyValue, it database.Iterator) {
+		defer it.Release()
+
+		for _, kv := range expected {
+			require.True(it.Next())
+			require.Equal(kv.Key, it.Key())
+			require.Equal(kv.Value, it.Value())
+		}
+		require.False(it.Next())
+		require.NoError(it.Error())
+	}
+	requireIterates(expected, it)
+
+	// Create an iterator at the pinned root after the commit.
+	it, err = db.NewIteratorAtRoot(pinnedRoot, []byte("b1"), nil)
+	require.NoError(err)
+	requireIterates([]KeyValue{
+		{Key: []byte("b1"), Value: []byte("3")},
+		{Key: []byte("c"), Value: []byte("4")},
+	}, it)
+
+	// Iterate at the current root.
+	currentRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	it, err = db.NewIteratorAtRoot(currentRoot, nil, []byte("b"))
+	require.NoError(err)
+	requireIterates([]KeyValue{
+		{Key: []byte("b0"), Value: []byte("5")},
+		{Key: []byte("b2"), Value: []byte("6")},
+	}, it)
+
+	_, err = db.NewIteratorAtRoot(ids.GenerateTestID(), nil, nil)
+	require.ErrorIs(err, ErrRootNotInHistory)
+}
+
+func TestDatabaseChangedKeysBetween(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("a"), []byte("1")))
+	require.NoError(batch.Put([]byte("b"), []byte("2")))
+	require.NoError(batch.Write())
+	startRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	batch = db.NewBatch()
+	require.NoError(batch.Put([]byte("a"), []byte("10")))
+	require.NoError(batch.Put([]byte("c"), []byte("3")))
+	require.NoError(batch.Write())
+	midRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	batch = db.NewBatch()
+	require.NoError(batch.Delete([]byte("b")))
+	// [c] is added and then removed, so it isn't changed overall.
+	require.NoError(batch.Delete([]byte("c")))
+	require.NoError(batch.Put([]byte("d"), []byte("4")))
+	require.NoError(batch.Write())
+	endRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	changedKeys, err := db.ChangedKeysBetween(startRoot, endRoot)
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("a"), []byte("b"), []byte("d")}, changedKeys)
+
+	changedKeys, err = db.ChangedKeysBetween(startRoot, midRoot)
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("a"), []byte("c")}, changedKeys)
+
+	changedKeys, err = db.ChangedKeysBetween(endRoot, endRoot)
+	require.NoError(err)
+	require.Empty(changedKeys)
+
+	_, err = db.ChangedKeysBetween(ids.GenerateTestID(), endRoot)
+	require.ErrorIs(err, ErrRootNotInHistory)
+
+	_, err = db.ChangedKeysBetween(startRoot, ids.GenerateTestID())
+	require.ErrorIs(err, ErrRootNotInHistory)
+}
+
+func TestDatabaseMaxValueLen(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.MaxValueLen = 4
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key0"), []byte("1234")))
+
+	initialRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	err = db.Put([]byte("key1"), []byte("12345"))
+	require.ErrorIs(err, ErrValueTooLarge)
+
+	// The whole batch is rejected if any value is too large.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key2"), []byte("1")))
+	require.NoError(batch.Delete([]byte("key0")))
+	require.NoError(batch.Put([]byte("key3"), []byte("12345")))
+	err = batch.Write()
+	require.ErrorIs(err, ErrValueTooLarge)
+
+	// Views are also limited.
+	_, err = db.NewView(context.Background(), []database.BatchOp{
+		{Key: []byte("key4"), Value: []byte("12345")},
+	})
+	require.ErrorIs(err, ErrValueTooLarge)
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(initialRoot, root)
+
+	value, err := db.Get([]byte("key0"))
+	require.NoError(err)
+	require.Equal([]byte("1234"), value)
+
+	for _, key := range []string{"key1", "key2", "key3", "key4"} {
+		_, err := db.Get([]byte(key))
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+
+	view, err := db.NewView(context.Background(), nil)
+	require.NoError(err)
+	_, err = view.NewView(context.Background(), []database.BatchOp{
+		{Key: []byte("key4"), Value: []byte("12345")},
+	})
+	require.ErrorIs(err, ErrValueTooLarge)
+
+	// Values from proofs aren't limited.
+	sourceDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(sourceDB.Put([]byte("key5"), []byte("12345")))
+	proof, err := sourceDB.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.NoError(db.CommitRangeProof(context.Background(), maybe.Nothing[[]byte](), proof))
+
+	value, err = db.Get([]byte("key5"))
+	require.NoError(err)
+	require.Equal([]byte("12345"), value)
+}
+
+func TestDatabaseKeyValidator(t *testing.T) {
+	require := require.New(t)
+
+	const maxKeyLen = 256
+	errKeyTooLong := errors.New("key too long")
+	config := newDefaultConfig()
+	config.KeyValidator = func(key []byte) error {
+		if len(key) > maxKeyLen {
+			return errKeyTooLong
+		}
+		return nil
+	}
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	require.NoError(db.Put(make([]byte, maxKeyLen), []byte("value")))
+
+	initialRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	initialBaseDB := dumpDB(t, baseDB)
+
+	longKey := make([]byte, maxKeyLen+1)
+	err = db.Put(longKey, []byte("value"))
+	require.ErrorIs(err, errKeyTooLong)
+
+	// The whole batch is rejected if any key is invalid.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key0"), []byte("value0")))
+	require.NoError(batch.Delete(make([]byte, maxKeyLen)))
+	require.NoError(batch.Put(longKey, []byte("value")))
+	require.NoError(batch.Put([]byte("key1"), []byte("value1")))
+	err = batch.Write()
+	require.ErrorIs(err, errKeyTooLong)
+
+	// Views are also validated.
+	_, err = db.NewView(context.Background(), []database.BatchOp{
+		{Key: longKey, Value: []byte("value")},
+	})
+	require.ErrorIs(err, errKeyTooLong)
+
+	// Deletions aren't validated.
+	require.NoError(db.Delete(longKey))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(initialRoot, root)
+	require.Equal(initialBaseDB, dumpDB(t, baseDB))
+
+	value, err := db.Get(make([]byte, maxKeyLen))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	for _, key := range [][]byte{longKey, []byte("key0"), []byte("key1")} {
+		_, err := db.Get(key)
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+
+	// Keys from proofs aren't validated.
+	sourceDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(sourceDB.Put(longKey, []byte("value")))
+	proof, err := sourceDB.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.NoError(db.CommitRangeProof(context.Background(), maybe.Nothing[[]byte](), proof))
+
+	value, err = db.Get(longKey)
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestDatabaseMaxChangedKeysPerCommit(t *testing.T) {
+	require := require.New(t)
+
+	const maxChangedKeys = 4
+	config := newDefaultConfig()
+	config.MaxChangedKeysPerCommit = maxChangedKeys
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	// Just under the limit.
+	ops := make([]database.BatchOp, maxChangedKeys)
+	for i := range ops {
+		ops[i] = database.BatchOp{Key: []byte{byte(i)}, Value: []byte{byte(i)}}
+	}
+	view, err := db.NewView(context.Background(), ops)
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+
+	initialRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	initialBaseDB := dumpDB(t, baseDB)
+
+	// Just over the limit.
+	ops = append(ops, database.BatchOp{Key: []byte{maxChangedKeys}, Value: []byte{maxChangedKeys}})
+	for i := range ops {
+		ops[i].Value = []byte{byte(i), 1}
+	}
+	view, err = db.NewView(context.Background(), ops)
+	require.NoError(err)
+	err = view.CommitToDB(context.Background())
+	require.ErrorIs(err, ErrCommitTooLarge)
+
+	// The same limit applies to batches.
+	batch := db.NewBatch()
+	for _, op := range ops {
+		require.NoError(batch.Put(op.Key, op.Value))
+	}
+	err = batch.Write()
+	require.ErrorIs(err, ErrCommitTooLarge)
+
+	// The database wasn't modified.
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(initialRoot, root)
+	require.Equal(initialBaseDB, dumpDB(t, baseDB))
+	for i := 0; i < maxChangedKeys; i++ {
+		value, err := db.Get([]byte{byte(i)})
+		require.NoError(err)
+		require.Equal([]byte{byte(i)}, value)
+	}
+	_, err = db.Get([]byte{maxChangedKeys})
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestDatabaseOnEvict(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		lock    sync.Mutex
+		evicted = set.Set[path]{}
+	)
+	config := newDefaultConfig()
+	config.NodeCacheSize = 10
+	config.EvictionBatchSize = 5
+	config.OnEvict = func(key SerializedPath) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		evicted.Add(key.deserialize())
+	}
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	keys := make([]path, 100)
+	for i := range keys {
+		key := []byte{byte(i)}
+		require.NoError(db.Put(key, key))
+		keys[i] = newPath(key)
+	}
+
+	// Every node that isn't in the cache anymore must have been reported as
+	// evicted. The callback is asynchronous, so wait for it.
+	require.Eventually(func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		for _, key := range keys {
+			if _, ok := db.nodeCache.Get(key); !ok && !evicted.Contains(key) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.GreaterOrEqual(evicted.Len(), len(keys)-config.NodeCacheSize)
+}
+
+func TestDatabaseOnEvictDropsWhenBehind(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		called  = make(chan struct{})
+		release = make(chan struct{})
+		lock    sync.Mutex
+		evicted []path
+	)
+	config := newDefaultConfig()
+	config.Reg = nil
+	config.OnEvict = func(key SerializedPath) {
+		lock.Lock()
+		isFirst := len(evicted) == 0
+		evicted = append(evicted, key.deserialize())
+		lock.Unlock()
+
+		// Block on the first key until the queue has overflowed.
+		if isFirst {
+			close(called)
+			<-release
+		}
+	}
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	batch := func(i int) []path {
+		return []path{newPath([]byte{byte(i >> 8), byte(i)})}
+	}
+	db.notifyEvicted(batch(0))
+	<-called
+
+	// Fill the queue while the callback is blocked.
+	expected := batch(0)
+	for i := 1; i <= onEvictQueueSize; i++ {
+		db.notifyEvicted(batch(i))
+		expected = append(expected, batch(i)...)
+	}
+
+	// The queue is full, so this batch is dropped.
+	db.notifyEvicted(batch(onEvictQueueSize + 1))
+	metrics := db.metrics.(*mockMetrics)
+	require.Equal(int64(1), metrics.evictsDropped)
+
+	// The queued batches are passed to the callback in order.
+	close(release)
+	require.Eventually(func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return len(evicted) == len(expected)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(expected, evicted)
+}
+
+func TestDatabaseGetAtSeq(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 3
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+	require.Zero(db.CommitSeq())
+
+	key := []byte("key")
+	_, err = db.GetAtSeq(0, key)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(db.Put(key, []byte("value1")))
+	require.Equal(uint64(1), db.CommitSeq())
+	require.NoError(db.Put(key, []byte("value2")))
+	require.NoError(db.Put([]byte("other"), []byte("value")))
+	require.Equal(uint64(3), db.CommitSeq())
+
+	// An empty commit doesn't advance the sequence.
+	require.NoError(db.NewBatch().Write())
+	require.Equal(uint64(3), db.CommitSeq())
+
+	require.NoError(db.Delete(key))
+	require.Equal(uint64(4), db.CommitSeq())
+
+	_, err = db.GetAtSeq(1, key)
+	require.ErrorIs(err, ErrSeqNotRetained)
+
+	val, err := db.GetAtSeq(2, key)
+	require.NoError(err)
+	require.Equal([]byte("value2"), val)
+
+	val, err = db.GetAtSeq(3, key)
+	require.NoError(err)
+	require.Equal([]byte("value2"), val)
+
+	_, err = db.GetAtSeq(4, key)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	_, err = db.GetAtSeq(5, key)
+	require.ErrorIs(err, errSeqNotCommitted)
+}
+
+func TestDatabaseGetValuesConsistent(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// The writer keeps all of [keys] set to the same value.
+	keys := make([][]byte, 100)
+	for i := range keys {
+		keys[i] = []byte(strconv.Itoa(i))
+	}
+	writeAll := func(value []byte) error {
+		batch := db.NewBatch()
+		for _, key := range keys {
+			if err := batch.Put(key, value); err != nil {
+				return err
+			}
+		}
+		return batch.Write()
+	}
+	require.NoError(writeAll([]byte{0}))
+
+	var (
+		eg   errgroup.Group
+		done = make(chan struct{})
+	)
+	eg.Go(func() error {
+		for i := 1; ; i++ {
+			select {
+			case <-done:
+				return nil
+			default:
+			}
+			if err := writeAll([]byte(strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	})
+
+	for i := 0; i < 100; i++ {
+		values, errs := db.GetValuesConsistent(context.Background(), keys)
+		require.Len(values, len(keys))
+		for j := range keys {
+			require.NoError(errs[j])
+			require.Equal(values[0], values[j])
+		}
+	}
+	close(done)
+	require.NoError(eg.Wait())
+
+	// Missing keys are reported per key.
+	values, errs := db.GetValuesConsistent(context.Background(), [][]byte{keys[0], []byte("missing")})
+	require.NoError(errs[0])
+	require.NotNil(values[0])
+	require.ErrorIs(errs[1], database.ErrNotFound)
+}
+
+func TestDatabaseWriteBackFlush(t *testing.T) {
+	require := require.New(t)
+
+	// copyDB simulates a crash by copying what's on disk.
+	copyDB := func(db database.Database) database.Database {
+		copied := memdb.New()
+		it := db.NewIterator()
+		defer it.Release()
+		for it.Next() {
+			require.NoError(copied.Put(it.Key(), it.Value()))
+		}
+		require.NoError(it.Error())
+		return copied
+	}
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.FlushPolicy = WriteBack
+	// Evict nodes so that reads of unflushed nodes can't be served by the
+	// cache.
+	config.NodeCacheSize = 5
+	config.EvictionBatchSize = 1
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	keyValues := make(map[string][]byte)
+	for i := 0; i < 100; i++ {
+		key := []byte(strconv.Itoa(i))
+		keyValues[string(key)] = []byte{byte(i)}
+		require.NoError(db.Put(key, []byte{byte(i)}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Committed changes are visible before they're flushed.
+	for key, value := range keyValues {
+		got, err := db.Get([]byte(key))
+		require.NoError(err)
+		require.Equal(value, got)
+	}
 
-	value, err := freshDB.Get([]byte("key2"))
+	// Unflushed changes are lost on a crash.
+	crashedDB, err := newDB(context.Background(), copyDB(baseDB), newDefaultConfig())
 	require.NoError(err)
-	require.Equal([]byte("2"), value)
+	_, err = crashedDB.Get([]byte("0"))
+	require.ErrorIs(err, database.ErrNotFound)
 
-	freshRoot, err := freshDB.GetMerkleRoot(context.Background())
+	// Flushed changes survive a crash.
+	require.NoError(db.Flush())
+	crashedDB, err = newDB(context.Background(), copyDB(baseDB), newDefaultConfig())
 	require.NoError(err)
-	oldRoot, err := db.GetMerkleRoot(context.Background())
+	for key, value := range keyValues {
+		got, err := crashedDB.Get([]byte(key))
+		require.NoError(err)
+		require.Equal(value, got)
+	}
+	crashedRoot, err := crashedDB.GetMerkleRoot(context.Background())
 	require.NoError(err)
-	require.Equal(oldRoot, freshRoot)
+	require.Equal(root, crashedRoot)
+
+	// Changes made after the last flush are flushed on close.
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Close())
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	got, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), got)
 }
 
-func Test_MerkleDB_GetValues(t *testing.T) {
+func TestDatabaseWriteBackFlushInterval(t *testing.T) {
 	require := require.New(t)
 
-	db, err := getBasicDB()
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	config.FlushPolicy = WriteBack
+	config.FlushInterval = time.Millisecond
+	db, err := newDB(context.Background(), baseDB, config)
 	require.NoError(err)
 
-	writeBasicBatch(t, db)
-	keys := [][]byte{{0}, {1}, {2}, {10}}
-	values, errors := db.GetValues(context.Background(), keys)
-	require.Len(values, len(keys))
-	require.Len(errors, len(keys))
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	nodeDB := prefixdb.New(nodePrefix, baseDB)
+	require.Eventually(func() bool {
+		has, err := nodeDB.Has(newPath([]byte("key")).Bytes())
+		return err == nil && has
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(db.Close())
+}
 
-	// first 3 have values
-	// last was not found
-	require.NoError(errors[0])
-	require.NoError(errors[1])
-	require.NoError(errors[2])
-	require.ErrorIs(errors[3], database.ErrNotFound)
+// writeCountDB counts the batches written to it.
+// failingWriteDB fails batch writes while [fail] is set.
+type failingWriteDB struct {
+	database.Database
 
-	require.Equal([]byte{0}, values[0])
-	require.Equal([]byte{1}, values[1])
-	require.Equal([]byte{2}, values[2])
-	require.Nil(values[3])
+	fail atomic.Bool
 }
 
-func Test_MerkleDB_InsertNil(t *testing.T) {
-	require := require.New(t)
-
-	db, err := getBasicDB()
-	require.NoError(err)
-	batch := db.NewBatch()
-	require.NoError(batch.Put([]byte("key0"), nil))
-	require.NoError(batch.Write())
+func (db *failingWriteDB) NewBatch() database.Batch {
+	return &failingWriteBatch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
+}
 
-	value, err := db.Get([]byte("key0"))
-	require.NoError(err)
-	require.Nil(value)
+type failingWriteBatch struct {
+	database.Batch
+	db *failingWriteDB
+}
 
-	value, err = getNodeValue(db, "key0")
-	require.NoError(err)
-	require.Nil(value)
+func (b *failingWriteBatch) Write() error {
+	if b.db.fail.Load() {
+		return errTransient
+	}
+	return b.Batch.Write()
 }
 
-func Test_MerkleDB_InsertAndRetrieve(t *testing.T) {
+func TestDatabaseWriteBackFlushIntervalFailure(t *testing.T) {
 	require := require.New(t)
 
-	db, err := getBasicDB()
+	baseDB := &failingWriteDB{Database: memdb.New()}
+	config := newDefaultConfig()
+	config.FlushPolicy = WriteBack
+	config.FlushInterval = time.Millisecond
+	config.Reg = nil
+	db, err := newDB(context.Background(), baseDB, config)
 	require.NoError(err)
 
-	// value hasn't been inserted so shouldn't exist
-	value, err := db.Get([]byte("key"))
-	require.ErrorIs(err, database.ErrNotFound)
-	require.Nil(value)
-
+	baseDB.fail.Store(true)
 	require.NoError(db.Put([]byte("key"), []byte("value")))
 
-	value, err = db.Get([]byte("key"))
-	require.NoError(err)
-	require.Equal([]byte("value"), value)
+	// Failed flushes are reported by the health check and counted.
+	require.Eventually(func() bool {
+		_, err := db.HealthCheck(context.Background())
+		return errors.Is(err, errFlushFailed)
+	}, 5*time.Second, time.Millisecond)
+	metrics := db.metrics.(*mockMetrics)
+	metrics.lock.Lock()
+	require.Positive(metrics.flushFailures)
+	metrics.lock.Unlock()
+
+	// Flushes keep being attempted and succeed once the writes do.
+	baseDB.fail.Store(false)
+	nodeDB := prefixdb.New(nodePrefix, baseDB.Database)
+	require.Eventually(func() bool {
+		has, err := nodeDB.Has(newPath([]byte("key")).Bytes())
+		return err == nil && has
+	}, 5*time.Second, time.Millisecond)
+	require.Eventually(func() bool {
+		_, err := db.HealthCheck(context.Background())
+		return err == nil
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(db.Close())
 }
 
-func Test_MerkleDB_HealthCheck(t *testing.T) {
-	require := require.New(t)
+type writeCountDB struct {
+	database.Database
+	writes int
+}
 
-	db, err := getBasicDB()
-	require.NoError(err)
-	val, err := db.HealthCheck(context.Background())
-	require.NoError(err)
-	require.Nil(val)
+func (db *writeCountDB) NewBatch() database.Batch {
+	return &writeCountBatch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
 }
 
-func Test_MerkleDB_Overwrite(t *testing.T) {
+type writeCountBatch struct {
+	database.Batch
+	db *writeCountDB
+}
+
+func (b *writeCountBatch) Write() error {
+	b.db.writes++
+	return b.Batch.Write()
+}
+
+func TestDatabaseBatchedCommits(t *testing.T) {
 	require := require.New(t)
 
-	db, err := getBasicDB()
+	baseDB := &writeCountDB{
+		Database: memdb.New(),
+	}
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
 	require.NoError(err)
 
-	require.NoError(db.Put([]byte("key"), []byte("value0")))
+	err = db.EndBatchedCommits()
+	require.ErrorIs(err, ErrNotBatchingCommits)
 
-	value, err := db.Get([]byte("key"))
+	require.NoError(db.BeginBatchedCommits())
+	err = db.BeginBatchedCommits()
+	require.ErrorIs(err, ErrBatchingCommits)
+
+	writes := baseDB.writes
+	for i := 0; i < 3; i++ {
+		key := []byte(strconv.Itoa(i))
+		view, err := db.NewView(context.Background(), []database.BatchOp{
+			{Key: key, Value: key},
+		})
+		require.NoError(err)
+		viewRoot, err := view.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		require.NoError(view.CommitToDB(context.Background()))
+
+		// Each commit is visible before the batch is written.
+		root, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		require.Equal(viewRoot, root)
+		value, err := db.Get(key)
+		require.NoError(err)
+		require.Equal(key, value)
+	}
+	require.Equal(writes, baseDB.writes)
+
+	require.NoError(db.EndBatchedCommits())
+	require.Equal(writes+1, baseDB.writes)
+	root, err := db.GetMerkleRoot(context.Background())
 	require.NoError(err)
-	require.Equal([]byte("value0"), value)
+	require.NoError(db.Close())
 
-	require.NoError(db.Put([]byte("key"), []byte("value1")))
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	for i := 0; i < 3; i++ {
+		key := []byte(strconv.Itoa(i))
+		value, err := db.Get(key)
+		require.NoError(err)
+		require.Equal(key, value)
+	}
+	reopenedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, reopenedRoot)
 
-	value, err = db.Get([]byte("key"))
+	// Commits that are still batched are written on close.
+	require.NoError(db.BeginBatchedCommits())
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Close())
+	db, err = newDB(context.Background(), baseDB, newDefaultConfig())
 	require.NoError(err)
-	require.Equal([]byte("value1"), value)
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
 }
 
-func Test_MerkleDB_Delete(t *testing.T) {
+// Iteration and cache eviction concurrent with batched commits must not race
+// with the batches being started and written. Run with -race.
+func TestDatabaseBatchedCommitsConcurrentReads(t *testing.T) {
 	require := require.New(t)
 
-	db, err := getBasicDB()
-	require.NoError(err)
-
-	require.NoError(db.Put([]byte("key"), []byte("value0")))
+	config := newDefaultConfig()
+	config.SeparateValueStore = true
+	// Evict nodes so that they're written while commits are batched.
+	config.NodeCacheSize = 8
+	config.EvictionBatchSize = 1
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	const numKeys = 50
+	keys := make([][]byte, numKeys)
+	for i := range keys {
+		keys[i] = []byte(strconv.Itoa(i))
+		require.NoError(db.Put(keys[i], make([]byte, HashLength)))
+	}
 
-	value, err := db.Get([]byte("key"))
-	require.NoError(err)
-	require.Equal([]byte("value0"), value)
+	done := make(chan struct{})
+	var eg errgroup.Group
+	eg.Go(func() error {
+		for {
+			select {
+			case <-done:
+				return nil
+			default:
+			}
+			it := db.NewIterator()
+			for it.Next() {
+			}
+			err := it.Error()
+			it.Release()
+			if err != nil {
+				return err
+			}
+		}
+	})
+	eg.Go(func() error {
+		for {
+			select {
+			case <-done:
+				return nil
+			default:
+			}
+			// Unpinning evicts nodes without holding [db.lock].
+			if err := db.Pin(keys[:1]); err != nil {
+				return err
+			}
+			db.Unpin(keys[:1])
+			if _, err := db.Get(keys[numKeys-1]); err != nil {
+				return err
+			}
+		}
+	})
 
-	require.NoError(db.Delete([]byte("key")))
+	for i := 0; i < 20; i++ {
+		require.NoError(db.BeginBatchedCommits())
+		for j := 0; j < 3; j++ {
+			value := make([]byte, HashLength)
+			value[0] = byte(i)
+			require.NoError(db.Put(keys[(i*3+j)%numKeys], value))
+		}
+		require.NoError(db.EndBatchedCommits())
+	}
+	close(done)
+	require.NoError(eg.Wait())
 
-	value, err = db.Get([]byte("key"))
-	require.ErrorIs(err, database.ErrNotFound)
-	require.Nil(value)
+	// Every batched commit was written to disk.
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NoError(db.Close())
+	config.Reg = prometheus.NewRegistry()
+	db, err = newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+	reopenedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, reopenedRoot)
+	for i := 0; i < numKeys; i++ {
+		_, err := db.Get(keys[i])
+		require.NoError(err)
+	}
 }
 
-func Test_MerkleDB_DeleteMissingKey(t *testing.T) {
+func TestDatabaseGetOrDefault(t *testing.T) {
 	require := require.New(t)
 
 	db, err := getBasicDB()
 	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	def := []byte("default")
+
+	// The stored value is returned as a copy.
+	value := db.GetOrDefault([]byte("key"), def)
+	require.Equal([]byte("value"), value)
+	value[0] = 'V'
+	require.Equal([]byte("value"), db.GetOrDefault([]byte("key"), def))
+
+	// The default is returned for missing keys.
+	value = db.GetOrDefault([]byte("missing"), def)
+	require.Equal(def, value)
+	require.Same(&def[0], &value[0])
+	require.Nil(db.GetOrDefault([]byte("missing"), nil))
 
 	require.NoError(db.Delete([]byte("key")))
+	require.Equal(def, db.GetOrDefault([]byte("key"), def))
 }
 
-// Test that untracked views aren't persisted to [db.childViews].
-func TestDatabaseNewUntrackedView(t *testing.T) {
+func TestDatabaseCountPrefix(t *testing.T) {
 	require := require.New(t)
 
 	db, err := getBasicDB()
 	require.NoError(err)
 
-	// Create a new untracked view.
-	view, err := db.newUntrackedView([]database.BatchOp{{Key: []byte{1}, Value: []byte{1}}})
-	require.NoError(err)
-	require.Empty(db.childViews)
+	expectedCounts := map[string]int{
+		"a":   3,
+		"ab":  2,
+		"abc": 1,
+		"b":   5,
+		"c":   0,
+		"":    8,
+	}
 
-	// Commit the view
-	require.NoError(view.CommitToDB(context.Background()))
+	batch := db.NewBatch()
+	for _, key := range []string{"a", "ab", "abc", "b0", "b1", "b2", "b3", "b4"} {
+		require.NoError(batch.Put([]byte(key), []byte(key)))
+	}
+	require.NoError(batch.Write())
 
-	// The untracked view should not be tracked by the parent database.
-	require.Empty(db.childViews)
+	for prefix, expectedCount := range expectedCounts {
+		count, err := db.CountPrefix(context.Background(), []byte(prefix))
+		require.NoError(err)
+		require.Equal(expectedCount, count, "prefix %q", prefix)
+	}
+
+	// Deleted keys aren't counted.
+	require.NoError(db.Delete([]byte("b0")))
+	count, err := db.CountPrefix(context.Background(), []byte("b"))
+	require.NoError(err)
+	require.Equal(4, count)
+
+	require.NoError(db.Close())
+	_, err = db.CountPrefix(context.Background(), nil)
+	require.ErrorIs(err, database.ErrClosed)
 }
 
-// Test that tracked views are persisted to [db.childViews].
-func TestDatabaseNewViewTracked(t *testing.T) {
+func TestDatabaseNewKeyIterator(t *testing.T) {
 	require := require.New(t)
 
 	db, err := getBasicDB()
 	require.NoError(err)
 
-	// Create a new tracked view.
-	view, err := db.NewView(context.Background(), []database.BatchOp{{Key: []byte{1}, Value: []byte{1}}})
-	require.NoError(err)
-	require.Len(db.childViews, 1)
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	keys := make([][]byte, 0, 500)
+	batch := db.NewBatch()
+	for i := 0; i < 500; i++ {
+		key := make([]byte, r.Intn(4)+1)
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(64))
+		_, _ = r.Read(value)
+		keys = append(keys, key)
+		require.NoError(batch.Put(key, value))
+	}
+	require.NoError(batch.Write())
 
-	// Commit the view
-	require.NoError(view.CommitToDB(context.Background()))
+	// Deleted keys must be skipped.
+	for _, key := range keys[:100] {
+		require.NoError(db.Delete(key))
+	}
 
-	// The untracked view should be tracked by the parent database.
-	require.Contains(db.childViews, view)
-	require.Len(db.childViews, 1)
+	tests := []struct {
+		start  []byte
+		prefix []byte
+	}{
+		{},
+		{start: keys[200]},
+		{prefix: keys[300][:1]},
+		{start: keys[400], prefix: keys[400][:1]},
+	}
+	for _, test := range tests {
+		expectedIt := db.NewIteratorWithStartAndPrefix(test.start, test.prefix)
+		it := db.NewKeyIterator(test.start, test.prefix)
+		for expectedIt.Next() {
+			require.True(it.Next())
+			require.Equal(expectedIt.Key(), it.Key())
+			require.Nil(it.Value())
+		}
+		require.False(it.Next())
+		require.NoError(expectedIt.Error())
+		require.NoError(it.Error())
+		expectedIt.Release()
+		it.Release()
+	}
 }
 
 func TestDatabaseCommitChanges(t *testing.T) {
@@ -685,6 +3341,159 @@ func Test_MerkleDB_Random_Insert_Ordering(t *testing.T) {
 	}
 }
 
+func Test_MerkleDB_ViewBuildConcurrency(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	ops := make([]database.BatchOp, 0, 1_000)
+	for i := 0; i < 1_000; i++ {
+		key := make([]byte, r.Intn(32))
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(32))
+		_, _ = r.Read(value)
+		ops = append(ops, database.BatchOp{
+			Key:    key,
+			Value:  value,
+			Delete: r.Intn(10) == 0,
+		})
+	}
+
+	var expectedRoot ids.ID
+	for i, concurrency := range []int{1, 2, 4, 16, 0} {
+		config := newDefaultConfig()
+		config.ViewBuildConcurrency = concurrency
+		db, err := newDB(context.Background(), memdb.New(), config)
+		require.NoError(err)
+
+		view, err := db.NewView(context.Background(), ops)
+		require.NoError(err)
+		root, err := view.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		if i == 0 {
+			expectedRoot = root
+			continue
+		}
+		require.Equal(expectedRoot, root, "concurrency %d", concurrency)
+	}
+}
+
+func Test_MerkleDB_VerifyOnCommit(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	config := newDefaultConfig()
+	config.VerifyOnCommit = true
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	const (
+		totalState = 500
+		batchSize  = 50
+	)
+	ops := make([]database.BatchOp, 0, totalState)
+	for i := 0; i < totalState; i++ {
+		key := make([]byte, r.Intn(50))
+		_, err := r.Read(key)
+		require.NoError(err)
+		if len(ops) > 0 && r.Intn(100) < 10 {
+			// prefix the key with an existing key
+			key = append(slices.Clone(ops[r.Intn(len(ops))].Key), key...)
+		}
+		value := make([]byte, r.Intn(50))
+		_, err = r.Read(value)
+		require.NoError(err)
+		ops = append(ops, database.BatchOp{Key: key, Value: value})
+	}
+	r.Shuffle(totalState, func(i, j int) {
+		ops[i], ops[j] = ops[j], ops[i]
+	})
+
+	// Every commit recalculates the root and compares it to the
+	// incrementally calculated root.
+	for start := 0; start < totalState; start += batchSize {
+		view, err := db.NewView(context.Background(), ops[start:start+batchSize])
+		require.NoError(err)
+		require.NoError(view.CommitToDB(context.Background()))
+	}
+	for _, op := range ops[:batchSize] {
+		require.NoError(db.Delete(op.Key))
+	}
+
+	// Write a key/value pair to disk without updating the trie.
+	n := newNode(nil, newPath([]byte("unexpected")))
+	n.setValue(maybe.Some([]byte("value")))
+	require.NoError(db.nodeDB.Put(n.key.Bytes(), n.marshal()))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// The commit is rejected before anything is written.
+	err = db.Put([]byte("key"), []byte("value"))
+	require.ErrorIs(err, errRootMismatch)
+	_, err = db.Get([]byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(root, newRoot)
+}
+
+func TestDatabaseVerifyIntegrity(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	nodeDB := prefixdb.New(nodePrefix, baseDB)
+	// Reopens the database so that nodes are read from [baseDB].
+	reopen := func(db *merkleDB) *merkleDB {
+		if db != nil {
+			require.NoError(db.Close())
+		}
+		db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+		require.NoError(err)
+		return db
+	}
+
+	db := reopen(nil)
+	for i := 0; i < 10; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		require.NoError(db.Put(key, key))
+	}
+	require.NoError(db.VerifyIntegrity(context.Background()))
+
+	db = reopen(db)
+	require.NoError(db.VerifyIntegrity(context.Background()))
+
+	// Change the value of a node on disk without updating its ancestors.
+	corruptedKey := newPath([]byte("key1")).Bytes()
+	nodeBytes, err := nodeDB.Get(corruptedKey)
+	require.NoError(err)
+	n, err := parseNode(path(corruptedKey), nodeBytes)
+	require.NoError(err)
+	n.setValue(maybe.Some([]byte("corrupted")))
+	require.NoError(nodeDB.Put(corruptedKey, n.marshal()))
+
+	db = reopen(db)
+	err = db.VerifyIntegrity(context.Background())
+	require.ErrorIs(err, errNodeIDMismatch)
+
+	// Restore the node and delete another one.
+	require.NoError(nodeDB.Put(corruptedKey, nodeBytes))
+	require.NoError(nodeDB.Delete(newPath([]byte("key2")).Bytes()))
+
+	db = reopen(db)
+	err = db.VerifyIntegrity(context.Background())
+	require.ErrorIs(err, errDanglingChild)
+
+	require.NoError(db.Close())
+	err = db.VerifyIntegrity(context.Background())
+	require.ErrorIs(err, database.ErrClosed)
+}
+
 func Test_MerkleDB_RandomCases(t *testing.T) {
 	require := require.New(t)
 
@@ -0,0 +1,109 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+const hexDigits = "0123456789abcdef"
+
+// A node to be written by [merkleDB.DumpN].
+type dumpEntry struct {
+	node *node
+	id   ids.ID
+	// The nibbles between [node]'s parent and [node], including the child
+	// index. Empty for the root.
+	fragment path
+	depth    int
+}
+
+// Dump writes every node of the trie to [w], depth first from the root.
+// See [DumpN].
+func (db *merkleDB) Dump(w io.Writer) error {
+	return db.DumpN(w, math.MaxInt)
+}
+
+// DumpN writes up to [maxNodes] nodes of the trie to [w], depth first from the
+// root. Each node is written on its own line, indented by its depth, with its
+// path fragment, child indices, whether it has a value, and its ID. If the
+// trie has more than [maxNodes] nodes, a final line noting the truncation is
+// written. The trie isn't modified.
+func (db *merkleDB) DumpN(w io.Writer, maxNodes int) error {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	stack := []dumpEntry{{
+		node: db.root,
+		id:   db.root.id,
+	}}
+	for numNodes := 0; len(stack) > 0; numNodes++ {
+		if numNodes >= maxNodes {
+			_, err := fmt.Fprintf(w, "truncated after %d nodes\n", maxNodes)
+			return err
+		}
+
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		n := entry.node
+		indices := maps.Keys(n.children)
+		slices.Sort(indices)
+		if _, err := fmt.Fprintf(
+			w,
+			"%sfragment=%s children=[%s] value=%t id=%s\n",
+			strings.Repeat("  ", entry.depth),
+			nibblesString(entry.fragment),
+			nibblesString(path(indices)),
+			n.hasValue(),
+			entry.id,
+		); err != nil {
+			return err
+		}
+
+		// Push in reverse so children are written in increasing order.
+		for i := len(indices) - 1; i >= 0; i-- {
+			index := indices[i]
+			child := n.children[index]
+			fragment := path(index) + child.compressedPath
+			childNode, err := db.getNode(n.key + fragment)
+			if err != nil {
+				return err
+			}
+			stack = append(stack, dumpEntry{
+				node:     childNode,
+				id:       child.id,
+				fragment: fragment,
+				depth:    entry.depth + 1,
+			})
+		}
+	}
+	return nil
+}
+
+// nibblesString returns the hex digit of each nibble in [p].
+func nibblesString(p path) string {
+	var sb strings.Builder
+	sb.Grow(len(p))
+	for i := 0; i < len(p); i++ {
+		sb.WriteByte(hexDigits[p[i]])
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabaseDump(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte{0x01}, []byte("a")))
+	require.NoError(db.Put([]byte{0x02}, []byte("b")))
+	require.NoError(db.Put([]byte{0x31}, []byte("c")))
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	child0 := db.root.children[0]
+	child3 := db.root.children[3]
+	node0, err := db.getNode(path([]byte{0}))
+	require.NoError(err)
+
+	var sb strings.Builder
+	require.NoError(db.Dump(&sb))
+	require.Equal(
+		[]string{
+			fmt.Sprintf("fragment= children=[03] value=false id=%s", root),
+			fmt.Sprintf("  fragment=0 children=[12] value=false id=%s", child0.id),
+			fmt.Sprintf("    fragment=1 children=[] value=true id=%s", node0.children[1].id),
+			fmt.Sprintf("    fragment=2 children=[] value=true id=%s", node0.children[2].id),
+			fmt.Sprintf("  fragment=31 children=[] value=true id=%s", child3.id),
+		},
+		strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n"),
+	)
+
+	sb.Reset()
+	require.NoError(db.DumpN(&sb, 2))
+	require.Equal(
+		[]string{
+			fmt.Sprintf("fragment= children=[03] value=false id=%s", root),
+			fmt.Sprintf("  fragment=0 children=[12] value=false id=%s", child0.id),
+			"truncated after 2 nodes",
+		},
+		strings.Split(strings.TrimSuffix(sb.String(), "\n"), "\n"),
+	)
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ava-labs/avalanchego/utils/math"
+)
+
+const defaultTargetEvictionLatency = 10 * time.Millisecond
+
+var errInvalidEvictionBatchSizeBounds = errors.New("invalid eviction batch size bounds")
+
+// adaptiveEvictionBatchSize tunes the number of nodes written to disk per
+// eviction based on how long previous writes took.
+// The batch size doubles when a write takes less than half of
+// [targetLatency] and halves when a write takes longer than [targetLatency].
+type adaptiveEvictionBatchSize struct {
+	min, max      int
+	targetLatency time.Duration
+	current       int
+}
+
+func newAdaptiveEvictionBatchSize(config Config) (*adaptiveEvictionBatchSize, error) {
+	if config.MinEvictionBatchSize <= 0 || config.MaxEvictionBatchSize < config.MinEvictionBatchSize {
+		return nil, errInvalidEvictionBatchSizeBounds
+	}
+
+	targetLatency := config.TargetEvictionLatency
+	if targetLatency <= 0 {
+		targetLatency = defaultTargetEvictionLatency
+	}
+	return &adaptiveEvictionBatchSize{
+		min:           config.MinEvictionBatchSize,
+		max:           config.MaxEvictionBatchSize,
+		targetLatency: targetLatency,
+		current: math.Min(
+			math.Max(config.EvictionBatchSize, config.MinEvictionBatchSize),
+			config.MaxEvictionBatchSize,
+		),
+	}, nil
+}
+
+// update adjusts the batch size after a write that took [latency] and returns
+// the new batch size.
+func (a *adaptiveEvictionBatchSize) update(latency time.Duration) int {
+	switch {
+	case latency < a.targetLatency/2:
+		a.current = math.Min(2*a.current, a.max)
+	case latency > a.targetLatency:
+		a.current = math.Max(a.current/2, a.min)
+	}
+	return a.current
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+// latencyDB delays every batch write by [delay].
+type latencyDB struct {
+	database.Database
+	delay time.Duration
+}
+
+func (db *latencyDB) NewBatch() database.Batch {
+	return &latencyBatch{
+		Batch: db.Database.NewBatch(),
+		db:    db,
+	}
+}
+
+type latencyBatch struct {
+	database.Batch
+	db *latencyDB
+}
+
+func (b *latencyBatch) Write() error {
+	time.Sleep(b.db.delay)
+	return b.Batch.Write()
+}
+
+func TestAdaptiveEvictionBatchSize(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := &latencyDB{
+		Database: memdb.New(),
+	}
+	config := newDefaultConfig()
+	config.Reg = nil
+	config.NodeCacheSize = 10
+	config.EvictionBatchSize = 4
+	config.AdaptiveEviction = true
+	config.MinEvictionBatchSize = 1
+	config.MaxEvictionBatchSize = 64
+	config.TargetEvictionLatency = 10 * time.Millisecond
+
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+	require.Equal(4, db.adaptiveEviction.current)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	putRandomKey := func() {
+		key := make([]byte, 8)
+		_, _ = r.Read(key)
+		require.NoError(db.Put(key, key))
+	}
+
+	// Fast writes grow the batch size up to the maximum.
+	for i := 0; i < 1_000 && db.adaptiveEviction.current < config.MaxEvictionBatchSize; i++ {
+		putRandomKey()
+	}
+	require.Equal(config.MaxEvictionBatchSize, db.adaptiveEviction.current)
+	require.Equal(int64(config.MaxEvictionBatchSize), db.metrics.(*mockMetrics).evictionBatchSize)
+
+	// Slow writes shrink the batch size.
+	baseDB.delay = 2 * config.TargetEvictionLatency
+	for i := 0; i < 1_000 && db.adaptiveEviction.current == config.MaxEvictionBatchSize; i++ {
+		putRandomKey()
+	}
+	require.Less(db.adaptiveEviction.current, config.MaxEvictionBatchSize)
+	require.Equal(int64(db.adaptiveEviction.current), db.metrics.(*mockMetrics).evictionBatchSize)
+}
+
+func TestAdaptiveEvictionBatchSizeUpdate(t *testing.T) {
+	require := require.New(t)
+
+	a, err := newAdaptiveEvictionBatchSize(Config{
+		EvictionBatchSize:     100,
+		MinEvictionBatchSize:  2,
+		MaxEvictionBatchSize:  16,
+		TargetEvictionLatency: 10 * time.Millisecond,
+	})
+	require.NoError(err)
+	// The initial batch size is bounded.
+	require.Equal(16, a.current)
+
+	// Latency between half the target and the target doesn't change the
+	// batch size.
+	require.Equal(16, a.update(7*time.Millisecond))
+
+	require.Equal(8, a.update(20*time.Millisecond))
+	require.Equal(4, a.update(20*time.Millisecond))
+	require.Equal(2, a.update(20*time.Millisecond))
+	require.Equal(2, a.update(20*time.Millisecond))
+
+	require.Equal(4, a.update(time.Millisecond))
+	require.Equal(8, a.update(time.Millisecond))
+	require.Equal(16, a.update(time.Millisecond))
+	require.Equal(16, a.update(time.Millisecond))
+
+	_, err = newAdaptiveEvictionBatchSize(Config{
+		MinEvictionBatchSize: 4,
+		MaxEvictionBatchSize: 2,
+	})
+	require.ErrorIs(err, errInvalidEvictionBatchSizeBounds)
+}
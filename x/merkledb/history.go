@@ -7,15 +7,22 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils"
 	"github.com/ava-labs/avalanchego/utils/buffer"
 	"github.com/ava-labs/avalanchego/utils/maybe"
 	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/timer/mockable"
 )
 
-var ErrInsufficientHistory = errors.New("insufficient history to generate proof")
+var (
+	ErrInsufficientHistory = errors.New("insufficient history to generate proof")
+	ErrRootNotInHistory    = errors.New("root not in history")
+	ErrSeqNotRetained      = errors.New("sequence number is no longer in history")
+	errSeqNotCommitted     = errors.New("sequence number hasn't been committed")
+)
 
 // stores previous trie states
 type trieHistory struct {
@@ -31,7 +38,11 @@ type trieHistory struct {
 	history buffer.Deque[*changeSummaryAndInsertNumber]
 
 	// Each change is tagged with this monotonic increasing number.
+	// It's incremented even when history isn't being recorded.
 	nextInsertNumber uint64
+
+	// Used to tag each change with the time it was recorded.
+	clock mockable.Clock
 }
 
 // Tracks the beginning and ending state of a value.
@@ -47,6 +58,8 @@ type changeSummaryAndInsertNumber struct {
 	// Another changeSummaryAndInsertNumber with a greater
 	// [insertNumber] means that change was after this one.
 	insertNumber uint64
+	// The time at which the change was recorded.
+	commitTime time.Time
 }
 
 // Tracks all of the node and value changes that resulted in the rootID.
@@ -273,8 +286,67 @@ func (th *trieHistory) getChangesToGetToRoot(rootID ids.ID, start maybe.Maybe[[]
 	return combinedChanges, nil
 }
 
+// Returns the root ID resulting from the change with insert number [seq].
+// Returns [ErrSeqNotRetained] if the change is no longer in the history.
+func (th *trieHistory) getRootAtSeq(seq uint64) (ids.ID, error) {
+	if seq >= th.nextInsertNumber {
+		return ids.Empty, fmt.Errorf("%w: %d > %d", errSeqNotCommitted, seq, th.nextInsertNumber-1)
+	}
+	oldestInsertNumber := th.nextInsertNumber - uint64(th.history.Len())
+	if seq < oldestInsertNumber {
+		return ids.Empty, fmt.Errorf("%w: %d < %d", ErrSeqNotRetained, seq, oldestInsertNumber)
+	}
+	changes, _ := th.history.Index(int(seq - oldestInsertNumber))
+	return changes.rootID, nil
+}
+
+// Returns the keys changed by the changes recorded after [t].
+// Returns [ErrInsufficientHistory] if [t] is before the oldest change in the
+// history, since changes after [t] may have been removed from the history.
+func (th *trieHistory) getKeysChangedSince(t time.Time) (set.Set[path], error) {
+	oldestChange, ok := th.history.PeekLeft()
+	if !ok {
+		return nil, fmt.Errorf("%w: no changes in history", ErrInsufficientHistory)
+	}
+	if t.Before(oldestChange.commitTime) {
+		return nil, fmt.Errorf("%w: %s is before the oldest change at %s", ErrInsufficientHistory, t, oldestChange.commitTime)
+	}
+
+	changedKeys := set.Set[path]{}
+	for i := th.history.Len() - 1; i >= 0; i-- {
+		changes, _ := th.history.Index(i)
+		if !changes.commitTime.After(t) {
+			break
+		}
+		for key, valueChange := range changes.values {
+			// Skip changes that set a key to its existing value.
+			if valueChange.before.HasValue() == valueChange.after.HasValue() &&
+				bytes.Equal(valueChange.before.Value(), valueChange.after.Value()) {
+				continue
+			}
+			changedKeys.Add(key)
+		}
+	}
+	return changedKeys, nil
+}
+
+// Returns the number of key-value pair changes in the history.
+// This is an upper bound on the number of keys changed between any two roots
+// in the history.
+func (th *trieHistory) numValueChanges() int {
+	numChanges := 0
+	for i := 0; i < th.history.Len(); i++ {
+		changes, _ := th.history.Index(i)
+		numChanges += len(changes.values)
+	}
+	return numChanges
+}
+
 // record the provided set of changes in the history
 func (th *trieHistory) record(changes *changeSummary) {
+	insertNumber := th.nextInsertNumber
+	th.nextInsertNumber++
+
 	// we aren't recording history so noop
 	if th.maxHistoryLen == 0 {
 		return
@@ -294,13 +366,14 @@ func (th *trieHistory) record(changes *changeSummary) {
 
 	changesAndIndex := &changeSummaryAndInsertNumber{
 		changeSummary: changes,
-		insertNumber:  th.nextInsertNumber,
+		insertNumber:  insertNumber,
+		commitTime:    th.clock.Time(),
 	}
-	th.nextInsertNumber++
 
 	// Add [changes] to the sorted change list.
 	_ = th.history.PushRight(changesAndIndex)
 
 	// Mark that this is the most recent change resulting in [changes.rootID].
 	th.lastChanges[changes.rootID] = changesAndIndex
+
 }
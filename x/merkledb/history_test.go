@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/utils/maybe"
@@ -390,6 +391,52 @@ func Test_History_ExcessDeletes(t *testing.T) {
 	require.NoError(newProof.Verify(context.Background(), maybe.Some([]byte("k")), maybe.Some([]byte("key3")), origRootID))
 }
 
+func Test_History_ChangeProofAcrossDeletion(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 3
+	config.NodeCacheSize = 0
+	db, err := newDB(
+		context.Background(),
+		memdb.New(),
+		config,
+	)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+	require.NoError(db.Put([]byte("key2"), []byte("value2")))
+	startRoot := db.getMerkleRoot()
+
+	// A db at [startRoot] to verify change proofs against.
+	verifyDB, err := newDB(
+		context.Background(),
+		memdb.New(),
+		newDefaultConfig(),
+	)
+	require.NoError(err)
+	require.NoError(verifyDB.Put([]byte("key1"), []byte("value1")))
+	require.NoError(verifyDB.Put([]byte("key2"), []byte("value2")))
+
+	require.NoError(db.Delete([]byte("key1")))
+	require.NoError(db.Put([]byte("key2"), []byte("value3")))
+	endRoot := db.getMerkleRoot()
+
+	// The deleted node is no longer stored, but the deletion is still in the
+	// history.
+	proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.Equal([]KeyChange{
+		{Key: []byte("key1"), Value: maybe.Nothing[[]byte]()},
+		{Key: []byte("key2"), Value: maybe.Some([]byte("value3"))},
+	}, proof.KeyChanges)
+	require.NoError(verifyDB.VerifyChangeProof(context.Background(), proof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), endRoot))
+
+	// The deletion is no longer in the history.
+	require.NoError(db.Put([]byte("key3"), []byte("value3")))
+	_, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.ErrorIs(err, ErrInsufficientHistory)
+}
+
 func Test_History_DontIncludeAllNodes(t *testing.T) {
 	require := require.New(t)
 
@@ -751,3 +798,53 @@ func TestHistoryGetChangesToRoot(t *testing.T) {
 		})
 	}
 }
+
+func Test_History_KeysChangedSince(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 3
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	start := time.Now().Add(time.Hour)
+	commitAt := func(offset time.Duration, ops ...database.BatchOp) {
+		db.history.clock.Set(start.Add(offset))
+		view, err := db.NewView(context.Background(), ops)
+		require.NoError(err)
+		require.NoError(view.CommitToDB(context.Background()))
+	}
+
+	commitAt(0, database.BatchOp{Key: []byte("k1"), Value: []byte("v1")})
+	commitAt(
+		2*time.Second,
+		database.BatchOp{Key: []byte("k2"), Value: []byte("v2")},
+		database.BatchOp{Key: []byte("k3"), Value: []byte("v3")},
+	)
+	commitAt(
+		4*time.Second,
+		database.BatchOp{Key: []byte("k1"), Delete: true},
+		// Setting a key to its existing value doesn't change it.
+		database.BatchOp{Key: []byte("k3"), Value: []byte("v3")},
+	)
+
+	keys, err := db.KeysChangedSince(start.Add(time.Second))
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("k1"), []byte("k2"), []byte("k3")}, keys)
+
+	keys, err = db.KeysChangedSince(start.Add(2 * time.Second))
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("k1")}, keys)
+
+	keys, err = db.KeysChangedSince(start.Add(4 * time.Second))
+	require.NoError(err)
+	require.Empty(keys)
+
+	// The oldest commit in the history was made at [start].
+	keys, err = db.KeysChangedSince(start)
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("k1"), []byte("k2"), []byte("k3")}, keys)
+
+	_, err = db.KeysChangedSince(start.Add(-time.Second))
+	require.ErrorIs(err, ErrInsufficientHistory)
+}
@@ -5,13 +5,18 @@ package merkledb
 
 import "github.com/ava-labs/avalanchego/database"
 
-var _ database.Iterator = (*iterator)(nil)
+var (
+	_ database.Iterator = (*iterator)(nil)
+	_ database.Iterator = (*keyIterator)(nil)
+	_ database.Iterator = (*keyValueIterator)(nil)
+)
 
 type iterator struct {
 	db       *merkleDB
 	nodeIter database.Iterator
 	current  *node
 	err      error
+	released bool
 }
 
 func (i *iterator) Error() error {
@@ -42,7 +47,7 @@ func (i *iterator) Next() bool {
 	}
 	for i.nodeIter.Next() {
 		i.db.metrics.IOKeyRead()
-		n, err := parseNode(path(i.nodeIter.Key()), i.nodeIter.Value())
+		n, err := i.db.parseNode(path(i.nodeIter.Key()), i.nodeIter.Value())
 		if err != nil {
 			i.err = err
 			return false
@@ -60,4 +65,101 @@ func (i *iterator) Next() bool {
 
 func (i *iterator) Release() {
 	i.nodeIter.Release()
+	if !i.released {
+		i.released = true
+		i.db.openIterators.Add(-1)
+	}
+}
+
+// keyIterator iterates over the keys in the database. Only the leading byte
+// of each node is decoded, so values are never parsed or hashed.
+type keyIterator struct {
+	db       *merkleDB
+	nodeIter database.Iterator
+	current  []byte
+	err      error
+}
+
+func (i *keyIterator) Error() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.nodeIter.Error()
+}
+
+func (i *keyIterator) Key() []byte {
+	return i.current
+}
+
+// Value always returns nil.
+func (*keyIterator) Value() []byte {
+	return nil
+}
+
+func (i *keyIterator) Next() bool {
+	i.current = nil
+	if i.err != nil {
+		return false
+	}
+	for i.nodeIter.Next() {
+		i.db.metrics.IOKeyRead()
+		hasValue, err := codec.decodeDBNodeHasValue(i.nodeIter.Value())
+		if err != nil {
+			i.err = err
+			return false
+		}
+		if hasValue {
+			i.current = path(i.nodeIter.Key()).Serialize().Value
+			return true
+		}
+	}
+	if i.err == nil {
+		i.err = i.nodeIter.Error()
+	}
+	return false
+}
+
+func (i *keyIterator) Release() {
+	i.nodeIter.Release()
+}
+
+// keyValueIterator iterates over key/value pairs held in memory.
+type keyValueIterator struct {
+	initialized bool
+	// Sorted by increasing key. The first element is the current key/value
+	// pair once initialized.
+	keyValues []KeyValue
+}
+
+func (i *keyValueIterator) Next() bool {
+	if !i.initialized {
+		i.initialized = true
+		return len(i.keyValues) > 0
+	}
+	if len(i.keyValues) > 0 {
+		i.keyValues = i.keyValues[1:]
+	}
+	return len(i.keyValues) > 0
+}
+
+func (*keyValueIterator) Error() error {
+	return nil
+}
+
+func (i *keyValueIterator) Key() []byte {
+	if !i.initialized || len(i.keyValues) == 0 {
+		return nil
+	}
+	return i.keyValues[0].Key
+}
+
+func (i *keyValueIterator) Value() []byte {
+	if !i.initialized || len(i.keyValues) == 0 {
+		return nil
+	}
+	return i.keyValues[0].Value
+}
+
+func (i *keyValueIterator) Release() {
+	i.keyValues = nil
 }
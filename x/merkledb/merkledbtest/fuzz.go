@@ -0,0 +1,277 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package merkledbtest provides utilities for testing code that embeds a
+// merkledb.
+package merkledbtest
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+const (
+	// The maximum number of key/value pairs in a generated proof.
+	maxProofLen = 100
+	// The maximum number of past roots that proofs are generated for.
+	maxPastRoots = 300
+	// The chance that a step is a full rehash of the trie. Full rehashes are
+	// expensive, so they're rare.
+	fullRehashChance = .01
+)
+
+const (
+	opUpdate = iota
+	opDelete
+	opGet
+	opWriteBatch
+	opGenerateRangeProof
+	opGenerateChangeProof
+	opCheckHash
+	opMax // boundary value, not an actual op
+)
+
+type randStep struct {
+	op    int
+	key   []byte // for opUpdate, opDelete, opGet
+	value []byte // for opUpdate
+}
+
+// FuzzDB performs [steps] random operations, generated deterministically from
+// [seed], against a merkledb created with [config] and fails [t] if the
+// database doesn't behave like a key/value store or its proofs are invalid.
+// Checked invariants are that:
+//   - Reads return the last written value.
+//   - Range proofs at the current and past roots verify.
+//   - Change proofs from the initial root to the current and past roots
+//     verify.
+//   - The root matches the root of a new database containing the same
+//     key/value pairs.
+//
+// [config.HistoryLength] must be large enough to retain every root produced
+// by [steps] operations.
+func FuzzDB(t require.TestingT, config merkledb.Config, seed int64, steps int) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(seed)) // #nosec G404
+	run(require, r, config, generate(r, steps))
+}
+
+func run(require *require.Assertions, r *rand.Rand, config merkledb.Config, steps []randStep) {
+	ctx := context.Background()
+
+	db, err := merkledb.New(ctx, memdb.New(), config)
+	require.NoError(err)
+
+	startRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	var (
+		values        = make(map[string][]byte) // tracks content of the trie
+		currentBatch  = db.NewBatch()
+		currentValues = make(map[string][]byte)
+		deleteValues  = make(map[string]struct{})
+		pastRoots     []ids.ID
+	)
+	for _, step := range steps {
+		switch step.op {
+		case opUpdate:
+			require.NoError(currentBatch.Put(step.key, step.value))
+			currentValues[string(step.key)] = step.value
+			delete(deleteValues, string(step.key))
+		case opDelete:
+			require.NoError(currentBatch.Delete(step.key))
+			deleteValues[string(step.key)] = struct{}{}
+			delete(currentValues, string(step.key))
+		case opGenerateRangeProof:
+			root, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			if len(pastRoots) > 0 {
+				root = pastRoots[r.Intn(len(pastRoots))]
+			}
+			start, end := bounds(step)
+
+			rangeProof, err := db.GetRangeProofAtRoot(ctx, root, start, end, maxProofLen)
+			require.NoError(err)
+			require.NoError(rangeProof.Verify(ctx, start, end, root))
+			require.LessOrEqual(len(rangeProof.KeyValues), maxProofLen)
+		case opGenerateChangeProof:
+			root, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			if len(pastRoots) > 1 {
+				root = pastRoots[r.Intn(len(pastRoots))]
+			}
+			if root == startRoot {
+				continue
+			}
+			start, end := bounds(step)
+
+			changeProof, err := db.GetChangeProof(ctx, startRoot, root, start, end, maxProofLen)
+			require.NoError(err)
+
+			changeProofDB, err := merkledb.New(ctx, memdb.New(), withNewRegistry(config))
+			require.NoError(err)
+			require.NoError(changeProofDB.VerifyChangeProof(ctx, changeProof, start, end, root))
+			require.LessOrEqual(len(changeProof.KeyChanges), maxProofLen)
+			require.NoError(changeProofDB.Close())
+		case opWriteBatch:
+			oldRoot, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			require.NoError(currentBatch.Write())
+			for key, value := range currentValues {
+				values[key] = value
+			}
+			for key := range deleteValues {
+				delete(values, key)
+			}
+
+			if len(currentValues) == 0 && len(deleteValues) == 0 {
+				continue
+			}
+			newRoot, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			if oldRoot != newRoot {
+				pastRoots = append(pastRoots, newRoot)
+				if len(pastRoots) > maxPastRoots {
+					pastRoots = pastRoots[len(pastRoots)-maxPastRoots:]
+				}
+			}
+			currentValues = map[string][]byte{}
+			deleteValues = map[string]struct{}{}
+			currentBatch = db.NewBatch()
+		case opGet:
+			want := values[string(step.key)]
+
+			v, err := db.Get(step.key)
+			if err != nil {
+				require.ErrorIs(err, database.ErrNotFound)
+			}
+			require.True(bytes.Equal(want, v)) // Use bytes.Equal so nil treated equal to []byte{}
+
+			// Reads through a view must agree with reads from the database.
+			view, err := db.NewView(ctx, nil)
+			require.NoError(err)
+			v, err = view.GetValue(ctx, step.key)
+			if err != nil {
+				require.ErrorIs(err, database.ErrNotFound)
+			}
+			require.True(bytes.Equal(want, v)) // Use bytes.Equal so nil treated equal to []byte{}
+		case opCheckHash:
+			rehashedDB, err := merkledb.New(ctx, memdb.New(), withNewRegistry(config))
+			require.NoError(err)
+			ops := make([]database.BatchOp, 0, len(values))
+			for key, value := range values {
+				ops = append(ops, database.BatchOp{Key: []byte(key), Value: value})
+			}
+			view, err := rehashedDB.NewView(ctx, ops)
+			require.NoError(err)
+
+			calculatedRoot, err := view.GetMerkleRoot(ctx)
+			require.NoError(err)
+			dbRoot, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			require.Equal(dbRoot, calculatedRoot)
+			require.NoError(rehashedDB.Close())
+		}
+	}
+	require.NoError(db.Close())
+}
+
+// bounds returns the proof range of [step]. An empty key means the range is
+// unbounded on that side.
+func bounds(step randStep) (maybe.Maybe[[]byte], maybe.Maybe[[]byte]) {
+	start := maybe.Nothing[[]byte]()
+	if len(step.key) > 0 {
+		start = maybe.Some(step.key)
+	}
+	end := maybe.Nothing[[]byte]()
+	if len(step.value) > 0 {
+		end = maybe.Some(step.value)
+	}
+	return start, end
+}
+
+// withNewRegistry returns [config] with a new metrics registry so that
+// another database can be created with it.
+func withNewRegistry(config merkledb.Config) merkledb.Config {
+	if config.Reg != nil {
+		config.Reg = prometheus.NewRegistry()
+	}
+	return config
+}
+
+// generate returns [size] random steps, the last of which is a full rehash of
+// the trie.
+func generate(r *rand.Rand, size int) []randStep {
+	var allKeys [][]byte
+	genKey := func() []byte {
+		if len(allKeys) < 2 || r.Intn(100) < 10 {
+			// new key
+			key := make([]byte, r.Intn(50))
+			_, _ = r.Read(key)
+			allKeys = append(allKeys, key)
+			return key
+		}
+		if len(allKeys) > 2 && r.Intn(100) < 10 {
+			// new prefixed key
+			prefix := allKeys[r.Intn(len(allKeys))]
+			key := make([]byte, r.Intn(50)+len(prefix))
+			copy(key, prefix)
+			_, _ = r.Read(key[len(prefix):])
+			allKeys = append(allKeys, key)
+			return key
+		}
+		// use existing key
+		return allKeys[r.Intn(len(allKeys))]
+	}
+
+	genEnd := func(key []byte) []byte {
+		if r.Intn(10) == 0 {
+			return nil
+		}
+
+		endKey := make([]byte, len(key))
+		copy(endKey, key)
+		for i := 0; i < len(endKey); i += 2 {
+			n := r.Intn(len(endKey))
+			if endKey[n] < 250 {
+				endKey[n] += byte(r.Intn(int(255 - endKey[n])))
+			}
+		}
+		return endKey
+	}
+
+	steps := make([]randStep, 0, size)
+	for len(steps) < size-1 {
+		s := randStep{op: r.Intn(opMax)}
+		switch s.op {
+		case opUpdate:
+			s.key = genKey()
+			s.value = make([]byte, r.Intn(50))
+			_, _ = r.Read(s.value)
+		case opGet, opDelete:
+			s.key = genKey()
+		case opGenerateRangeProof, opGenerateChangeProof:
+			s.key = genKey()
+			s.value = genEnd(s.key)
+		case opCheckHash:
+			if r.Float64() >= fullRehashChance {
+				continue
+			}
+		}
+		steps = append(steps, s)
+	}
+	// always end with a full hash of the trie
+	return append(steps, randStep{op: opCheckHash})
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledbtest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/trace"
+	"github.com/ava-labs/avalanchego/x/merkledb"
+)
+
+func newConfig(historyLength int) merkledb.Config {
+	tracer, _ := trace.New(trace.Config{Enabled: false})
+	return merkledb.Config{
+		EvictionBatchSize: 100,
+		HistoryLength:     historyLength,
+		NodeCacheSize:     1_000,
+		Reg:               prometheus.NewRegistry(),
+		Tracer:            tracer,
+	}
+}
+
+// failureRecorder records the failures of a test instead of failing the test
+// it's used in.
+type failureRecorder struct {
+	failed   bool
+	messages []string
+}
+
+func (f *failureRecorder) Errorf(format string, args ...interface{}) {
+	f.failed = true
+	f.messages = append(f.messages, fmt.Sprintf(format, args...))
+}
+
+func (f *failureRecorder) FailNow() {
+	f.failed = true
+	runtime.Goexit()
+}
+
+func TestFuzzDB(t *testing.T) {
+	for _, seed := range []int64{0, 1} {
+		t.Run(fmt.Sprint(seed), func(t *testing.T) {
+			FuzzDB(t, newConfig(1_000), seed, 500)
+		})
+	}
+}
+
+func TestFuzzDBBrokenConfig(t *testing.T) {
+	// Proofs can't be generated for past roots that aren't in the history.
+	config := newConfig(1)
+
+	recorder := &failureRecorder{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		FuzzDB(recorder, config, 0, 500)
+	}()
+	<-done
+	require.True(t, recorder.failed)
+	require.Len(t, recorder.messages, 1)
+	require.Contains(t, recorder.messages[0], merkledb.ErrInsufficientHistory.Error())
+}
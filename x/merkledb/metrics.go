@@ -22,10 +22,22 @@ type merkleMetrics interface {
 	HashCalculated()
 	DBNodeCacheHit()
 	DBNodeCacheMiss()
+	// Records the depth, in nibbles, of a node that was found in the db
+	// node cache.
+	DBNodeCacheHitDepth(depth int)
 	ViewNodeCacheHit()
 	ViewNodeCacheMiss()
 	ViewValueCacheHit()
 	ViewValueCacheMiss()
+	SetEvictionBatchSize(int)
+	// Records the number of nodes that a commit changed, each of which is
+	// written to disk.
+	NodesWrittenPerCommit(nodesWritten int)
+	// Records that a periodic flush failed.
+	PeriodicFlushFailed()
+	// Records that [Config.OnEvict] wasn't called for [dropped] evicted nodes
+	// because it fell behind.
+	EvictNotificationsDropped(dropped int)
 }
 
 type mockMetrics struct {
@@ -39,6 +51,11 @@ type mockMetrics struct {
 	viewNodeCacheMiss  int64
 	viewValueCacheHit  int64
 	viewValueCacheMiss int64
+	evictionBatchSize  int64
+	cachedNodeDepths   []int
+	nodesWritten       []int
+	flushFailures      int64
+	evictsDropped      int64
 }
 
 func (m *mockMetrics) HashCalculated() {
@@ -104,6 +121,41 @@ func (m *mockMetrics) DBNodeCacheMiss() {
 	m.dbNodeCacheMiss++
 }
 
+func (m *mockMetrics) DBNodeCacheHitDepth(depth int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.cachedNodeDepths = append(m.cachedNodeDepths, depth)
+}
+
+func (m *mockMetrics) SetEvictionBatchSize(size int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.evictionBatchSize = int64(size)
+}
+
+func (m *mockMetrics) NodesWrittenPerCommit(nodesWritten int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.nodesWritten = append(m.nodesWritten, nodesWritten)
+}
+
+func (m *mockMetrics) PeriodicFlushFailed() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.flushFailures++
+}
+
+func (m *mockMetrics) EvictNotificationsDropped(dropped int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.evictsDropped += int64(dropped)
+}
+
 type metrics struct {
 	ioKeyWrite         prometheus.Counter
 	ioKeyRead          prometheus.Counter
@@ -114,6 +166,11 @@ type metrics struct {
 	viewNodeCacheMiss  prometheus.Counter
 	viewValueCacheHit  prometheus.Counter
 	viewValueCacheMiss prometheus.Counter
+	evictionBatchSize  prometheus.Gauge
+	cachedNodeDepth    prometheus.Histogram
+	nodesWritten       prometheus.Histogram
+	flushFailures      prometheus.Counter
+	evictsDropped      prometheus.Counter
 }
 
 func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, error) {
@@ -167,6 +224,33 @@ func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, err
 			Name:      "view_value_cache_miss",
 			Help:      "cumulative amount of misses on the view value cache",
 		}),
+		evictionBatchSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "eviction_batch_size",
+			Help:      "number of nodes written to disk per cache eviction when adaptive eviction is enabled",
+		}),
+		cachedNodeDepth: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "cached_node_depth",
+			Help:      "depth, in nibbles, of the nodes found in the db node cache",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 9), // 1 to 256 nibbles
+		}),
+		nodesWritten: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "nodes_written_per_commit",
+			Help:      "number of nodes changed, and therefore written to disk, by each commit",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10), // 1 to 262,144 nodes
+		}),
+		flushFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "periodic_flush_failures",
+			Help:      "cumulative number of periodic flushes that failed",
+		}),
+		evictsDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "evict_notifications_dropped",
+			Help:      "cumulative number of evicted nodes that the eviction callback wasn't notified of because it fell behind",
+		}),
 	}
 	errs := wrappers.Errs{}
 	errs.Add(
@@ -179,6 +263,11 @@ func newMetrics(namespace string, reg prometheus.Registerer) (merkleMetrics, err
 		reg.Register(m.viewNodeCacheMiss),
 		reg.Register(m.viewValueCacheHit),
 		reg.Register(m.viewValueCacheMiss),
+		reg.Register(m.evictionBatchSize),
+		reg.Register(m.cachedNodeDepth),
+		reg.Register(m.nodesWritten),
+		reg.Register(m.flushFailures),
+		reg.Register(m.evictsDropped),
 	)
 	return &m, errs.Err
 }
@@ -218,3 +307,23 @@ func (m *metrics) DBNodeCacheHit() {
 func (m *metrics) DBNodeCacheMiss() {
 	m.dbNodeCacheMiss.Inc()
 }
+
+func (m *metrics) DBNodeCacheHitDepth(depth int) {
+	m.cachedNodeDepth.Observe(float64(depth))
+}
+
+func (m *metrics) SetEvictionBatchSize(size int) {
+	m.evictionBatchSize.Set(float64(size))
+}
+
+func (m *metrics) NodesWrittenPerCommit(nodesWritten int) {
+	m.nodesWritten.Observe(float64(nodesWritten))
+}
+
+func (m *metrics) PeriodicFlushFailed() {
+	m.flushFailures.Inc()
+}
+
+func (m *metrics) EvictNotificationsDropped(dropped int) {
+	m.evictsDropped.Add(float64(dropped))
+}
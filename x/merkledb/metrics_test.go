@@ -7,10 +7,15 @@ import (
 	"context"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+
 	"github.com/stretchr/testify/require"
 
+	dto "github.com/prometheus/client_model/go"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
 )
 
 func Test_Metrics_Basic_Usage(t *testing.T) {
@@ -46,6 +51,54 @@ func Test_Metrics_Basic_Usage(t *testing.T) {
 	require.Equal(t, int64(4), db.metrics.(*mockMetrics).hashCount)
 }
 
+func Test_Metrics_GetMerkleRoot(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.Reg = nil
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+
+	metrics := db.metrics.(*mockMetrics)
+	requireNoWalk := func(getRoot func() (ids.ID, error)) ids.ID {
+		hashCount := metrics.hashCount
+		keyReadCount := metrics.keyReadCount
+		dbNodeCacheHit := metrics.dbNodeCacheHit
+		dbNodeCacheMiss := metrics.dbNodeCacheMiss
+
+		root, err := getRoot()
+		require.NoError(err)
+
+		require.Equal(hashCount, metrics.hashCount)
+		require.Equal(keyReadCount, metrics.keyReadCount)
+		require.Equal(dbNodeCacheHit, metrics.dbNodeCacheHit)
+		require.Equal(dbNodeCacheMiss, metrics.dbNodeCacheMiss)
+		return root
+	}
+
+	// The root of a view is calculated once.
+	view, err := db.NewView(context.Background(), []database.BatchOp{
+		{Key: []byte("key2"), Value: []byte("value2")},
+	})
+	require.NoError(err)
+	hashCount := metrics.hashCount
+	viewRoot, err := view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Greater(metrics.hashCount, hashCount)
+	require.Equal(viewRoot, requireNoWalk(func() (ids.ID, error) {
+		return view.GetMerkleRoot(context.Background())
+	}))
+
+	// The root of the db is calculated when changes are committed.
+	require.NoError(view.CommitToDB(context.Background()))
+	for i := 0; i < 2; i++ {
+		require.Equal(viewRoot, requireNoWalk(func() (ids.ID, error) {
+			return db.GetMerkleRoot(context.Background())
+		}))
+	}
+}
+
 func Test_Metrics_Initialize(t *testing.T) {
 	db, err := New(
 		context.Background(),
@@ -62,3 +115,108 @@ func Test_Metrics_Initialize(t *testing.T) {
 
 	require.NoError(t, db.Delete([]byte("key")))
 }
+
+func Test_Metrics_CachedNodeDepth(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	reg := prometheus.NewRegistry()
+	config.Reg = reg
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	// The nodes are at depths 2 and 4.
+	shortKey := []byte{0x10}
+	longKey := []byte{0x10, 0x00}
+	require.NoError(db.Put(shortKey, []byte("value0")))
+	require.NoError(db.Put(longKey, []byte("value1")))
+
+	histogram := func() *dto.Histogram {
+		metrics, err := reg.Gather()
+		require.NoError(err)
+		for _, metric := range metrics {
+			if metric.GetName() == "merkleDB_cached_node_depth" {
+				require.Len(metric.Metric, 1)
+				return metric.Metric[0].GetHistogram()
+			}
+		}
+		require.FailNow("histogram not registered")
+		return nil
+	}
+	initial := histogram()
+
+	// Each read hits the cache for the node with the key.
+	for i := 0; i < 3; i++ {
+		_, err := db.Get(shortKey)
+		require.NoError(err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err := db.Get(longKey)
+		require.NoError(err)
+	}
+
+	final := histogram()
+	require.Equal(uint64(5), final.GetSampleCount()-initial.GetSampleCount())
+	require.Equal(float64(3*2+2*4), final.GetSampleSum()-initial.GetSampleSum())
+
+	// Buckets are cumulative, so 3 observations are of depth 2 and 2 are of
+	// depth 4.
+	for _, bucket := range final.Bucket {
+		var initialCount uint64
+		for _, initialBucket := range initial.Bucket {
+			if initialBucket.GetUpperBound() == bucket.GetUpperBound() {
+				initialCount = initialBucket.GetCumulativeCount()
+			}
+		}
+		switch bucket.GetUpperBound() {
+		case 1:
+			require.Zero(bucket.GetCumulativeCount() - initialCount)
+		case 2:
+			require.Equal(uint64(3), bucket.GetCumulativeCount()-initialCount)
+		case 4:
+			require.Equal(uint64(5), bucket.GetCumulativeCount()-initialCount)
+		}
+	}
+}
+
+func Test_Metrics_NodesWrittenPerCommit(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	reg := prometheus.NewRegistry()
+	config.Reg = reg
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	changedKeys, nodesWritten := db.LastCommitStats()
+	require.Zero(changedKeys)
+	require.Zero(nodesWritten)
+
+	require.NoError(db.Put([]byte{0x00}, []byte("value")))
+
+	// The keys share a prefix, so the commit writes the root, a new branch
+	// node at the shared prefix and a node for each key.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte{0x10, 0x00}, []byte("value0")))
+	require.NoError(batch.Put([]byte{0x10, 0x01}, []byte("value1")))
+	require.NoError(batch.Put([]byte{0x10, 0x02}, []byte("value2")))
+	require.NoError(batch.Write())
+
+	changedKeys, nodesWritten = db.LastCommitStats()
+	require.Equal(3, changedKeys)
+	require.Equal(5, nodesWritten)
+
+	metrics, err := reg.Gather()
+	require.NoError(err)
+	var histogram *dto.Histogram
+	for _, metric := range metrics {
+		if metric.GetName() == "merkleDB_nodes_written_per_commit" {
+			require.Len(metric.Metric, 1)
+			histogram = metric.Metric[0].GetHistogram()
+		}
+	}
+	require.NotNil(histogram)
+	// The first commit wrote the root and the node for its key.
+	require.Equal(uint64(2), histogram.GetSampleCount())
+	require.Equal(float64(2+5), histogram.GetSampleSum())
+}
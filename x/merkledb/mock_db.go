@@ -9,7 +9,9 @@ package merkledb
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	database "github.com/ava-labs/avalanchego/database"
 	ids "github.com/ava-labs/avalanchego/ids"
@@ -40,6 +42,77 @@ func (m *MockMerkleDB) EXPECT() *MockMerkleDBMockRecorder {
 	return m.recorder
 }
 
+// BeginBatchedCommits mocks base method.
+func (m *MockMerkleDB) BeginBatchedCommits() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginBatchedCommits")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginBatchedCommits indicates an expected call of BeginBatchedCommits.
+func (mr *MockMerkleDBMockRecorder) BeginBatchedCommits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginBatchedCommits", reflect.TypeOf((*MockMerkleDB)(nil).BeginBatchedCommits))
+}
+
+// BulkLoadSorted mocks base method.
+func (m *MockMerkleDB) BulkLoadSorted(arg0 context.Context, arg1 database.Iterator) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkLoadSorted", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkLoadSorted indicates an expected call of BulkLoadSorted.
+func (mr *MockMerkleDBMockRecorder) BulkLoadSorted(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkLoadSorted", reflect.TypeOf((*MockMerkleDB)(nil).BulkLoadSorted), arg0, arg1)
+}
+
+// CachedRoot mocks base method.
+func (m *MockMerkleDB) CachedRoot() ids.ID {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CachedRoot")
+	ret0, _ := ret[0].(ids.ID)
+	return ret0
+}
+
+// CachedRoot indicates an expected call of CachedRoot.
+func (mr *MockMerkleDBMockRecorder) CachedRoot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CachedRoot", reflect.TypeOf((*MockMerkleDB)(nil).CachedRoot))
+}
+
+// Capabilities mocks base method.
+func (m *MockMerkleDB) Capabilities() Capabilities {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capabilities")
+	ret0, _ := ret[0].(Capabilities)
+	return ret0
+}
+
+// Capabilities indicates an expected call of Capabilities.
+func (mr *MockMerkleDBMockRecorder) Capabilities() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capabilities", reflect.TypeOf((*MockMerkleDB)(nil).Capabilities))
+}
+
+// ChangedKeysBetween mocks base method.
+func (m *MockMerkleDB) ChangedKeysBetween(arg0, arg1 ids.ID) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangedKeysBetween", arg0, arg1)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangedKeysBetween indicates an expected call of ChangedKeysBetween.
+func (mr *MockMerkleDBMockRecorder) ChangedKeysBetween(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangedKeysBetween", reflect.TypeOf((*MockMerkleDB)(nil).ChangedKeysBetween), arg0, arg1)
+}
+
 // Close mocks base method.
 func (m *MockMerkleDB) Close() error {
 	m.ctrl.T.Helper()
@@ -82,6 +155,34 @@ func (mr *MockMerkleDBMockRecorder) CommitRangeProof(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitRangeProof", reflect.TypeOf((*MockMerkleDB)(nil).CommitRangeProof), arg0, arg1, arg2)
 }
 
+// CommitRangeProofVerified mocks base method.
+func (m *MockMerkleDB) CommitRangeProofVerified(arg0 context.Context, arg1 maybe.Maybe[[]uint8], arg2 *RangeProof, arg3 ids.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitRangeProofVerified", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitRangeProofVerified indicates an expected call of CommitRangeProofVerified.
+func (mr *MockMerkleDBMockRecorder) CommitRangeProofVerified(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitRangeProofVerified", reflect.TypeOf((*MockMerkleDB)(nil).CommitRangeProofVerified), arg0, arg1, arg2, arg3)
+}
+
+// CommitSeq mocks base method.
+func (m *MockMerkleDB) CommitSeq() uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitSeq")
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// CommitSeq indicates an expected call of CommitSeq.
+func (mr *MockMerkleDBMockRecorder) CommitSeq() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitSeq", reflect.TypeOf((*MockMerkleDB)(nil).CommitSeq))
+}
+
 // Compact mocks base method.
 func (m *MockMerkleDB) Compact(arg0, arg1 []byte) error {
 	m.ctrl.T.Helper()
@@ -96,6 +197,21 @@ func (mr *MockMerkleDBMockRecorder) Compact(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compact", reflect.TypeOf((*MockMerkleDB)(nil).Compact), arg0, arg1)
 }
 
+// CountPrefix mocks base method.
+func (m *MockMerkleDB) CountPrefix(arg0 context.Context, arg1 []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPrefix", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPrefix indicates an expected call of CountPrefix.
+func (mr *MockMerkleDBMockRecorder) CountPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPrefix", reflect.TypeOf((*MockMerkleDB)(nil).CountPrefix), arg0, arg1)
+}
+
 // Delete mocks base method.
 func (m *MockMerkleDB) Delete(arg0 []byte) error {
 	m.ctrl.T.Helper()
@@ -110,6 +226,107 @@ func (mr *MockMerkleDBMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMerkleDB)(nil).Delete), arg0)
 }
 
+// Dump mocks base method.
+func (m *MockMerkleDB) Dump(arg0 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dump", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Dump indicates an expected call of Dump.
+func (mr *MockMerkleDBMockRecorder) Dump(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dump", reflect.TypeOf((*MockMerkleDB)(nil).Dump), arg0)
+}
+
+// DumpN mocks base method.
+func (m *MockMerkleDB) DumpN(arg0 io.Writer, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpN", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DumpN indicates an expected call of DumpN.
+func (mr *MockMerkleDBMockRecorder) DumpN(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpN", reflect.TypeOf((*MockMerkleDB)(nil).DumpN), arg0, arg1)
+}
+
+// EndBatchedCommits mocks base method.
+func (m *MockMerkleDB) EndBatchedCommits() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndBatchedCommits")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndBatchedCommits indicates an expected call of EndBatchedCommits.
+func (mr *MockMerkleDBMockRecorder) EndBatchedCommits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndBatchedCommits", reflect.TypeOf((*MockMerkleDB)(nil).EndBatchedCommits))
+}
+
+// EstimateRangeProofCost mocks base method.
+func (m *MockMerkleDB) EstimateRangeProofCost(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8], arg3 int) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateRangeProofCost", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EstimateRangeProofCost indicates an expected call of EstimateRangeProofCost.
+func (mr *MockMerkleDBMockRecorder) EstimateRangeProofCost(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateRangeProofCost", reflect.TypeOf((*MockMerkleDB)(nil).EstimateRangeProofCost), arg0, arg1, arg2, arg3)
+}
+
+// EstimateRangeSize mocks base method.
+func (m *MockMerkleDB) EstimateRangeSize(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8]) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateRangeSize", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateRangeSize indicates an expected call of EstimateRangeSize.
+func (mr *MockMerkleDBMockRecorder) EstimateRangeSize(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateRangeSize", reflect.TypeOf((*MockMerkleDB)(nil).EstimateRangeSize), arg0, arg1, arg2)
+}
+
+// ExtractRange mocks base method.
+func (m *MockMerkleDB) ExtractRange(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8], arg3 database.Database) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtractRange", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtractRange indicates an expected call of ExtractRange.
+func (mr *MockMerkleDBMockRecorder) ExtractRange(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractRange", reflect.TypeOf((*MockMerkleDB)(nil).ExtractRange), arg0, arg1, arg2, arg3)
+}
+
+// Flush mocks base method.
+func (m *MockMerkleDB) Flush() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockMerkleDBMockRecorder) Flush() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockMerkleDB)(nil).Flush))
+}
+
 // Get mocks base method.
 func (m *MockMerkleDB) Get(arg0 []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -125,6 +342,36 @@ func (mr *MockMerkleDBMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMerkleDB)(nil).Get), arg0)
 }
 
+// GetAbsenceProof mocks base method.
+func (m *MockMerkleDB) GetAbsenceProof(arg0 context.Context, arg1, arg2 []byte) (*RangeProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAbsenceProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*RangeProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAbsenceProof indicates an expected call of GetAbsenceProof.
+func (mr *MockMerkleDBMockRecorder) GetAbsenceProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAbsenceProof", reflect.TypeOf((*MockMerkleDB)(nil).GetAbsenceProof), arg0, arg1, arg2)
+}
+
+// GetAtSeq mocks base method.
+func (m *MockMerkleDB) GetAtSeq(arg0 uint64, arg1 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAtSeq", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAtSeq indicates an expected call of GetAtSeq.
+func (mr *MockMerkleDBMockRecorder) GetAtSeq(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAtSeq", reflect.TypeOf((*MockMerkleDB)(nil).GetAtSeq), arg0, arg1)
+}
+
 // GetChangeProof mocks base method.
 func (m *MockMerkleDB) GetChangeProof(arg0 context.Context, arg1, arg2 ids.ID, arg3, arg4 maybe.Maybe[[]uint8], arg5 int) (*ChangeProof, error) {
 	m.ctrl.T.Helper()
@@ -140,6 +387,37 @@ func (mr *MockMerkleDBMockRecorder) GetChangeProof(arg0, arg1, arg2, arg3, arg4,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangeProof", reflect.TypeOf((*MockMerkleDB)(nil).GetChangeProof), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// GetDiffProof mocks base method.
+func (m *MockMerkleDB) GetDiffProof(arg0 context.Context, arg1, arg2 ids.ID, arg3 maybe.Maybe[[]uint8], arg4 int) (*ChangeProof, maybe.Maybe[[]uint8], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDiffProof", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*ChangeProof)
+	ret1, _ := ret[1].(maybe.Maybe[[]uint8])
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetDiffProof indicates an expected call of GetDiffProof.
+func (mr *MockMerkleDBMockRecorder) GetDiffProof(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDiffProof", reflect.TypeOf((*MockMerkleDB)(nil).GetDiffProof), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetMap mocks base method.
+func (m *MockMerkleDB) GetMap(arg0 context.Context, arg1 [][]byte) (map[string][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMap", arg0, arg1)
+	ret0, _ := ret[0].(map[string][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMap indicates an expected call of GetMap.
+func (mr *MockMerkleDBMockRecorder) GetMap(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMap", reflect.TypeOf((*MockMerkleDB)(nil).GetMap), arg0, arg1)
+}
+
 // GetMerkleRoot mocks base method.
 func (m *MockMerkleDB) GetMerkleRoot(arg0 context.Context) (ids.ID, error) {
 	m.ctrl.T.Helper()
@@ -155,6 +433,35 @@ func (mr *MockMerkleDBMockRecorder) GetMerkleRoot(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerkleRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetMerkleRoot), arg0)
 }
 
+// GetMultiRangeProof mocks base method.
+func (m *MockMerkleDB) GetMultiRangeProof(arg0 context.Context, arg1 []Bounds, arg2 int) (*MultiRangeProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMultiRangeProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*MultiRangeProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMultiRangeProof indicates an expected call of GetMultiRangeProof.
+func (mr *MockMerkleDBMockRecorder) GetMultiRangeProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMultiRangeProof", reflect.TypeOf((*MockMerkleDB)(nil).GetMultiRangeProof), arg0, arg1, arg2)
+}
+
+// GetOrDefault mocks base method.
+func (m *MockMerkleDB) GetOrDefault(arg0, arg1 []byte) []byte {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrDefault", arg0, arg1)
+	ret0, _ := ret[0].([]byte)
+	return ret0
+}
+
+// GetOrDefault indicates an expected call of GetOrDefault.
+func (mr *MockMerkleDBMockRecorder) GetOrDefault(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrDefault", reflect.TypeOf((*MockMerkleDB)(nil).GetOrDefault), arg0, arg1)
+}
+
 // GetProof mocks base method.
 func (m *MockMerkleDB) GetProof(arg0 context.Context, arg1 []byte) (*Proof, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +507,51 @@ func (mr *MockMerkleDBMockRecorder) GetRangeProofAtRoot(arg0, arg1, arg2, arg3,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeProofAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetRangeProofAtRoot), arg0, arg1, arg2, arg3, arg4)
 }
 
+// GetRangeProofWithBounds mocks base method.
+func (m *MockMerkleDB) GetRangeProofWithBounds(arg0 context.Context, arg1 Bounds, arg2 int) (*RangeProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRangeProofWithBounds", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*RangeProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRangeProofWithBounds indicates an expected call of GetRangeProofWithBounds.
+func (mr *MockMerkleDBMockRecorder) GetRangeProofWithBounds(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRangeProofWithBounds", reflect.TypeOf((*MockMerkleDB)(nil).GetRangeProofWithBounds), arg0, arg1, arg2)
+}
+
+// GetRawNode mocks base method.
+func (m *MockMerkleDB) GetRawNode(arg0 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRawNode", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRawNode indicates an expected call of GetRawNode.
+func (mr *MockMerkleDBMockRecorder) GetRawNode(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRawNode", reflect.TypeOf((*MockMerkleDB)(nil).GetRawNode), arg0)
+}
+
+// GetUnsafe mocks base method.
+func (m *MockMerkleDB) GetUnsafe(arg0 []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnsafe", arg0)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnsafe indicates an expected call of GetUnsafe.
+func (mr *MockMerkleDBMockRecorder) GetUnsafe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnsafe", reflect.TypeOf((*MockMerkleDB)(nil).GetUnsafe), arg0)
+}
+
 // GetValue mocks base method.
 func (m *MockMerkleDB) GetValue(arg0 context.Context, arg1 []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -230,6 +582,21 @@ func (mr *MockMerkleDBMockRecorder) GetValues(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValues", reflect.TypeOf((*MockMerkleDB)(nil).GetValues), arg0, arg1)
 }
 
+// GetValuesConsistent mocks base method.
+func (m *MockMerkleDB) GetValuesConsistent(arg0 context.Context, arg1 [][]byte) ([][]byte, []error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValuesConsistent", arg0, arg1)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].([]error)
+	return ret0, ret1
+}
+
+// GetValuesConsistent indicates an expected call of GetValuesConsistent.
+func (mr *MockMerkleDBMockRecorder) GetValuesConsistent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValuesConsistent", reflect.TypeOf((*MockMerkleDB)(nil).GetValuesConsistent), arg0, arg1)
+}
+
 // Has mocks base method.
 func (m *MockMerkleDB) Has(arg0 []byte) (bool, error) {
 	m.ctrl.T.Helper()
@@ -260,6 +627,51 @@ func (mr *MockMerkleDBMockRecorder) HealthCheck(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockMerkleDB)(nil).HealthCheck), arg0)
 }
 
+// KeysChangedSince mocks base method.
+func (m *MockMerkleDB) KeysChangedSince(arg0 time.Time) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KeysChangedSince", arg0)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KeysChangedSince indicates an expected call of KeysChangedSince.
+func (mr *MockMerkleDBMockRecorder) KeysChangedSince(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeysChangedSince", reflect.TypeOf((*MockMerkleDB)(nil).KeysChangedSince), arg0)
+}
+
+// LastCommitStats mocks base method.
+func (m *MockMerkleDB) LastCommitStats() (int, int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LastCommitStats")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	return ret0, ret1
+}
+
+// LastCommitStats indicates an expected call of LastCommitStats.
+func (mr *MockMerkleDBMockRecorder) LastCommitStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LastCommitStats", reflect.TypeOf((*MockMerkleDB)(nil).LastCommitStats))
+}
+
+// Len mocks base method.
+func (m *MockMerkleDB) Len() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Len")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Len indicates an expected call of Len.
+func (mr *MockMerkleDBMockRecorder) Len() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Len", reflect.TypeOf((*MockMerkleDB)(nil).Len))
+}
+
 // NewBatch mocks base method.
 func (m *MockMerkleDB) NewBatch() database.Batch {
 	m.ctrl.T.Helper()
@@ -288,6 +700,21 @@ func (mr *MockMerkleDBMockRecorder) NewIterator() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIterator", reflect.TypeOf((*MockMerkleDB)(nil).NewIterator))
 }
 
+// NewIteratorAtRoot mocks base method.
+func (m *MockMerkleDB) NewIteratorAtRoot(arg0 ids.ID, arg1, arg2 []byte) (database.Iterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewIteratorAtRoot", arg0, arg1, arg2)
+	ret0, _ := ret[0].(database.Iterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewIteratorAtRoot indicates an expected call of NewIteratorAtRoot.
+func (mr *MockMerkleDBMockRecorder) NewIteratorAtRoot(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIteratorAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).NewIteratorAtRoot), arg0, arg1, arg2)
+}
+
 // NewIteratorWithPrefix mocks base method.
 func (m *MockMerkleDB) NewIteratorWithPrefix(arg0 []byte) database.Iterator {
 	m.ctrl.T.Helper()
@@ -330,6 +757,20 @@ func (mr *MockMerkleDBMockRecorder) NewIteratorWithStartAndPrefix(arg0, arg1 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewIteratorWithStartAndPrefix", reflect.TypeOf((*MockMerkleDB)(nil).NewIteratorWithStartAndPrefix), arg0, arg1)
 }
 
+// NewKeyIterator mocks base method.
+func (m *MockMerkleDB) NewKeyIterator(arg0, arg1 []byte) database.Iterator {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewKeyIterator", arg0, arg1)
+	ret0, _ := ret[0].(database.Iterator)
+	return ret0
+}
+
+// NewKeyIterator indicates an expected call of NewKeyIterator.
+func (mr *MockMerkleDBMockRecorder) NewKeyIterator(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewKeyIterator", reflect.TypeOf((*MockMerkleDB)(nil).NewKeyIterator), arg0, arg1)
+}
+
 // NewView mocks base method.
 func (m *MockMerkleDB) NewView(arg0 context.Context, arg1 []database.BatchOp) (TrieView, error) {
 	m.ctrl.T.Helper()
@@ -345,6 +786,34 @@ func (mr *MockMerkleDBMockRecorder) NewView(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewView", reflect.TypeOf((*MockMerkleDB)(nil).NewView), arg0, arg1)
 }
 
+// Pin mocks base method.
+func (m *MockMerkleDB) Pin(arg0 [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pin", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pin indicates an expected call of Pin.
+func (mr *MockMerkleDBMockRecorder) Pin(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pin", reflect.TypeOf((*MockMerkleDB)(nil).Pin), arg0)
+}
+
+// PrefetchRange mocks base method.
+func (m *MockMerkleDB) PrefetchRange(arg0 context.Context, arg1, arg2 maybe.Maybe[[]uint8]) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrefetchRange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PrefetchRange indicates an expected call of PrefetchRange.
+func (mr *MockMerkleDBMockRecorder) PrefetchRange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrefetchRange", reflect.TypeOf((*MockMerkleDB)(nil).PrefetchRange), arg0, arg1, arg2)
+}
+
 // Put mocks base method.
 func (m *MockMerkleDB) Put(arg0, arg1 []byte) error {
 	m.ctrl.T.Helper()
@@ -359,6 +828,89 @@ func (mr *MockMerkleDBMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockMerkleDB)(nil).Put), arg0, arg1)
 }
 
+// SampleProof mocks base method.
+func (m *MockMerkleDB) SampleProof(arg0 context.Context, arg1 uint64, arg2 int) (*RangeProof, [][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SampleProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*RangeProof)
+	ret1, _ := ret[1].([][]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SampleProof indicates an expected call of SampleProof.
+func (mr *MockMerkleDBMockRecorder) SampleProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SampleProof", reflect.TypeOf((*MockMerkleDB)(nil).SampleProof), arg0, arg1, arg2)
+}
+
+// Snapshot mocks base method.
+func (m *MockMerkleDB) Snapshot() (*Snapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot")
+	ret0, _ := ret[0].(*Snapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockMerkleDBMockRecorder) Snapshot() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockMerkleDB)(nil).Snapshot))
+}
+
+// StartScrubber mocks base method.
+func (m *MockMerkleDB) StartScrubber(arg0 context.Context, arg1 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartScrubber", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartScrubber indicates an expected call of StartScrubber.
+func (mr *MockMerkleDBMockRecorder) StartScrubber(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartScrubber", reflect.TypeOf((*MockMerkleDB)(nil).StartScrubber), arg0, arg1)
+}
+
+// StopScrubber mocks base method.
+func (m *MockMerkleDB) StopScrubber() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StopScrubber")
+}
+
+// StopScrubber indicates an expected call of StopScrubber.
+func (mr *MockMerkleDBMockRecorder) StopScrubber() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopScrubber", reflect.TypeOf((*MockMerkleDB)(nil).StopScrubber))
+}
+
+// Unpin mocks base method.
+func (m *MockMerkleDB) Unpin(arg0 [][]byte) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Unpin", arg0)
+}
+
+// Unpin indicates an expected call of Unpin.
+func (mr *MockMerkleDBMockRecorder) Unpin(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unpin", reflect.TypeOf((*MockMerkleDB)(nil).Unpin), arg0)
+}
+
+// Update mocks base method.
+func (m *MockMerkleDB) Update(arg0 context.Context, arg1 func(Txn) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockMerkleDBMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockMerkleDB)(nil).Update), arg0, arg1)
+}
+
 // VerifyChangeProof mocks base method.
 func (m *MockMerkleDB) VerifyChangeProof(arg0 context.Context, arg1 *ChangeProof, arg2, arg3 maybe.Maybe[[]uint8], arg4 ids.ID) error {
 	m.ctrl.T.Helper()
@@ -373,6 +925,20 @@ func (mr *MockMerkleDBMockRecorder) VerifyChangeProof(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyChangeProof", reflect.TypeOf((*MockMerkleDB)(nil).VerifyChangeProof), arg0, arg1, arg2, arg3, arg4)
 }
 
+// VerifyIntegrity mocks base method.
+func (m *MockMerkleDB) VerifyIntegrity(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyIntegrity", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyIntegrity indicates an expected call of VerifyIntegrity.
+func (mr *MockMerkleDBMockRecorder) VerifyIntegrity(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyIntegrity", reflect.TypeOf((*MockMerkleDB)(nil).VerifyIntegrity), arg0)
+}
+
 // getEditableNode mocks base method.
 func (m *MockMerkleDB) getEditableNode(arg0 path) (*node, error) {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"reflect"
 	"strings"
+	"sync"
 	"unsafe"
 )
 
@@ -106,6 +107,18 @@ func (p path) Bytes() []byte {
 	return buf
 }
 
+// hex returns [p] as a string with one hex digit per nibble.
+func (p path) hex() string {
+	const digits = "0123456789abcdef"
+
+	var sb strings.Builder
+	sb.Grow(len(p))
+	for i := 0; i < len(p); i++ {
+		sb.WriteByte(digits[p[i]])
+	}
+	return sb.String()
+}
+
 // Returns true iff [p] begins with [prefix].
 func (p path) HasPrefix(prefix path) bool {
 	return strings.HasPrefix(string(p), string(prefix))
@@ -145,6 +158,28 @@ func (p path) Serialize() SerializedPath {
 	return result
 }
 
+// Buffers for newPathInBuffer.
+var pathBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new([]byte)
+	},
+}
+
+// newPathInBuffer is like newPath, but the path is written into [buffer],
+// which is grown if it's too small. The returned path shares memory with
+// [buffer], so it must not be used once [buffer] is modified or reused.
+func newPathInBuffer(buffer *[]byte, p []byte) path {
+	if cap(*buffer) < 2*len(p) {
+		*buffer = make([]byte, 2*len(p))
+	}
+	b := (*buffer)[:2*len(p)]
+	for i, currentByte := range p {
+		b[2*i] = currentByte >> 4
+		b[2*i+1] = currentByte & 0x0F
+	}
+	return *(*path)(unsafe.Pointer(&b))
+}
+
 func newPath(p []byte) path {
 	// create new buffer with double the length of the input since each byte gets split into two nibbles
 	buffer := make([]byte, 2*len(p))
@@ -100,6 +100,14 @@ func Test_SerializedPath_Equal(t *testing.T) {
 	require.True(first.Equal(prefix))
 }
 
+func Test_Path_Hex(t *testing.T) {
+	require := require.New(t)
+
+	require.Empty(EmptyPath.hex())
+	require.Equal("1a2b", newPath([]byte{0x1a, 0x2b}).hex())
+	require.Equal("1a2", newPath([]byte{0x1a, 0x2b})[:3].hex())
+}
+
 func FuzzPath(f *testing.F) {
 	f.Fuzz(func(t *testing.T, pathBytes []byte) {
 		require := require.New(t)
@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+type commitProgress struct {
+	nodesFlushed int
+	totalNodes   int
+}
+
+// progressReporter calls a progress callback on a separate goroutine so that
+// reports can be made while holding locks the callback must not hold.
+type progressReporter struct {
+	// Holds the latest report that hasn't been delivered yet, if any.
+	updates chan commitProgress
+	done    chan struct{}
+}
+
+func newProgressReporter(progress func(nodesFlushed, totalNodes int)) *progressReporter {
+	r := &progressReporter{
+		updates: make(chan commitProgress, 1),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(r.done)
+
+		for update := range r.updates {
+			progress(update.nodesFlushed, update.totalNodes)
+		}
+	}()
+	return r
+}
+
+// report queues a report without blocking. If the previous report hasn't been
+// delivered yet, it's replaced.
+// Must not be called concurrently or after close.
+func (r *progressReporter) report(nodesFlushed, totalNodes int) {
+	select {
+	case <-r.updates:
+	default:
+	}
+	r.updates <- commitProgress{
+		nodesFlushed: nodesFlushed,
+		totalNodes:   totalNodes,
+	}
+}
+
+// close returns once all queued reports have been delivered.
+func (r *progressReporter) close() {
+	close(r.updates)
+	<-r.done
+}
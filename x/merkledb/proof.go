@@ -6,8 +6,17 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
@@ -15,6 +24,8 @@ import (
 	"github.com/ava-labs/avalanchego/trace"
 	"github.com/ava-labs/avalanchego/utils/hashing"
 	"github.com/ava-labs/avalanchego/utils/maybe"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/utils/wrappers"
 
 	pb "github.com/ava-labs/avalanchego/proto/pb/sync"
 )
@@ -48,6 +59,15 @@ var (
 	ErrNilProof                    = errors.New("proof is nil")
 	ErrNilValue                    = errors.New("value is nil")
 	ErrUnexpectedEndProof          = errors.New("end proof should be empty")
+	ErrKeyInAbsenceRange           = errors.New("key exists strictly between the bounds of the absence proof")
+	ErrExcludedEndInProof          = errors.New("proof contains the excluded end key along with other keys")
+	ErrInvalidProofLength          = errors.New("proof length is invalid")
+	ErrProofVerificationFailed     = errors.New("proof verification failed")
+	ErrNoRanges                    = errors.New("no ranges")
+	ErrRangesNotDisjoint           = errors.New("ranges aren't sorted and disjoint")
+	ErrRangeCountMismatch          = errors.New("number of ranges doesn't match the number of proofs")
+	ErrEmptyLeftProof              = errors.New("left proof has no key-value pairs")
+	ErrInvalidSubtrie              = errors.New("key-value pairs don't match the ID of their subtrie in the proof")
 )
 
 type ProofNode struct {
@@ -244,6 +264,31 @@ type KeyValue struct {
 	Value []byte
 }
 
+// Bounds of a key range whose endpoints may each be inclusive or exclusive.
+type Bounds struct {
+	// If Nothing, there's no lower bound on the range.
+	Start maybe.Maybe[[]byte]
+	// If Nothing, there's no upper bound on the range.
+	End maybe.Maybe[[]byte]
+	// If true, [Start] is in the range.
+	IncludeStart bool
+	// If true, [End] is in the range.
+	IncludeEnd bool
+}
+
+// inclusiveStart returns the smallest key in the range, ignoring [End].
+func (b Bounds) inclusiveStart() maybe.Maybe[[]byte] {
+	if b.Start.IsNothing() || b.IncludeStart {
+		return b.Start
+	}
+	return maybe.Some(absenceProofStart(b.Start.Value()))
+}
+
+// excludesEnd returns true iff [key] is the excluded [End] of the range.
+func (b Bounds) excludesEnd(key []byte) bool {
+	return b.End.HasValue() && !b.IncludeEnd && bytes.Equal(key, b.End.Value())
+}
+
 // A proof that a given set of key-value pairs are in a trie.
 type RangeProof struct {
 	// Invariant: At least one of [StartProof], [EndProof], [KeyValues] is non-empty.
@@ -284,22 +329,73 @@ func (proof *RangeProof) Verify(
 	end maybe.Maybe[[]byte],
 	expectedRootID ids.ID,
 ) error {
+	calculatedRoot, err := proof.ComputeRoot(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	if expectedRootID != calculatedRoot {
+		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, calculatedRoot, expectedRootID)
+	}
+	return nil
+}
+
+// ComputeRoot returns the root ID of the trie that [proof] proves the
+// key-value pairs in [proof.KeyValues] are in. [proof] is valid for a root
+// iff [Verify] returns nil when given that root, so the returned root is the
+// only root [Verify] accepts.
+// Returns an error if the invariants of RangeProof don't hold, [start] > [end],
+// or any key in [proof.KeyValues] isn't in the range [start, end].
+func (proof *RangeProof) ComputeRoot(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+) (ids.ID, error) {
+	view, err := proof.computeView(ctx, start, end)
+	if err != nil {
+		return ids.Empty, err
+	}
+	return view.GetMerkleRoot(ctx)
+}
+
+// computeView returns a view containing the key-value pairs and proof nodes
+// of [proof], whose root is the root that [proof] proves the key-value pairs
+// are in. See [ComputeRoot].
+func (proof *RangeProof) computeView(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+) (*trieView, error) {
+	smallestProvenPath, largestProvenPath, err := proof.provenRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return proof.buildView(ctx, smallestProvenPath, largestProvenPath, nil)
+}
+
+// provenRange returns an error if the invariants of [proof] don't hold for
+// [start] and [end]. Otherwise it returns the range of paths that [proof]
+// allegedly provides and proves all the key-value pairs in.
+func (proof *RangeProof) provenRange(
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+) (maybe.Maybe[path], maybe.Maybe[path], error) {
+	nothing := maybe.Nothing[path]()
 	switch {
 	case start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0:
-		return ErrStartAfterEnd
+		return nothing, nothing, ErrStartAfterEnd
 	case len(proof.KeyValues) == 0 && len(proof.StartProof) == 0 && len(proof.EndProof) == 0:
-		return ErrNoMerkleProof
+		return nothing, nothing, ErrNoMerkleProof
 	case end.IsNothing() && len(proof.KeyValues) == 0 && len(proof.StartProof) > 0 && len(proof.EndProof) != 0:
-		return ErrUnexpectedEndProof
+		return nothing, nothing, ErrUnexpectedEndProof
 	case end.IsNothing() && len(proof.KeyValues) == 0 && len(proof.StartProof) == 0 && len(proof.EndProof) != 1:
-		return ErrShouldJustBeRoot
+		return nothing, nothing, ErrShouldJustBeRoot
 	case len(proof.EndProof) == 0 && (end.HasValue() || len(proof.KeyValues) > 0):
-		return ErrNoEndProof
+		return nothing, nothing, ErrNoEndProof
 	}
 
 	// Make sure the key-value pairs are sorted and in [start, end].
 	if err := verifyKeyValues(proof.KeyValues, start, end); err != nil {
-		return err
+		return nothing, nothing, err
 	}
 
 	// [proof] allegedly provides and proves all key-value
@@ -328,7 +424,7 @@ func (proof *RangeProof) Verify(
 	// Ensure that the start proof is valid and contains values that
 	// match the key/values that were sent.
 	if err := verifyProofPath(proof.StartProof, smallestProvenPath.Value()); err != nil {
-		return err
+		return nothing, nothing, err
 	}
 	if err := verifyAllRangeProofKeyValuesPresent(
 		proof.StartProof,
@@ -336,13 +432,13 @@ func (proof *RangeProof) Verify(
 		largestProvenPath,
 		keyValues,
 	); err != nil {
-		return err
+		return nothing, nothing, err
 	}
 
 	// Ensure that the end proof is valid and contains values that
 	// match the key/values that were sent.
 	if err := verifyProofPath(proof.EndProof, largestProvenPath.Value()); err != nil {
-		return err
+		return nothing, nothing, err
 	}
 	if err := verifyAllRangeProofKeyValuesPresent(
 		proof.EndProof,
@@ -350,22 +446,38 @@ func (proof *RangeProof) Verify(
 		largestProvenPath,
 		keyValues,
 	); err != nil {
-		return err
+		return nothing, nothing, err
 	}
+	return smallestProvenPath, largestProvenPath, nil
+}
 
-	// Insert all key-value pairs into the trie.
-	ops := make([]database.BatchOp, len(proof.KeyValues))
-	for i, kv := range proof.KeyValues {
-		ops[i] = database.BatchOp{
+// buildView returns a view containing the key-value pairs and proof nodes of
+// [proof], given the range returned by [provenRange].
+// [verifiedSubtries] maps the path of each subtrie returned by
+// [verifySubtries] to its ID. The key-value pairs in those subtries aren't
+// inserted into the view; the subtries are added to their parents by ID.
+func (proof *RangeProof) buildView(
+	ctx context.Context,
+	smallestProvenPath maybe.Maybe[path],
+	largestProvenPath maybe.Maybe[path],
+	verifiedSubtries map[path]ids.ID,
+) (*trieView, error) {
+	// Insert all key-value pairs that aren't in a verified subtrie into the trie.
+	ops := make([]database.BatchOp, 0, len(proof.KeyValues))
+	for _, kv := range proof.KeyValues {
+		if len(verifiedSubtries) > 0 && isInSubtrie(newPath(kv.Key), verifiedSubtries) {
+			continue
+		}
+		ops = append(ops, database.BatchOp{
 			Key:   kv.Key,
 			Value: kv.Value,
-		}
+		})
 	}
 
 	// Don't need to lock [view] because nobody else has a reference to it.
 	view, err := getStandaloneTrieView(ctx, ops)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// For all the nodes along the edges of the proof, insert children
@@ -380,7 +492,7 @@ func (proof *RangeProof) Verify(
 		smallestProvenPath,
 		largestProvenPath,
 	); err != nil {
-		return err
+		return nil, err
 	}
 	if err := addPathInfo(
 		view,
@@ -388,19 +500,430 @@ func (proof *RangeProof) Verify(
 		smallestProvenPath,
 		largestProvenPath,
 	); err != nil {
-		return err
+		return nil, err
 	}
 
-	calculatedRoot, err := view.GetMerkleRoot(ctx)
+	// Every verified subtrie is a child of a proof node, which [addPathInfo]
+	// has inserted into [view].
+	for subtriePath, id := range verifiedSubtries {
+		parentPath := subtriePath[:len(subtriePath)-1]
+		parent := view.root
+		if parentPath != RootPath {
+			parent, err = view.getNodeWithID(ids.Empty, parentPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		parent.addChildWithoutNode(subtriePath[len(subtriePath)-1], EmptyPath, id)
+	}
+	return view, nil
+}
+
+// isInSubtrie returns true iff [key] is in one of [subtries].
+func isInSubtrie(key path, subtries map[path]ids.ID) bool {
+	for subtriePath := range subtries {
+		if key.HasPrefix(subtriePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// provenSubtrie is a child of one of a range proof's proof nodes whose keys
+// are all in the range the proof proves.
+type provenSubtrie struct {
+	// The path of the proof node followed by the index of the child.
+	path path
+	// The ID of the child, if the proof node has it.
+	id       ids.ID
+	hasChild bool
+}
+
+// verifySubtries verifies each subtrie that's a child of one of [proof]'s
+// proof nodes and whose keys are all in [smallestProvenPath, largestProvenPath]
+// against the ID the proof node has for it. Since the ID of such a subtrie only
+// depends on the key-value pairs in it, each subtrie is verified on its own,
+// in key order, before the rest of the proof is. Returns an error for the
+// first subtrie whose key-value pairs don't match, without building a view of
+// the remaining key-value pairs.
+// Otherwise returns the path of each verified subtrie mapped to its ID, which
+// can be passed to [buildView].
+func (proof *RangeProof) verifySubtries(
+	ctx context.Context,
+	smallestProvenPath maybe.Maybe[path],
+	largestProvenPath maybe.Maybe[path],
+) (map[path]ids.ID, error) {
+	subtries := proof.provenSubtries(smallestProvenPath, largestProvenPath)
+	if len(subtries) == 0 {
+		return nil, nil
+	}
+
+	keyPaths := make([]path, len(proof.KeyValues))
+	for i, kv := range proof.KeyValues {
+		keyPaths[i] = newPath(kv.Key)
+	}
+
+	// Each subtrie is built in its own view of the same empty database.
+	db, err := newStandaloneDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := make(map[path]ids.ID, len(subtries))
+	for _, subtrie := range subtries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// [keyPaths] is sorted so the keys in [subtrie] are contiguous.
+		start := sort.Search(len(keyPaths), func(i int) bool {
+			return keyPaths[i].Compare(subtrie.path) >= 0
+		})
+		end := start
+		for end < len(keyPaths) && keyPaths[end].HasPrefix(subtrie.path) {
+			end++
+		}
+
+		if !subtrie.hasChild {
+			if end > start {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidSubtrie, subtrie.path.hex())
+			}
+			continue
+		}
+		if end == start {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSubtrie, subtrie.path.hex())
+		}
+
+		id, err := subtrieID(ctx, db, proof.KeyValues[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if id != subtrie.id {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSubtrie, subtrie.path.hex())
+		}
+		verified[subtrie.path] = id
+	}
+	return verified, nil
+}
+
+// provenSubtries returns, sorted by path, the children of [proof]'s proof
+// nodes whose keys are all in [smallestProvenPath, largestProvenPath].
+// A child with a proof node or a bound of the range under it is excluded
+// since only some of its keys may be in the range.
+func (proof *RangeProof) provenSubtries(
+	smallestProvenPath maybe.Maybe[path],
+	largestProvenPath maybe.Maybe[path],
+) []provenSubtrie {
+	proofNodes := make([]ProofNode, 0, len(proof.StartProof)+len(proof.EndProof))
+	proofNodes = append(proofNodes, proof.StartProof...)
+	proofNodes = append(proofNodes, proof.EndProof...)
+
+	proofPaths := make([]path, len(proofNodes))
+	for i, proofNode := range proofNodes {
+		proofPaths[i] = proofNode.KeyPath.deserialize()
+	}
+
+	isOnPath := func(childPath path) bool {
+		if smallestProvenPath.HasValue() && smallestProvenPath.Value().HasPrefix(childPath) {
+			return true
+		}
+		if largestProvenPath.HasValue() && largestProvenPath.Value().HasPrefix(childPath) {
+			return true
+		}
+		for _, proofPath := range proofPaths {
+			if proofPath.HasPrefix(childPath) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var (
+		seen     = set.Set[path]{}
+		subtries []provenSubtrie
+	)
+	for i, proofNode := range proofNodes {
+		for index := byte(0); index < NodeBranchFactor; index++ {
+			childPath := proofPaths[i].Append(index)
+			if seen.Contains(childPath) {
+				continue
+			}
+			seen.Add(childPath)
+
+			// Since [childPath] isn't a prefix of either bound, either all
+			// of the keys under it are in the range or none are.
+			if isOnPath(childPath) ||
+				(smallestProvenPath.HasValue() && childPath.Compare(smallestProvenPath.Value()) < 0) ||
+				(largestProvenPath.HasValue() && childPath.Compare(largestProvenPath.Value()) > 0) {
+				continue
+			}
+
+			id, hasChild := proofNode.Children[index]
+			subtries = append(subtries, provenSubtrie{
+				path:     childPath,
+				id:       id,
+				hasChild: hasChild,
+			})
+		}
+	}
+	slices.SortFunc(subtries, func(a, b provenSubtrie) bool {
+		return a.path.Less(b.path)
+	})
+	return subtries
+}
+
+// subtrieID returns the ID of the only child of the root of the trie
+// containing [keyValues], which all have the same first nibble after the
+// path of the subtrie's parent.
+// Note the compressed path of the child isn't part of its ID.
+func subtrieID(ctx context.Context, db *merkleDB, keyValues []KeyValue) (ids.ID, error) {
+	ops := make([]database.BatchOp, len(keyValues))
+	for i, kv := range keyValues {
+		ops[i] = database.BatchOp{
+			Key:   kv.Key,
+			Value: kv.Value,
+		}
+	}
+	view, err := db.newUntrackedView(ops)
 	if err != nil {
+		return ids.Empty, err
+	}
+	if err := view.calculateNodeIDs(ctx); err != nil {
+		return ids.Empty, err
+	}
+	for _, entry := range view.root.children {
+		return entry.id, nil
+	}
+	return ids.Empty, nil
+}
+
+// VerifyWithBounds returns nil iff [proof] is a valid proof, as returned by
+// [GetRangeProofWithBounds], of the key-value pairs in [bounds] in the trie
+// whose root is [expectedRootID].
+// If [bounds.End] is excluded, [proof.KeyValues] may only contain [bounds.End]
+// if it's the only key, which proves there are no keys in [bounds].
+func (proof *RangeProof) VerifyWithBounds(
+	ctx context.Context,
+	bounds Bounds,
+	expectedRootID ids.ID,
+) error {
+	if err := proof.Verify(ctx, bounds.inclusiveStart(), bounds.End, expectedRootID); err != nil {
 		return err
 	}
-	if expectedRootID != calculatedRoot {
-		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, calculatedRoot, expectedRootID)
+
+	// [proof.KeyValues] is sorted and has no keys > [bounds.End], so only the
+	// last key can be [bounds.End].
+	numKeys := len(proof.KeyValues)
+	if numKeys > 1 && bounds.excludesEnd(proof.KeyValues[numKeys-1].Key) {
+		return ErrExcludedEndInProof
+	}
+	return nil
+}
+
+// StitchRangeProofs returns nil iff [left] and [right] are valid proofs of
+// adjacent ranges in the trie whose root is [rootID], such that together they
+// prove every key-value pair in [start, end] up to the largest key in [right].
+// [left] must prove a range starting at [start] and [right] must prove a range
+// starting at the largest key in [left]. This guarantees that no keys between
+// the largest key in [left] and the smallest key in [right] were skipped.
+// If [start] is Nothing, there's no lower bound on the range.
+// If [end] is Nothing, there's no upper bound on the range.
+func StitchRangeProofs(
+	ctx context.Context,
+	left *RangeProof,
+	right *RangeProof,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	rootID ids.ID,
+) error {
+	if left == nil || right == nil {
+		return ErrNilRangeProof
+	}
+	if len(left.KeyValues) == 0 {
+		return ErrEmptyLeftProof
+	}
+	if err := left.Verify(ctx, start, end, rootID); err != nil {
+		return fmt.Errorf("invalid left proof: %w", err)
+	}
+
+	// [right] is verified to contain every key in [boundary, end] up to its
+	// largest key, which is only possible if its start proof is a proof of
+	// [boundary].
+	boundary := left.KeyValues[len(left.KeyValues)-1].Key
+	if err := right.Verify(ctx, maybe.Some(boundary), end, rootID); err != nil {
+		return fmt.Errorf("invalid right proof starting at %x: %w", boundary, err)
+	}
+	return nil
+}
+
+// validateRanges returns nil iff [ranges] is non-empty, each range has
+// start <= end, and the ranges are sorted and don't overlap.
+func validateRanges(ranges []Bounds) error {
+	if len(ranges) == 0 {
+		return ErrNoRanges
+	}
+	for i, bounds := range ranges {
+		if bounds.Start.HasValue() && bounds.End.HasValue() && bytes.Compare(bounds.Start.Value(), bounds.End.Value()) > 0 {
+			return fmt.Errorf("%w: range %d", ErrStartAfterEnd, i)
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := ranges[i-1]
+		if prev.End.IsNothing() || bounds.Start.IsNothing() {
+			return fmt.Errorf("%w: range %d is unbounded towards range %d", ErrRangesNotDisjoint, i-1, i)
+		}
+		switch cmp := bytes.Compare(prev.End.Value(), bounds.Start.Value()); {
+		case cmp > 0, cmp == 0 && prev.IncludeEnd && bounds.IncludeStart:
+			return fmt.Errorf("%w: range %d overlaps range %d", ErrRangesNotDisjoint, i-1, i)
+		}
+	}
+	return nil
+}
+
+// A proof of the key-value pairs in several disjoint key ranges of the same
+// trie.
+type MultiRangeProof struct {
+	// The proven ranges, sorted and disjoint. This may be a prefix of the
+	// requested ranges if the requested maximum number of keys was reached.
+	// Verifiers should check that these are the ranges they requested.
+	Ranges []Bounds
+
+	// Proofs[i] is a proof, as returned by [GetRangeProofWithBounds], of the
+	// key-value pairs in Ranges[i].
+	Proofs []*RangeProof
+}
+
+// Verify returns nil iff [proof.Ranges] are sorted and disjoint and each of
+// [proof.Proofs] is a valid proof of the key-value pairs in the corresponding
+// range in the trie whose root is [expectedRootID].
+func (proof *MultiRangeProof) Verify(ctx context.Context, expectedRootID ids.ID) error {
+	if len(proof.Ranges) != len(proof.Proofs) {
+		return fmt.Errorf("%w: %d != %d", ErrRangeCountMismatch, len(proof.Ranges), len(proof.Proofs))
+	}
+	if err := validateRanges(proof.Ranges); err != nil {
+		return err
+	}
+	for i, rangeProof := range proof.Proofs {
+		if rangeProof == nil {
+			return ErrNilRangeProof
+		}
+		if err := rangeProof.VerifyWithBounds(ctx, proof.Ranges[i], expectedRootID); err != nil {
+			return fmt.Errorf("invalid proof of range %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// NumKeys returns the number of key-value pairs in [proof].
+func (proof *MultiRangeProof) NumKeys() int {
+	numKeys := 0
+	for _, rangeProof := range proof.Proofs {
+		numKeys += len(rangeProof.KeyValues)
+	}
+	return numKeys
+}
+
+// VerifyAbsence returns nil iff [proof] proves that the trie whose root is
+// [expectedRootID] has no keys strictly between [a] and [b].
+// [a] and [b] themselves may be in the trie.
+func (proof *RangeProof) VerifyAbsence(
+	ctx context.Context,
+	a []byte,
+	b []byte,
+	expectedRootID ids.ID,
+) error {
+	if bytes.Compare(a, b) >= 0 {
+		return ErrStartAfterEnd
+	}
+
+	if err := proof.Verify(
+		ctx,
+		maybe.Some(absenceProofStart(a)),
+		maybe.Some(b),
+		expectedRootID,
+	); err != nil {
+		return err
+	}
+
+	// [proof] contains every key in [absenceProofStart(a), b] up to its
+	// largest key, so the gap is empty iff [b] is the only key it contains.
+	for _, kv := range proof.KeyValues {
+		if !bytes.Equal(kv.Key, b) {
+			return fmt.Errorf("%w: %x", ErrKeyInAbsenceRange, kv.Key)
+		}
 	}
 	return nil
 }
 
+// absenceProofStart returns the smallest key that is greater than [a].
+func absenceProofStart(a []byte) []byte {
+	start := make([]byte, len(a)+1)
+	copy(start, a)
+	return start
+}
+
+// MarshalCompact returns the compact encoding of [proof]. Prefixes shared by
+// consecutive proof nodes and consecutive keys are only encoded once, so the
+// encoding is smaller than the protobuf encoding for proofs of dense keys.
+func (proof *RangeProof) MarshalCompact() []byte {
+	return codec.encodeCompactRangeProof(proof)
+}
+
+// CanonicalBytes returns an encoding of [proof] that only depends on its proof
+// nodes and key/value pairs, not on the order in which they're stored in
+// [proof]. The encoding is stable across versions, so it's suitable for
+// hashing to identify proofs.
+func (proof *RangeProof) CanonicalBytes() []byte {
+	return codec.encodeCanonicalRangeProof(proof)
+}
+
+// UnmarshalCompactRangeProof returns the range proof encoded by
+// [RangeProof.MarshalCompact].
+func UnmarshalCompactRangeProof(b []byte) (*RangeProof, error) {
+	proof := &RangeProof{}
+	if err := codec.decodeCompactRangeProof(b, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// keyValueProofSize returns the approximate size of a key/value pair with a
+// key of [keyLen] bytes and a value of [valueLen] bytes in a serialized range
+// proof.
+func keyValueProofSize(keyLen, valueLen int) int {
+	return messageFieldSize(bytesFieldSize(keyLen) + bytesFieldSize(valueLen))
+}
+
+// proofNodeSize returns the approximate size of [n] in a serialized proof.
+func proofNodeSize(n *node) int {
+	keySize := 1 + protowire.SizeVarint(uint64(len(n.key))) + bytesFieldSize((len(n.key)+1)/2)
+
+	valueOrHashSize := 2 // is_nothing
+	if n.valueDigest.HasValue() {
+		valueOrHashSize = bytesFieldSize(len(n.valueDigest.Value()))
+	}
+
+	size := messageFieldSize(keySize) + messageFieldSize(valueOrHashSize)
+	for index := range n.children {
+		size += messageFieldSize(1 + protowire.SizeVarint(uint64(index)) + bytesFieldSize(ids.IDLen))
+	}
+	return messageFieldSize(size)
+}
+
+// bytesFieldSize returns the size of a serialized protobuf bytes field of
+// [length] bytes.
+func bytesFieldSize(length int) int {
+	return 1 + protowire.SizeBytes(length)
+}
+
+// messageFieldSize returns the size of a serialized protobuf message field
+// whose contents are [size] bytes.
+func messageFieldSize(size int) int {
+	return 1 + protowire.SizeBytes(size)
+}
+
 func (proof *RangeProof) ToProto() *pb.RangeProof {
 	startProof := make([]*pb.ProofNode, len(proof.StartProof))
 	for i, node := range proof.StartProof {
@@ -631,6 +1154,62 @@ func (proof *ChangeProof) UnmarshalProto(pbProof *pb.ChangeProof) error {
 	return nil
 }
 
+// WriteTo writes [proof] to [w] prefixed by its length, so multiple proofs can
+// be written one after another and read back with [ReadChangeProof].
+// Returns the number of bytes written.
+func (proof *ChangeProof) WriteTo(w io.Writer) (int64, error) {
+	proofBytes, err := proto.Marshal(proof.ToProto())
+	if err != nil {
+		return 0, err
+	}
+
+	var lenBytes [wrappers.LongLen]byte
+	binary.BigEndian.PutUint64(lenBytes[:], uint64(len(proofBytes)))
+	n, err := w.Write(lenBytes[:])
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	n, err = w.Write(proofBytes)
+	return written + int64(n), err
+}
+
+// ReadChangeProof reads the next proof written by [ChangeProof.WriteTo] from
+// [r]. Returns io.EOF if there are no more proofs in [r] and
+// io.ErrUnexpectedEOF if [r] ends in the middle of a proof.
+// The returned proof should be verified before it's used.
+func ReadChangeProof(r io.Reader) (*ChangeProof, error) {
+	var lenBytes [wrappers.LongLen]byte
+	if _, err := io.ReadFull(r, lenBytes[:]); err != nil {
+		return nil, err
+	}
+	proofLen := binary.BigEndian.Uint64(lenBytes[:])
+	if proofLen > math.MaxInt64 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidProofLength, proofLen)
+	}
+
+	// The proof is copied rather than read into a buffer of [proofLen] bytes
+	// so that a corrupt length can't cause a huge allocation.
+	var proofBytes bytes.Buffer
+	if _, err := io.CopyN(&proofBytes, r, int64(proofLen)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	var pbProof pb.ChangeProof
+	if err := proto.Unmarshal(proofBytes.Bytes(), &pbProof); err != nil {
+		return nil, err
+	}
+	var proof ChangeProof
+	if err := proof.UnmarshalProto(&pbProof); err != nil {
+		return nil, err
+	}
+	return &proof, nil
+}
+
 // Verifies that all values present in the [proof]:
 // - Are nothing when deleted, not in the db, or the node has an odd path length.
 // - if the node's path is within the key range, that has a value that matches the value passed in the change list or in the db
@@ -864,11 +1443,20 @@ func addPathInfo(
 
 // getStandaloneTrieView returns a new view that has nothing in it besides the changes due to [ops]
 func getStandaloneTrieView(ctx context.Context, ops []database.BatchOp) (*trieView, error) {
+	db, err := newStandaloneDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return db.newUntrackedView(ops)
+}
+
+// newStandaloneDatabase returns a new empty in-memory database
+func newStandaloneDatabase(ctx context.Context) (*merkleDB, error) {
 	tracer, err := trace.New(trace.Config{Enabled: false})
 	if err != nil {
 		return nil, err
 	}
-	db, err := newDatabase(
+	return newDatabase(
 		ctx,
 		memdb.New(),
 		Config{
@@ -878,9 +1466,4 @@ func getStandaloneTrieView(ctx context.Context, ops []database.BatchOp) (*trieVi
 		},
 		&mockMetrics{},
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return db.newUntrackedView(ops)
 }
@@ -6,12 +6,24 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/database/memdb"
 	"github.com/ava-labs/avalanchego/ids"
@@ -659,6 +671,597 @@ func Test_RangeProof_EmptyValues(t *testing.T) {
 	))
 }
 
+func Test_RangeProof_Absence(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte{1}, []byte{1}))
+	require.NoError(batch.Put([]byte{3}, []byte{3}))
+	require.NoError(batch.Put([]byte{3, 1}, []byte{3, 1}))
+	require.NoError(batch.Put([]byte{5}, nil))
+	require.NoError(batch.Write())
+
+	rootID, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	tests := []struct {
+		name        string
+		a           []byte
+		b           []byte
+		expectedErr error
+	}{
+		{
+			name: "empty gap; both bounds exist",
+			a:    []byte{1},
+			b:    []byte{3},
+		},
+		{
+			name: "empty gap; only lower bound exists",
+			a:    []byte{5},
+			b:    []byte{9},
+		},
+		{
+			name: "empty gap; only upper bound exists",
+			a:    []byte{0},
+			b:    []byte{1},
+		},
+		{
+			name: "empty gap; neither bound exists",
+			a:    []byte{6},
+			b:    []byte{9},
+		},
+		{
+			name: "empty gap; bound is a prefix of the other",
+			a:    []byte{3, 1},
+			b:    []byte{5},
+		},
+		{
+			name:        "non-empty gap",
+			a:           []byte{0},
+			b:           []byte{2},
+			expectedErr: ErrKeyInAbsenceRange,
+		},
+		{
+			name:        "non-empty gap; key extends the lower bound",
+			a:           []byte{3},
+			b:           []byte{5},
+			expectedErr: ErrKeyInAbsenceRange,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(*testing.T) {
+			proof, err := db.GetAbsenceProof(context.Background(), tt.a, tt.b)
+			require.NoError(err)
+
+			err = proof.VerifyAbsence(context.Background(), tt.a, tt.b, rootID)
+			require.ErrorIs(err, tt.expectedErr)
+
+			// The proof is only valid for the root it was generated against.
+			err = proof.VerifyAbsence(context.Background(), tt.a, tt.b, ids.GenerateTestID())
+			require.ErrorIs(err, ErrInvalidProof)
+		})
+	}
+
+	// Omitting the keys in a non-empty gap doesn't produce a valid proof.
+	proof, err := db.GetAbsenceProof(context.Background(), []byte{0}, []byte{2})
+	require.NoError(err)
+	proof.KeyValues = nil
+	err = proof.VerifyAbsence(context.Background(), []byte{0}, []byte{2}, rootID)
+	require.ErrorIs(err, ErrProofNodeHasUnincludedValue)
+
+	_, err = db.GetAbsenceProof(context.Background(), []byte{1}, []byte{1})
+	require.ErrorIs(err, ErrStartAfterEnd)
+}
+
+func Test_RangeProof_ComputeRoot(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 500; i++ {
+		key := make([]byte, r.Intn(8)+1)
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(8))
+		_, _ = r.Read(value)
+		require.NoError(db.Put(key, value))
+	}
+	expectedRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	for i := 0; i < 20; i++ {
+		start := maybe.Nothing[[]byte]()
+		if r.Intn(2) == 0 {
+			startBytes := make([]byte, r.Intn(8)+1)
+			_, _ = r.Read(startBytes)
+			start = maybe.Some(startBytes)
+		}
+		end := maybe.Nothing[[]byte]()
+		if r.Intn(2) == 0 {
+			endBytes := make([]byte, r.Intn(8)+1)
+			_, _ = r.Read(endBytes)
+			end = maybe.Some(endBytes)
+		}
+		if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0 {
+			start, end = end, start
+		}
+
+		proof, err := db.GetRangeProof(context.Background(), start, end, r.Intn(50)+1)
+		require.NoError(err)
+
+		root, err := proof.ComputeRoot(context.Background(), start, end)
+		require.NoError(err)
+		require.Equal(expectedRoot, root)
+		require.NoError(proof.Verify(context.Background(), start, end, root))
+	}
+
+	// Changing a proven value changes the computed root.
+	proof, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	proof.KeyValues[0].Value = append(proof.KeyValues[0].Value, 0)
+	root, err := proof.ComputeRoot(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte]())
+	if err == nil {
+		require.NotEqual(expectedRoot, root)
+	}
+
+	_, err = proof.ComputeRoot(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{0}))
+	require.ErrorIs(err, ErrStartAfterEnd)
+}
+
+func Test_RangeProof_Bounds(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := byte(1); i <= 5; i++ {
+		require.NoError(db.Put([]byte{i}, []byte{i}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	tests := []struct {
+		name         string
+		bounds       Bounds
+		maxLength    int
+		expectedKeys [][]byte
+	}{
+		{
+			name: "include start; include end",
+			bounds: Bounds{
+				Start:        maybe.Some([]byte{2}),
+				End:          maybe.Some([]byte{4}),
+				IncludeStart: true,
+				IncludeEnd:   true,
+			},
+			maxLength:    10,
+			expectedKeys: [][]byte{{2}, {3}, {4}},
+		},
+		{
+			name: "exclude start; include end",
+			bounds: Bounds{
+				Start:      maybe.Some([]byte{2}),
+				End:        maybe.Some([]byte{4}),
+				IncludeEnd: true,
+			},
+			maxLength:    10,
+			expectedKeys: [][]byte{{3}, {4}},
+		},
+		{
+			name: "include start; exclude end",
+			bounds: Bounds{
+				Start:        maybe.Some([]byte{2}),
+				End:          maybe.Some([]byte{4}),
+				IncludeStart: true,
+			},
+			maxLength:    10,
+			expectedKeys: [][]byte{{2}, {3}},
+		},
+		{
+			name: "exclude start; exclude end",
+			bounds: Bounds{
+				Start: maybe.Some([]byte{2}),
+				End:   maybe.Some([]byte{4}),
+			},
+			maxLength:    10,
+			expectedKeys: [][]byte{{3}},
+		},
+		{
+			name: "exclude end; limited by max length",
+			bounds: Bounds{
+				Start:        maybe.Some([]byte{2}),
+				End:          maybe.Some([]byte{4}),
+				IncludeStart: true,
+			},
+			maxLength:    1,
+			expectedKeys: [][]byte{{2}},
+		},
+		{
+			name: "exclude end; no keys in range",
+			bounds: Bounds{
+				Start: maybe.Some([]byte{3}),
+				End:   maybe.Some([]byte{4}),
+			},
+			maxLength:    10,
+			expectedKeys: [][]byte{{4}},
+		},
+		{
+			name: "no bounds",
+			bounds: Bounds{
+				Start: maybe.Nothing[[]byte](),
+				End:   maybe.Nothing[[]byte](),
+			},
+			maxLength:    10,
+			expectedKeys: [][]byte{{1}, {2}, {3}, {4}, {5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(*testing.T) {
+			proof, err := db.GetRangeProofWithBounds(context.Background(), tt.bounds, tt.maxLength)
+			require.NoError(err)
+
+			keys := make([][]byte, len(proof.KeyValues))
+			for i, kv := range proof.KeyValues {
+				keys[i] = kv.Key
+			}
+			require.Equal(tt.expectedKeys, keys)
+			require.NoError(proof.VerifyWithBounds(context.Background(), tt.bounds, root))
+		})
+	}
+
+	// A proof that includes the excluded end along with other keys is
+	// rejected.
+	bounds := Bounds{
+		Start:        maybe.Some([]byte{2}),
+		End:          maybe.Some([]byte{4}),
+		IncludeStart: true,
+	}
+	proof, err := db.GetRangeProof(context.Background(), bounds.Start, bounds.End, 10)
+	require.NoError(err)
+	require.Len(proof.KeyValues, 3)
+	err = proof.VerifyWithBounds(context.Background(), bounds, root)
+	require.ErrorIs(err, ErrExcludedEndInProof)
+}
+
+func Test_MultiRangeProof(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := byte(1); i <= 9; i++ {
+		require.NoError(db.Put([]byte{i}, []byte{i}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	ranges := []Bounds{
+		{
+			Start:        maybe.Some([]byte{2}),
+			End:          maybe.Some([]byte{3}),
+			IncludeStart: true,
+			IncludeEnd:   true,
+		},
+		{
+			Start:        maybe.Some([]byte{6}),
+			End:          maybe.Nothing[[]byte](),
+			IncludeStart: true,
+		},
+	}
+	proof, err := db.GetMultiRangeProof(context.Background(), ranges, 10)
+	require.NoError(err)
+	require.Equal(ranges, proof.Ranges)
+	require.Len(proof.Proofs, 2)
+	require.Equal([]KeyValue{{Key: []byte{2}, Value: []byte{2}}, {Key: []byte{3}, Value: []byte{3}}}, proof.Proofs[0].KeyValues)
+	require.Len(proof.Proofs[1].KeyValues, 4)
+	require.Equal(6, proof.NumKeys())
+	require.NoError(proof.Verify(context.Background(), root))
+
+	// The proof doesn't verify against another root.
+	err = proof.Verify(context.Background(), ids.GenerateTestID())
+	require.ErrorIs(err, ErrInvalidProof)
+
+	// Ranges past the maximum number of keys are omitted.
+	proof, err = db.GetMultiRangeProof(context.Background(), ranges, 2)
+	require.NoError(err)
+	require.Equal(ranges[:1], proof.Ranges)
+	require.NoError(proof.Verify(context.Background(), root))
+
+	// A proof whose ranges were swapped doesn't verify.
+	proof, err = db.GetMultiRangeProof(context.Background(), ranges, 10)
+	require.NoError(err)
+	proof.Ranges[0], proof.Ranges[1] = proof.Ranges[1], proof.Ranges[0]
+	err = proof.Verify(context.Background(), root)
+	require.ErrorIs(err, ErrRangesNotDisjoint)
+
+	// Overlapping ranges are rejected.
+	_, err = db.GetMultiRangeProof(context.Background(), []Bounds{
+		{
+			Start:      maybe.Some([]byte{2}),
+			End:        maybe.Some([]byte{4}),
+			IncludeEnd: true,
+		},
+		{
+			Start:        maybe.Some([]byte{4}),
+			End:          maybe.Some([]byte{6}),
+			IncludeStart: true,
+		},
+	}, 10)
+	require.ErrorIs(err, ErrRangesNotDisjoint)
+
+	// Ranges that share an excluded endpoint don't overlap.
+	adjacentRanges := []Bounds{
+		{
+			Start: maybe.Some([]byte{2}),
+			End:   maybe.Some([]byte{4}),
+		},
+		{
+			Start:        maybe.Some([]byte{4}),
+			End:          maybe.Some([]byte{6}),
+			IncludeStart: true,
+		},
+	}
+	proof, err = db.GetMultiRangeProof(context.Background(), adjacentRanges, 10)
+	require.NoError(err)
+	require.NoError(proof.Verify(context.Background(), root))
+
+	_, err = db.GetMultiRangeProof(context.Background(), nil, 10)
+	require.ErrorIs(err, ErrNoRanges)
+}
+
+func Test_SampleProof(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	_, _, err = db.SampleProof(context.Background(), 0, 0)
+	require.ErrorIs(err, ErrInvalidMaxLength)
+
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+	batch := db.NewBatch()
+	for i := 0; i < 1000; i++ {
+		key := make([]byte, r.Intn(8)+1)
+		_, _ = r.Read(key)
+		require.NoError(batch.Put(key, key))
+	}
+	require.NoError(batch.Write())
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	proof, keys, err := db.SampleProof(context.Background(), 1, 10)
+	require.NoError(err)
+	require.Len(keys, 10)
+	require.NoError(proof.Verify(context.Background(), maybe.Some(keys[0]), maybe.Nothing[[]byte](), root))
+	for i, kv := range proof.KeyValues {
+		require.Equal(keys[i], kv.Key)
+	}
+
+	// The same seed yields the same sample.
+	_, sameKeys, err := db.SampleProof(context.Background(), 1, 10)
+	require.NoError(err)
+	require.Equal(keys, sameKeys)
+
+	// A replica with the same key/value pairs yields the same sample.
+	replica, err := getBasicDB()
+	require.NoError(err)
+	it := db.NewIterator()
+	for it.Next() {
+		require.NoError(replica.Put(it.Key(), it.Value()))
+	}
+	require.NoError(it.Error())
+	it.Release()
+	_, replicaKeys, err := replica.SampleProof(context.Background(), 1, 10)
+	require.NoError(err)
+	require.Equal(keys, replicaKeys)
+
+	// A different seed yields a different sample.
+	_, otherKeys, err := db.SampleProof(context.Background(), 2, 10)
+	require.NoError(err)
+	require.Len(otherKeys, 10)
+	require.NotEqual(keys, otherKeys)
+
+	// If there are fewer keys than the sample size, every key is sampled.
+	smallDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(smallDB.Put([]byte("a"), []byte("1")))
+	require.NoError(smallDB.Put([]byte("b"), []byte("2")))
+	_, smallKeys, err := smallDB.SampleProof(context.Background(), 1, 10)
+	require.NoError(err)
+	require.Equal([][]byte{[]byte("a"), []byte("b")}, smallKeys)
+}
+
+func Test_SampleProofConcurrentPuts(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	const numKeys = 100
+	for i := 0; i < numKeys; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		require.NoError(db.Put(key, key))
+	}
+
+	// Samples are taken while commits are waiting to be made.
+	var eg errgroup.Group
+	eg.Go(func() error {
+		for i := numKeys; i < 2*numKeys; i++ {
+			key := []byte(fmt.Sprintf("key%d", i))
+			if err := db.Put(key, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	eg.Go(func() error {
+		for i := 0; i < numKeys; i++ {
+			if _, _, err := db.SampleProof(context.Background(), uint64(i), 10); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(eg.Wait())
+}
+
+func Test_RangeProof_CanonicalBytes(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+
+	keyValues := make([]KeyValue, 200)
+	for i := range keyValues {
+		key := make([]byte, r.Intn(4)+1)
+		_, _ = r.Read(key)
+		keyValues[i] = KeyValue{Key: key, Value: []byte{byte(i)}}
+	}
+
+	// Insert the same data into two fresh databases in different orders.
+	db1, err := getBasicDB()
+	require.NoError(err)
+	for _, kv := range keyValues {
+		require.NoError(db1.Put(kv.Key, kv.Value))
+	}
+	db2, err := getBasicDB()
+	require.NoError(err)
+	for i := len(keyValues) - 1; i >= 0; i-- {
+		// Values of duplicate keys must match the last write to [db1].
+		if _, err := db2.Get(keyValues[i].Key); err == nil {
+			continue
+		}
+		value, err := db1.Get(keyValues[i].Key)
+		require.NoError(err)
+		require.NoError(db2.Put(keyValues[i].Key, value))
+	}
+
+	start := maybe.Some([]byte{0x10})
+	end := maybe.Some([]byte{0xF0})
+	proof1, err := db1.GetRangeProof(context.Background(), start, end, 50)
+	require.NoError(err)
+	proof2, err := db2.GetRangeProof(context.Background(), start, end, 50)
+	require.NoError(err)
+	require.Equal(proof1.CanonicalBytes(), proof2.CanonicalBytes())
+
+	// The order of the proof's fields doesn't affect the encoding.
+	shuffled := &RangeProof{
+		StartProof: slices.Clone(proof1.StartProof),
+		EndProof:   slices.Clone(proof1.EndProof),
+		KeyValues:  slices.Clone(proof1.KeyValues),
+	}
+	r.Shuffle(len(shuffled.StartProof), func(i, j int) {
+		shuffled.StartProof[i], shuffled.StartProof[j] = shuffled.StartProof[j], shuffled.StartProof[i]
+	})
+	r.Shuffle(len(shuffled.EndProof), func(i, j int) {
+		shuffled.EndProof[i], shuffled.EndProof[j] = shuffled.EndProof[j], shuffled.EndProof[i]
+	})
+	r.Shuffle(len(shuffled.KeyValues), func(i, j int) {
+		shuffled.KeyValues[i], shuffled.KeyValues[j] = shuffled.KeyValues[j], shuffled.KeyValues[i]
+	})
+	require.Equal(proof1.CanonicalBytes(), shuffled.CanonicalBytes())
+
+	// A different proof has a different encoding.
+	proof3, err := db1.GetRangeProof(context.Background(), start, end, 49)
+	require.NoError(err)
+	require.NotEqual(proof1.CanonicalBytes(), proof3.CanonicalBytes())
+
+	// The encoding must not change between versions.
+	proof := &RangeProof{
+		EndProof: []ProofNode{
+			{
+				KeyPath:     SerializedPath{NibbleLength: 1, Value: []byte{0x10}},
+				ValueOrHash: maybe.Some([]byte{2}),
+			},
+			{
+				KeyPath: SerializedPath{Value: []byte{}},
+				Children: map[byte]ids.ID{
+					1: {1},
+				},
+			},
+		},
+		KeyValues: []KeyValue{
+			{Key: []byte{0x10}, Value: []byte{2}},
+			{Key: []byte{0x01}, Value: []byte{1}},
+		},
+	}
+	expected := []byte{
+		canonicalRangeProofVersion,
+		// Start proof
+		0x00, // number of nodes
+		// End proof
+		0x04, // number of nodes
+		0x00, // root key
+		0x00, // root value is nothing
+		0x02, // number of children
+		0x02, // child index
+	}
+	childID := ids.ID{1}
+	expected = append(expected, childID[:]...)
+	expected = append(expected,
+		0x02, 0x10, // key
+		0x01, 0x02, 0x02, // value
+		0x00, // number of children
+		// Key/values
+		0x04,       // number of key/values
+		0x02, 0x01, // key
+		0x02, 0x01, // value
+		0x02, 0x10, // key
+		0x02, 0x02, // value
+	)
+	require.Equal(expected, proof.CanonicalBytes())
+}
+
+func Test_RangeProof_Compact(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	// Dense keys share long prefixes.
+	prefix := bytes.Repeat([]byte{0xAB}, 32)
+	for i := 0; i < 256; i++ {
+		key := append(slices.Clone(prefix), byte(i), byte(i))
+		require.NoError(db.Put(key, []byte{byte(i)}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	start := maybe.Some(append(slices.Clone(prefix), 16))
+	end := maybe.Some(append(slices.Clone(prefix), 200))
+	proof, err := db.GetRangeProof(context.Background(), start, end, 100)
+	require.NoError(err)
+	require.NotEmpty(proof.StartProof)
+	require.NotEmpty(proof.EndProof)
+	require.Len(proof.KeyValues, 100)
+
+	compactBytes := proof.MarshalCompact()
+	protoBytes, err := proto.Marshal(proof.ToProto())
+	require.NoError(err)
+	require.Less(len(compactBytes), len(protoBytes))
+
+	decodedProof, err := UnmarshalCompactRangeProof(compactBytes)
+	require.NoError(err)
+	require.Equal(proof.StartProof, decodedProof.StartProof)
+	require.Equal(proof.EndProof, decodedProof.EndProof)
+	require.Equal(proof.KeyValues, decodedProof.KeyValues)
+	require.NoError(decodedProof.Verify(context.Background(), start, end, root))
+
+	computedRoot, err := decodedProof.ComputeRoot(context.Background(), start, end)
+	require.NoError(err)
+	require.Equal(root, computedRoot)
+
+	// Truncated encodings are rejected.
+	_, err = UnmarshalCompactRangeProof(compactBytes[:len(compactBytes)-1])
+	require.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	// Extra bytes are rejected.
+	_, err = UnmarshalCompactRangeProof(append(compactBytes, 0))
+	require.ErrorIs(err, errExtraSpace)
+}
+
 func Test_ChangeProof_Missing_History_For_EndRoot(t *testing.T) {
 	require := require.New(t)
 
@@ -671,6 +1274,112 @@ func Test_ChangeProof_Missing_History_For_EndRoot(t *testing.T) {
 	require.ErrorIs(err, ErrInsufficientHistory)
 }
 
+func Test_MerkleDB_GetDiffProof(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	peerDB, err := getBasicDB()
+	require.NoError(err)
+
+	for i := byte(0); i < 5; i++ {
+		require.NoError(db.Put([]byte{i}, []byte{i}))
+		require.NoError(peerDB.Put([]byte{i}, []byte{i}))
+	}
+	fromRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Make 5 changes, which don't fit in a single proof of 3 keys.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte{0}, []byte{10}))
+	require.NoError(batch.Delete([]byte{2}))
+	require.NoError(batch.Put([]byte{3}, []byte{13}))
+	require.NoError(batch.Put([]byte{5}, []byte{5}))
+	require.NoError(batch.Put([]byte{6}, []byte{6}))
+	require.NoError(batch.Write())
+	toRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	start := maybe.Nothing[[]byte]()
+	proof, next, err := db.GetDiffProof(context.Background(), fromRoot, toRoot, start, 3)
+	require.NoError(err)
+	require.Len(proof.KeyChanges, 3)
+	require.Equal(maybe.Some([]byte{3, 0}), next)
+	require.NoError(peerDB.VerifyChangeProof(context.Background(), proof, start, maybe.Nothing[[]byte](), toRoot))
+	require.NoError(peerDB.CommitChangeProof(context.Background(), proof))
+
+	// The second proof continues after the last key of the first.
+	start = next
+	proof, next, err = db.GetDiffProof(context.Background(), fromRoot, toRoot, start, 3)
+	require.NoError(err)
+	require.Equal(
+		[]KeyChange{
+			{Key: []byte{5}, Value: maybe.Some([]byte{5})},
+			{Key: []byte{6}, Value: maybe.Some([]byte{6})},
+		},
+		proof.KeyChanges,
+	)
+	require.False(next.HasValue())
+	require.NoError(peerDB.VerifyChangeProof(context.Background(), proof, start, maybe.Nothing[[]byte](), toRoot))
+	require.NoError(peerDB.CommitChangeProof(context.Background(), proof))
+
+	peerRoot, err := peerDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(toRoot, peerRoot)
+}
+
+func Test_MerkleDB_EstimateRangeProofCost(t *testing.T) {
+	require := require.New(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 1_000; i++ {
+		key := make([]byte, r.Intn(32))
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(64))
+		_, _ = r.Read(value)
+		require.NoError(db.Put(key, value))
+	}
+
+	randBound := func() maybe.Maybe[[]byte] {
+		if r.Intn(4) == 0 {
+			return maybe.Nothing[[]byte]()
+		}
+		bound := make([]byte, r.Intn(32))
+		_, _ = r.Read(bound)
+		return maybe.Some(bound)
+	}
+
+	for i := 0; i < 100; i++ {
+		start, end := randBound(), randBound()
+		if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) > 0 {
+			start, end = end, start
+		}
+		maxKeys := r.Intn(200) + 1
+
+		nodes, size, err := db.EstimateRangeProofCost(context.Background(), start, end, maxKeys)
+		require.NoError(err)
+
+		proof, err := db.GetRangeProof(context.Background(), start, end, maxKeys)
+		require.NoError(err)
+		require.Equal(len(proof.StartProof)+len(proof.EndProof), nodes)
+
+		// The size is only approximate since empty fields aren't serialized.
+		proofSize := proto.Size(proof.ToProto())
+		require.InDelta(proofSize, size, float64(proofSize)/10)
+	}
+
+	_, _, err = db.EstimateRangeProofCost(context.Background(), maybe.Some([]byte{1}), maybe.Some([]byte{0}), 10)
+	require.ErrorIs(err, ErrStartAfterEnd)
+
+	_, _, err = db.EstimateRangeProofCost(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 0)
+	require.ErrorIs(err, ErrInvalidMaxLength)
+}
+
 func Test_ChangeProof_BadBounds(t *testing.T) {
 	require := require.New(t)
 
@@ -774,6 +1483,81 @@ func Test_ChangeProof_Verify(t *testing.T) {
 	require.NoError(dbClone.VerifyChangeProof(context.Background(), proof, maybe.Some([]byte("key20")), maybe.Some([]byte("key30")), db.getMerkleRoot()))
 }
 
+func TestChangeProofWriteToRead(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	dbClone, err := getBasicDB()
+	require.NoError(err)
+	for i := 0; i < 10; i++ {
+		key := []byte{byte(i)}
+		require.NoError(db.Put(key, key))
+		require.NoError(dbClone.Put(key, key))
+	}
+	startRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	batch := db.NewBatch()
+	for i := 0; i < 10; i++ {
+		key := []byte{byte(i)}
+		if i%3 == 0 {
+			require.NoError(batch.Delete(key))
+		} else {
+			require.NoError(batch.Put(key, []byte{byte(i), 1}))
+		}
+		require.NoError(batch.Put([]byte{byte(i), 0}, key))
+	}
+	require.NoError(batch.Write())
+	endRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Write the changes as two proofs to the same stream.
+	var (
+		mid    = []byte{5}
+		ranges = []struct {
+			start maybe.Maybe[[]byte]
+			end   maybe.Maybe[[]byte]
+		}{
+			{start: maybe.Nothing[[]byte](), end: maybe.Some(mid)},
+			{start: maybe.Some(append(mid, 0)), end: maybe.Nothing[[]byte]()},
+		}
+		buf     bytes.Buffer
+		written int64
+	)
+	for _, r := range ranges {
+		proof, err := db.GetChangeProof(context.Background(), startRoot, endRoot, r.start, r.end, 100)
+		require.NoError(err)
+		require.NotEmpty(proof.KeyChanges)
+
+		n, err := proof.WriteTo(&buf)
+		require.NoError(err)
+		written += n
+	}
+	proofBytes := slices.Clone(buf.Bytes())
+	require.Len(proofBytes, int(written))
+
+	// Read the proofs back and apply them.
+	for _, r := range ranges {
+		proof, err := ReadChangeProof(&buf)
+		require.NoError(err)
+		require.NoError(dbClone.VerifyChangeProof(context.Background(), proof, r.start, r.end, endRoot))
+		require.NoError(dbClone.CommitChangeProof(context.Background(), proof))
+	}
+	_, err = ReadChangeProof(&buf)
+	require.ErrorIs(err, io.EOF)
+
+	root, err := dbClone.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(endRoot, root)
+
+	// A truncated stream can't be read.
+	_, err = ReadChangeProof(bytes.NewReader(proofBytes[:len(proofBytes)/4]))
+	require.ErrorIs(err, io.ErrUnexpectedEOF)
+	_, err = ReadChangeProof(bytes.NewReader(proofBytes[:4]))
+	require.ErrorIs(err, io.ErrUnexpectedEOF)
+}
+
 func Test_ChangeProof_Verify_Bad_Data(t *testing.T) {
 	type test struct {
 		name        string
@@ -1900,3 +2684,124 @@ func FuzzChangeProof(f *testing.F) {
 		}
 	})
 }
+
+// recordingTracer is a tracer whose spans are recorded by an in-memory
+// span recorder.
+type recordingTracer struct {
+	oteltrace.Tracer
+}
+
+func (recordingTracer) Close() error {
+	return nil
+}
+
+func Test_Proof_TracingSpans(t *testing.T) {
+	require := require.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	config := newDefaultConfig()
+	config.Tracer = recordingTracer{Tracer: provider.Tracer("merkledb")}
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte{0}, []byte{1}))
+	startRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NoError(db.Put([]byte{1}, []byte{2}))
+	endRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	rangeProof, err := db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+	require.Len(rangeProof.KeyValues, 2)
+
+	_, err = db.GetChangeProof(context.Background(), startRoot, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 10)
+	require.NoError(err)
+
+	require.NoError(db.CommitRangeProof(context.Background(), maybe.Nothing[[]byte](), rangeProof))
+
+	// Spans must also be ended when the operation fails.
+	_, err = db.GetRangeProof(context.Background(), maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 0)
+	require.ErrorIs(err, ErrInvalidMaxLength)
+
+	attributes := map[string]map[string]int64{}
+	counts := map[string]int{}
+	for _, span := range recorder.Ended() {
+		counts[span.Name()]++
+		if _, ok := attributes[span.Name()]; ok {
+			continue
+		}
+		attrs := map[string]int64{}
+		for _, attr := range span.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.AsInt64()
+		}
+		attributes[span.Name()] = attrs
+	}
+
+	require.Equal(2, counts["MerkleDB.GetRangeProof"])
+	require.Equal(1, counts["MerkleDB.GetChangeProof"])
+	require.Equal(1, counts["MerkleDB.CommitRangeProof"])
+
+	require.Equal(map[string]int64{
+		"maxLength": 10,
+		"keyCount":  2,
+		"rangeSize": 4,
+	}, attributes["MerkleDB.GetRangeProof"])
+	require.Equal(map[string]int64{
+		"maxLength": 10,
+		"keyCount":  1,
+		"rangeSize": 2,
+	}, attributes["MerkleDB.GetChangeProof"])
+	require.Equal(map[string]int64{
+		"keyCount":  2,
+		"rangeSize": 4,
+	}, attributes["MerkleDB.CommitRangeProof"])
+}
+
+func Test_StitchRangeProofs(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	for i := byte(0); i < 10; i++ {
+		require.NoError(db.Put([]byte{i}, []byte{i}))
+	}
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	var (
+		start = maybe.Some([]byte{1})
+		end   = maybe.Some([]byte{8})
+	)
+	left, err := db.GetRangeProof(context.Background(), start, end, 3)
+	require.NoError(err)
+	require.Len(left.KeyValues, 3)
+
+	// The right proof starts at the largest key of the left proof.
+	right, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{3}), end, 10)
+	require.NoError(err)
+	require.NoError(StitchRangeProofs(context.Background(), left, right, start, end, root))
+
+	// A right proof that starts after a key that wasn't in the left proof
+	// skips it.
+	skippingRight, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{5}), end, 10)
+	require.NoError(err)
+	require.NoError(skippingRight.Verify(context.Background(), maybe.Some([]byte{5}), end, root))
+	err = StitchRangeProofs(context.Background(), left, skippingRight, start, end, root)
+	require.ErrorIs(err, ErrInvalidProof)
+
+	// A right proof that omits a key after the boundary is invalid.
+	skippingRight, err = db.GetRangeProof(context.Background(), maybe.Some([]byte{3}), end, 10)
+	require.NoError(err)
+	skippingRight.KeyValues = append(skippingRight.KeyValues[:1], skippingRight.KeyValues[2:]...)
+	err = StitchRangeProofs(context.Background(), left, skippingRight, start, end, root)
+	require.ErrorIs(err, ErrInvalidProof)
+
+	// There's no boundary if the left proof is empty.
+	emptyLeft, err := db.GetRangeProof(context.Background(), maybe.Some([]byte{10}), maybe.Some([]byte{11}), 10)
+	require.NoError(err)
+	err = StitchRangeProofs(context.Background(), emptyLeft, right, start, end, root)
+	require.ErrorIs(err, ErrEmptyLeftProof)
+}
@@ -0,0 +1,110 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/versiondb"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var (
+	_ database.Database = (*readOnlyDB)(nil)
+	_ database.Batch    = (*readOnlyBatch)(nil)
+
+	ErrReadOnly = errors.New("database is read-only")
+)
+
+// readOnlyDB serves reads from a merkleDB and rejects all writes.
+type readOnlyDB struct {
+	db *merkleDB
+}
+
+// OpenReadOnlyAtRoot opens the merkle database stored in [db] without
+// modifying [db]. Reads are served as of [root], which must be the root of the
+// stored trie. Any call that would modify the database returns [ErrReadOnly].
+//
+// Any writes the database makes to [db] while running, such as writing evicted
+// nodes, are kept in memory and dropped on close.
+func OpenReadOnlyAtRoot(ctx context.Context, db database.Database, root ids.ID, config Config) (database.Database, error) {
+	metrics, err := newMetrics("merkleDB", config.Reg)
+	if err != nil {
+		return nil, err
+	}
+	trieDB, err := newDatabase(ctx, versiondb.New(db), config, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentRoot := trieDB.getMerkleRoot(); currentRoot != root {
+		_ = trieDB.Close()
+		return nil, fmt.Errorf("%w: root %s not found, stored root is %s", ErrInsufficientHistory, root, currentRoot)
+	}
+	return &readOnlyDB{db: trieDB}, nil
+}
+
+func (r *readOnlyDB) Has(key []byte) (bool, error) {
+	return r.db.Has(key)
+}
+
+func (r *readOnlyDB) Get(key []byte) ([]byte, error) {
+	return r.db.Get(key)
+}
+
+func (*readOnlyDB) Put([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+func (*readOnlyDB) Delete([]byte) error {
+	return ErrReadOnly
+}
+
+func (*readOnlyDB) NewBatch() database.Batch {
+	return &readOnlyBatch{}
+}
+
+func (r *readOnlyDB) NewIterator() database.Iterator {
+	return r.db.NewIterator()
+}
+
+func (r *readOnlyDB) NewIteratorWithStart(start []byte) database.Iterator {
+	return r.db.NewIteratorWithStart(start)
+}
+
+func (r *readOnlyDB) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return r.db.NewIteratorWithPrefix(prefix)
+}
+
+func (r *readOnlyDB) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return r.db.NewIteratorWithStartAndPrefix(start, prefix)
+}
+
+func (*readOnlyDB) Compact([]byte, []byte) error {
+	return ErrReadOnly
+}
+
+func (r *readOnlyDB) Close() error {
+	return r.db.Close()
+}
+
+func (r *readOnlyDB) HealthCheck(ctx context.Context) (interface{}, error) {
+	return r.db.HealthCheck(ctx)
+}
+
+// readOnlyBatch records operations like any batch but can't be written.
+type readOnlyBatch struct {
+	database.BatchOps
+}
+
+func (*readOnlyBatch) Write() error {
+	return ErrReadOnly
+}
+
+func (b *readOnlyBatch) Inner() database.Batch {
+	return b
+}
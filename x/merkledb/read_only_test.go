@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+func TestOpenReadOnlyAtRoot(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+	require.NoError(db.Put([]byte("key2"), []byte("value2")))
+	require.NoError(db.Delete([]byte("key1")))
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NoError(db.Close())
+
+	stored := dumpDB(t, baseDB)
+
+	// Opening at a root other than the stored one fails.
+	_, err = OpenReadOnlyAtRoot(context.Background(), baseDB, ids.GenerateTestID(), newDefaultConfig())
+	require.ErrorIs(err, ErrInsufficientHistory)
+
+	readOnlyDB, err := OpenReadOnlyAtRoot(context.Background(), baseDB, root, newDefaultConfig())
+	require.NoError(err)
+
+	// Reads reflect [root].
+	val, err := readOnlyDB.Get([]byte("key2"))
+	require.NoError(err)
+	require.Equal([]byte("value2"), val)
+
+	has, err := readOnlyDB.Has([]byte("key1"))
+	require.NoError(err)
+	require.False(has)
+
+	it := readOnlyDB.NewIterator()
+	require.True(it.Next())
+	require.Equal([]byte("key2"), it.Key())
+	require.False(it.Next())
+	require.NoError(it.Error())
+	it.Release()
+
+	// Writes fail.
+	err = readOnlyDB.Put([]byte("key3"), []byte("value3"))
+	require.ErrorIs(err, ErrReadOnly)
+	err = readOnlyDB.Delete([]byte("key2"))
+	require.ErrorIs(err, ErrReadOnly)
+
+	batch := readOnlyDB.NewBatch()
+	require.NoError(batch.Put([]byte("key3"), []byte("value3")))
+	err = batch.Write()
+	require.ErrorIs(err, ErrReadOnly)
+
+	err = readOnlyDB.Compact(nil, nil)
+	require.ErrorIs(err, ErrReadOnly)
+
+	has, err = readOnlyDB.Has([]byte("key3"))
+	require.NoError(err)
+	require.False(has)
+
+	require.NoError(readOnlyDB.Close())
+
+	// The underlying database wasn't modified.
+	require.Equal(stored, dumpDB(t, baseDB))
+}
+
+// dumpDB returns all the key/value pairs in [db].
+func dumpDB(t *testing.T, db database.Database) map[string][]byte {
+	it := db.NewIterator()
+	defer it.Release()
+
+	kvs := make(map[string][]byte)
+	for it.Next() {
+		kvs[string(it.Key())] = it.Value()
+	}
+	require.NoError(t, it.Error())
+	return kvs
+}
@@ -0,0 +1,216 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+var (
+	ErrScrubberRunning  = errors.New("scrubber is already running")
+	ErrInvalidScrubRate = errors.New("scrub rate must be positive")
+)
+
+type scrubber struct {
+	cancel context.CancelFunc
+	// Closed when the scrubber exits.
+	done chan struct{}
+}
+
+func (db *merkleDB) StartScrubber(ctx context.Context, ratePerSec int) error {
+	if ratePerSec <= 0 {
+		return fmt.Errorf("%w but was %d", ErrInvalidScrubRate, ratePerSec)
+	}
+
+	db.scrubberLock.Lock()
+	defer db.scrubberLock.Unlock()
+
+	if db.scrubber != nil {
+		select {
+		case <-db.scrubber.done:
+			// The previous scrubber exited because its context was
+			// cancelled, so a new one can be started.
+		default:
+			return ErrScrubberRunning
+		}
+	}
+
+	db.lock.RLock()
+	closed := db.closed
+	db.lock.RUnlock()
+	if closed {
+		return database.ErrClosed
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &scrubber{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	db.scrubber = s
+	go func() {
+		defer close(s.done)
+
+		db.scrub(ctx, time.Second/time.Duration(ratePerSec))
+	}()
+	return nil
+}
+
+func (db *merkleDB) StopScrubber() {
+	db.scrubberLock.Lock()
+	defer db.scrubberLock.Unlock()
+
+	if db.scrubber == nil {
+		return
+	}
+	db.scrubber.cancel()
+	<-db.scrubber.done
+	db.scrubber = nil
+}
+
+// scrubTarget is a node to be verified by the scrubber.
+type scrubTarget struct {
+	key path
+	// The key of the node's parent. Ignored if [key] is [RootPath].
+	parentKey path
+}
+
+// scrub walks the trie depth first, verifying one node every [interval],
+// until [ctx] is cancelled or [db] is closed.
+// The locks are only held while a single node is verified, so the trie may
+// change during the walk. Nodes that are removed before they're reached are
+// skipped and nodes that are added may not be verified until the next walk.
+func (db *merkleDB) scrub(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var toVisit []scrubTarget
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if len(toVisit) == 0 {
+			toVisit = append(toVisit, scrubTarget{key: RootPath})
+		}
+		target := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+
+		children, err := db.scrubNode(target)
+		if err == database.ErrClosed {
+			return
+		}
+		for _, child := range children {
+			toVisit = append(toVisit, scrubTarget{
+				key:       child,
+				parentKey: target.key,
+			})
+		}
+		if err != nil && db.onCorruption != nil {
+			db.onCorruption(target.key.Serialize(), err)
+		}
+	}
+}
+
+// scrubNode verifies that the ID of the node at [target] matches the ID its
+// parent has for it, or the root ID if it's the root. Returns the keys of the
+// node's children and, if the node is corrupt or missing, an error describing
+// why.
+// If the node has been removed from the trie by a commit, nothing is
+// returned.
+func (db *merkleDB) scrubNode(target scrubTarget) ([]path, error) {
+	// The node and its parent are read with [db.lock] held so that a commit
+	// can't change them in between.
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	key := target.key
+	expectedID := db.root.id
+	if key != RootPath {
+		parent, err := db.readNodeUncached(target.parentKey)
+		if err == database.ErrNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(key) <= len(parent.key) {
+			return nil, nil
+		}
+		entry, ok := parent.children[key[len(parent.key)]]
+		if !ok || parent.key+path(key[len(parent.key)])+entry.compressedPath != key {
+			// The node was removed or moved since its parent was verified.
+			return nil, nil
+		}
+		expectedID = entry.id
+	}
+
+	n, err := db.readNodeUncached(key)
+	if err == database.ErrNotFound {
+		return nil, fmt.Errorf("%w: %x", errDanglingChild, key.Serialize().Value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]path, 0, len(n.children))
+	for index, entry := range n.children {
+		children = append(children, n.key+path(index)+entry.compressedPath)
+	}
+
+	recalculated := n.clone()
+	recalculated.id = ids.Empty
+	if err := recalculated.calculateID(db.metrics); err != nil {
+		return children, err
+	}
+	if recalculated.id != expectedID {
+		return children, fmt.Errorf(
+			"%w: node %x, expected %s, recalculated %s",
+			errNodeIDMismatch,
+			key.Serialize().Value,
+			expectedID,
+			recalculated.id,
+		)
+	}
+	return children, nil
+}
+
+// readNodeUncached returns the node with [key] as it's stored on disk, without
+// adding it to [db.nodeCache], so that scrubbing doesn't evict the nodes used
+// by reads and commits.
+// Intermediate nodes are only written to disk when they're evicted from the
+// cache, so their copies on disk may be outdated while they're cached. For
+// those, and for the root, the node in memory is returned instead.
+// Assumes [db.lock] is read locked.
+func (db *merkleDB) readNodeUncached(key path) (*node, error) {
+	if key == RootPath {
+		return db.root, nil
+	}
+	if n, isCached := db.nodeCache.Get(key); isCached {
+		if n == nil {
+			return nil, database.ErrNotFound
+		}
+		if !n.hasValue() {
+			return n, nil
+		}
+	}
+
+	rawBytes, err := db.readNode(key)
+	if err != nil {
+		return nil, err
+	}
+	return db.parseNode(key, rawBytes)
+}
@@ -0,0 +1,168 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+	"github.com/ava-labs/avalanchego/database/prefixdb"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+type corruptionReport struct {
+	key SerializedPath
+	err error
+}
+
+func TestScrubber(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	for i := 0; i < 10; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		require.NoError(db.Put(key, key))
+	}
+	require.NoError(db.Close())
+
+	// Change the value of a node on disk without updating its ancestors.
+	nodeDB := prefixdb.New(nodePrefix, baseDB)
+	corruptedKey := newPath([]byte("key1"))
+	nodeBytes, err := nodeDB.Get(corruptedKey.Bytes())
+	require.NoError(err)
+	n, err := parseNode(corruptedKey, nodeBytes)
+	require.NoError(err)
+	n.setValue(maybe.Some([]byte("corrupted")))
+	require.NoError(nodeDB.Put(corruptedKey.Bytes(), n.marshal()))
+
+	reports := make(chan corruptionReport, 1)
+	config := newDefaultConfig()
+	config.OnCorruption = func(key SerializedPath, err error) {
+		select {
+		case reports <- corruptionReport{key: key, err: err}:
+		default:
+		}
+	}
+	db, err = newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+
+	require.NoError(db.StartScrubber(context.Background(), 1_000))
+	err = db.StartScrubber(context.Background(), 1_000)
+	require.ErrorIs(err, ErrScrubberRunning)
+
+	select {
+	case report := <-reports:
+		require.Equal(corruptedKey.Serialize(), report.key)
+		require.ErrorIs(report.err, errNodeIDMismatch)
+	case <-time.After(10 * time.Second):
+		require.FailNow("scrubber didn't report the corrupt node")
+	}
+	db.StopScrubber()
+
+	// The scrubber can be restarted once it has been stopped.
+	require.NoError(db.StartScrubber(context.Background(), 1_000))
+	require.NoError(db.Close())
+	db.StopScrubber()
+
+	err = db.StartScrubber(context.Background(), 1_000)
+	require.ErrorIs(err, database.ErrClosed)
+}
+
+func TestScrubberCachedNode(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	reports := make(chan corruptionReport, 1)
+	config := newDefaultConfig()
+	config.OnCorruption = func(key SerializedPath, err error) {
+		select {
+		case reports <- corruptionReport{key: key, err: err}:
+		default:
+		}
+	}
+	db, err := newDB(context.Background(), baseDB, config)
+	require.NoError(err)
+	for i := 0; i < 10; i++ {
+		key := []byte(fmt.Sprintf("key%d", i))
+		require.NoError(db.Put(key, key))
+	}
+
+	// Corrupt a node on disk while it's in the cache.
+	corruptedKey := newPath([]byte("key1"))
+	_, err = db.Get([]byte("key1"))
+	require.NoError(err)
+	nodeDB := prefixdb.New(nodePrefix, baseDB)
+	nodeBytes, err := nodeDB.Get(corruptedKey.Bytes())
+	require.NoError(err)
+	n, err := parseNode(corruptedKey, nodeBytes)
+	require.NoError(err)
+	n.setValue(maybe.Some([]byte("corrupted")))
+	require.NoError(nodeDB.Put(corruptedKey.Bytes(), n.marshal()))
+
+	cachedNodes := db.nodeCache.fifo.Len()
+	require.NoError(db.StartScrubber(context.Background(), 1_000))
+	select {
+	case report := <-reports:
+		require.Equal(corruptedKey.Serialize(), report.key)
+		require.ErrorIs(report.err, errNodeIDMismatch)
+	case <-time.After(10 * time.Second):
+		require.FailNow("scrubber didn't report the corrupt node")
+	}
+	db.StopScrubber()
+
+	// Scrubbing doesn't add nodes to the cache.
+	require.Equal(cachedNodes, db.nodeCache.fifo.Len())
+	require.NoError(db.Close())
+}
+
+func TestScrubberRoot(t *testing.T) {
+	require := require.New(t)
+
+	reports := make(chan corruptionReport, 1)
+	config := newDefaultConfig()
+	config.OnCorruption = func(key SerializedPath, err error) {
+		select {
+		case reports <- corruptionReport{key: key, err: err}:
+		default:
+		}
+	}
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	// Change the root without updating its ID.
+	db.lock.Lock()
+	rootID := db.root.id
+	db.root.setValue(maybe.Some([]byte("corrupted")))
+	db.root.id = rootID
+	db.lock.Unlock()
+
+	require.NoError(db.StartScrubber(context.Background(), 1_000))
+	select {
+	case report := <-reports:
+		require.Equal(RootPath.Serialize(), report.key)
+		require.ErrorIs(report.err, errNodeIDMismatch)
+	case <-time.After(10 * time.Second):
+		require.FailNow("scrubber didn't report the corrupt root")
+	}
+	db.StopScrubber()
+}
+
+func TestScrubberInvalidRate(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	err = db.StartScrubber(context.Background(), 0)
+	require.ErrorIs(err, ErrInvalidScrubRate)
+}
@@ -0,0 +1,263 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+// The number of key/value pairs a snapshot iterator reads at a time.
+const snapshotIteratorPageSize = 256
+
+var (
+	_ database.Iterator = (*snapshotIterator)(nil)
+
+	ErrSnapshotExpired  = errors.New("snapshot is no longer in the history")
+	ErrSnapshotReleased = errors.New("snapshot has been released")
+)
+
+// Snapshot serves reads of a merkleDB as of the time it was created,
+// regardless of commits made since.
+//
+// A snapshot doesn't prevent its state from being removed from the history.
+// Once more than [Config.HistoryLength] commits have been made since it was
+// created, reads fail with [ErrSnapshotExpired].
+type Snapshot struct {
+	db *merkleDB
+	// The sequence number, as returned by [CommitSeq], of the state of [db]
+	// when this snapshot was created.
+	seq  uint64
+	root ids.ID
+
+	released utils.Atomic[bool]
+}
+
+func (db *merkleDB) Snapshot() (*Snapshot, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return &Snapshot{
+		db:   db,
+		seq:  db.history.nextInsertNumber - 1,
+		root: db.getMerkleRoot(),
+	}, nil
+}
+
+// Root returns the root of the trie this snapshot reads from.
+func (s *Snapshot) Root() ids.ID {
+	return s.root
+}
+
+// Get returns a copy of the value associated with [key] as of this snapshot.
+// Returns [database.ErrNotFound] if [key] wasn't in the database.
+func (s *Snapshot) Get(key []byte) ([]byte, error) {
+	s.db.commitLock.RLock()
+	defer s.db.commitLock.RUnlock()
+
+	keyBounds := maybe.Some(key)
+	view, err := s.getView(keyBounds, keyBounds)
+	if err != nil {
+		return nil, err
+	}
+	return view.getValueCopy(newPath(key))
+}
+
+// GetValues returns copies of the values associated with [keys] as of this
+// snapshot.
+func (s *Snapshot) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error) {
+	_, span := s.db.tracer.Start(ctx, "MerkleDB.Snapshot.GetValues", oteltrace.WithAttributes(
+		attribute.Int("keyCount", len(keys)),
+	))
+	defer span.End()
+
+	s.db.commitLock.RLock()
+	defer s.db.commitLock.RUnlock()
+
+	values := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	if len(keys) == 0 {
+		return values, errs
+	}
+
+	// Only the changes to keys in [smallest, largest] need to be reverted.
+	smallest, largest := keys[0], keys[0]
+	for _, key := range keys[1:] {
+		if bytes.Compare(key, smallest) < 0 {
+			smallest = key
+		}
+		if bytes.Compare(key, largest) > 0 {
+			largest = key
+		}
+	}
+	view, err := s.getView(maybe.Some(smallest), maybe.Some(largest))
+	for i, key := range keys {
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		values[i], errs[i] = view.getValueCopy(newPath(key))
+	}
+	return values, errs
+}
+
+// NewIterator returns an iterator over the key/value pairs in the database as
+// of this snapshot. The key/value pairs are read in pages of
+// [snapshotIteratorPageSize], and commits are only blocked while a page is
+// read, so the iterator holds at most one page in memory. Iterators created
+// before this snapshot is released still work, but if this snapshot expires
+// during the iteration, the iteration stops with [ErrSnapshotExpired].
+func (s *Snapshot) NewIterator() database.Iterator {
+	if s.released.Get() {
+		return &database.IteratorError{Err: ErrSnapshotReleased}
+	}
+	return &snapshotIterator{
+		snapshot: s,
+		next:     maybe.Nothing[[]byte](),
+	}
+}
+
+// readPage returns up to [snapshotIteratorPageSize] key/value pairs with keys
+// >= [start] as of this snapshot. If [start] is Nothing, there's no lower
+// bound.
+func (s *Snapshot) readPage(start maybe.Maybe[[]byte]) ([]KeyValue, error) {
+	s.db.commitLock.RLock()
+	defer s.db.commitLock.RUnlock()
+
+	view, err := s.getHistoricalView(start, maybe.Nothing[[]byte]())
+	if err != nil {
+		return nil, err
+	}
+
+	// Since we hold [db.commitLock], the trie can't change while the page
+	// is read.
+	it := view.NewIteratorWithStart(start.Value())
+	defer it.Release()
+
+	keyValues := make([]KeyValue, 0, snapshotIteratorPageSize)
+	for len(keyValues) < snapshotIteratorPageSize && it.Next() {
+		keyValues = append(keyValues, KeyValue{
+			Key:   it.Key(),
+			Value: slices.Clone(it.Value()),
+		})
+	}
+	return keyValues, it.Error()
+}
+
+// Release causes subsequent reads to fail with [ErrSnapshotReleased].
+func (s *Snapshot) Release() {
+	s.released.Set(true)
+}
+
+// getView returns a view of the trie as of this snapshot for keys in
+// [start, end].
+// Assumes [s.db.commitLock] is read locked.
+func (s *Snapshot) getView(start, end maybe.Maybe[[]byte]) (*trieView, error) {
+	if s.released.Get() {
+		return nil, ErrSnapshotReleased
+	}
+	return s.getHistoricalView(start, end)
+}
+
+// getHistoricalView is like getView, but doesn't fail if this snapshot has
+// been released.
+// Assumes [s.db.commitLock] is read locked.
+func (s *Snapshot) getHistoricalView(start, end maybe.Maybe[[]byte]) (*trieView, error) {
+	db := s.db
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	// The current state isn't in the history if [Config.HistoryLength] is 0.
+	if s.seq != db.history.nextInsertNumber-1 {
+		if _, err := db.history.getRootAtSeq(s.seq); err != nil {
+			return nil, fmt.Errorf("%w: root %s at sequence number %d", ErrSnapshotExpired, s.root, s.seq)
+		}
+	}
+	return db.getHistoricalViewForRange(s.root, start, end)
+}
+
+// snapshotIterator iterates over the key/value pairs of a [Snapshot], reading
+// them a page at a time.
+type snapshotIterator struct {
+	snapshot *Snapshot
+	// The smallest key of the next page. Nothing before the first page is
+	// read.
+	next maybe.Maybe[[]byte]
+	// True once the last page has been read.
+	exhausted bool
+	// The rest of the current page. The first element is the current
+	// key/value pair.
+	page    []KeyValue
+	started bool
+	err     error
+}
+
+func (it *snapshotIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.started && len(it.page) > 0 {
+		it.page = it.page[1:]
+	}
+	it.started = true
+	if len(it.page) > 0 {
+		return true
+	}
+	if it.exhausted {
+		return false
+	}
+
+	it.page, it.err = it.snapshot.readPage(it.next)
+	if it.err != nil {
+		it.page = nil
+		return false
+	}
+	if len(it.page) < snapshotIteratorPageSize {
+		it.exhausted = true
+	} else {
+		lastKey := it.page[len(it.page)-1].Key
+		it.next = maybe.Some(absenceProofStart(lastKey))
+	}
+	return len(it.page) > 0
+}
+
+func (it *snapshotIterator) Error() error {
+	return it.err
+}
+
+func (it *snapshotIterator) Key() []byte {
+	if len(it.page) == 0 {
+		return nil
+	}
+	return it.page[0].Key
+}
+
+func (it *snapshotIterator) Value() []byte {
+	if len(it.page) == 0 {
+		return nil
+	}
+	return it.page[0].Value
+}
+
+func (it *snapshotIterator) Release() {
+	it.page = nil
+	it.exhausted = true
+}
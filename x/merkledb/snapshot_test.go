@@ -0,0 +1,186 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/database/memdb"
+)
+
+func TestSnapshotIsolation(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key1"), []byte("value1")))
+	require.NoError(db.Put([]byte("key2"), []byte("value2")))
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	snapshot, err := db.Snapshot()
+	require.NoError(err)
+	require.Equal(root, snapshot.Root())
+
+	requireSnapshotState := func() {
+		value, err := snapshot.Get([]byte("key1"))
+		require.NoError(err)
+		require.Equal([]byte("value1"), value)
+
+		_, err = snapshot.Get([]byte("key3"))
+		require.ErrorIs(err, database.ErrNotFound)
+
+		values, errs := snapshot.GetValues(context.Background(), [][]byte{[]byte("key2"), []byte("key1"), []byte("key3")})
+		require.Equal([][]byte{[]byte("value2"), []byte("value1"), nil}, values)
+		require.NoError(errs[0])
+		require.NoError(errs[1])
+		require.ErrorIs(errs[2], database.ErrNotFound)
+
+		it := snapshot.NewIterator()
+		defer it.Release()
+		require.True(it.Next())
+		require.Equal([]byte("key1"), it.Key())
+		require.Equal([]byte("value1"), it.Value())
+		require.True(it.Next())
+		require.Equal([]byte("key2"), it.Key())
+		require.Equal([]byte("value2"), it.Value())
+		require.False(it.Next())
+		require.NoError(it.Error())
+	}
+	requireSnapshotState()
+
+	// Commits made after the snapshot was created aren't visible to it.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("key1"), []byte("newValue1")))
+	require.NoError(batch.Delete([]byte("key2")))
+	require.NoError(batch.Put([]byte("key3"), []byte("value3")))
+	require.NoError(batch.Write())
+	requireSnapshotState()
+
+	value, err := db.Get([]byte("key1"))
+	require.NoError(err)
+	require.Equal([]byte("newValue1"), value)
+
+	// Iterators created before the snapshot is released still work.
+	it := snapshot.NewIterator()
+	defer it.Release()
+
+	snapshot.Release()
+	_, err = snapshot.Get([]byte("key1"))
+	require.ErrorIs(err, ErrSnapshotReleased)
+
+	require.True(it.Next())
+	require.Equal([]byte("key1"), it.Key())
+}
+
+func TestSnapshotExpiry(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 3
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	snapshot, err := db.Snapshot()
+	require.NoError(err)
+
+	// The snapshot's state is still in the history.
+	for i := 0; i < config.HistoryLength-1; i++ {
+		require.NoError(db.Put([]byte("key"), []byte(strconv.Itoa(i))))
+	}
+	value, err := snapshot.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	// The snapshot's state is no longer in the history.
+	require.NoError(db.Put([]byte("key"), []byte("newValue")))
+	_, err = snapshot.Get([]byte("key"))
+	require.ErrorIs(err, ErrSnapshotExpired)
+
+	_, errs := snapshot.GetValues(context.Background(), [][]byte{[]byte("key")})
+	require.ErrorIs(errs[0], ErrSnapshotExpired)
+
+	it := snapshot.NewIterator()
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrSnapshotExpired)
+	it.Release()
+}
+
+func TestSnapshotIteratorPages(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 20
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	const numKeys = 3*snapshotIteratorPageSize + 1
+	batch := db.NewBatch()
+	for i := 0; i < numKeys; i++ {
+		key := []byte(fmt.Sprintf("key%04d", i))
+		require.NoError(batch.Put(key, key))
+	}
+	require.NoError(batch.Write())
+
+	snapshot, err := db.Snapshot()
+	require.NoError(err)
+
+	// Commits can be made between pages and aren't visible to the iterator.
+	it := snapshot.NewIterator()
+	for i := 0; i < numKeys; i++ {
+		require.True(it.Next())
+		key := []byte(fmt.Sprintf("key%04d", i))
+		require.Equal(key, it.Key())
+		require.Equal(key, it.Value())
+		if i%snapshotIteratorPageSize == 0 {
+			require.NoError(db.Put([]byte(fmt.Sprintf("key%04d", numKeys-1)), []byte("newValue")))
+			require.NoError(db.Delete([]byte(fmt.Sprintf("key%04d", numKeys-2))))
+		}
+	}
+	require.False(it.Next())
+	require.NoError(it.Error())
+	it.Release()
+
+	// The iteration stops if the snapshot expires between pages.
+	it = snapshot.NewIterator()
+	defer it.Release()
+	for i := 0; i < snapshotIteratorPageSize; i++ {
+		require.True(it.Next())
+	}
+	for i := 0; i < config.HistoryLength; i++ {
+		require.NoError(db.Put([]byte("key"), []byte(strconv.Itoa(i))))
+	}
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrSnapshotExpired)
+}
+
+func TestSnapshotWithoutHistory(t *testing.T) {
+	require := require.New(t)
+
+	config := newDefaultConfig()
+	config.HistoryLength = 0
+	db, err := newDB(context.Background(), memdb.New(), config)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	snapshot, err := db.Snapshot()
+	require.NoError(err)
+
+	// Snapshots of the current state can be read without any history.
+	value, err := snapshot.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	require.NoError(db.Put([]byte("key"), []byte("newValue")))
+	_, err = snapshot.Get([]byte("key"))
+	require.ErrorIs(err, ErrSnapshotExpired)
+}
@@ -6,6 +6,7 @@ package merkledb
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/ava-labs/avalanchego/database"
 	"github.com/ava-labs/avalanchego/ids"
@@ -64,6 +65,24 @@ type TrieView interface {
 	Trie
 
 	// CommitToDB writes the changes in this view to the database.
+	// Views built on the same trie as this view, and their descendants, are
+	// invalidated. Only one of a set of sibling views can be committed.
 	// Takes the DB commit lock.
 	CommitToDB(ctx context.Context) error
+
+	// CommitToDBWithProgress is like CommitToDB, but calls [progress] with
+	// the number of node changes written so far and the total number of node
+	// changes as they're written. [progress] is called on a separate
+	// goroutine without holding any of the database's locks. Successive
+	// calls have increasing [nodesFlushed], though intermediate counts may be
+	// skipped, and the last call of a successful commit has [nodesFlushed]
+	// equal to [totalNodes]. All calls are made before this returns.
+	CommitToDBWithProgress(ctx context.Context, progress func(nodesFlushed, totalNodes int)) error
+
+	// CommitToDBWithDeadline is like CommitToDB, but returns
+	// [ErrCommitDeadlineExceeded] if the changes can't start being written
+	// to disk by [deadline]. The changes are written atomically, so if the
+	// commit is abandoned, the database and its views are left as they were
+	// before the call and the commit may be retried.
+	CommitToDBWithDeadline(ctx context.Context, deadline time.Time) error
 }
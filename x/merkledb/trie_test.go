@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/avalanchego/utils/maybe"
 
@@ -954,6 +955,126 @@ func TestTrieViewInvalidate(t *testing.T) {
 	require.True(view3.invalidated)
 }
 
+func Test_TrieView_Fork(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key0"), []byte("value0")))
+
+	viewIntf, err := db.NewView(context.Background(), []database.BatchOp{
+		{Key: []byte("key1"), Value: []byte("value1")},
+	})
+	require.NoError(err)
+	view := viewIntf.(*trieView)
+
+	fork1, err := view.Fork()
+	require.NoError(err)
+	fork2, err := view.Fork()
+	require.NoError(err)
+
+	// The forks are tracked as siblings of [view].
+	require.Equal(db, fork1.parentTrie)
+	require.Contains(db.childViews, fork1)
+	require.Contains(db.childViews, fork2)
+
+	// Mutate each fork independently.
+	require.NoError(fork1.recordValueChange(newPath([]byte("key2")), maybe.Some([]byte("value2"))))
+	require.NoError(fork2.recordValueChange(newPath([]byte("key3")), maybe.Some([]byte("value3"))))
+
+	viewRoot, err := view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	fork1Root, err := fork1.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	fork2Root, err := fork2.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NotEqual(viewRoot, fork1Root)
+	require.NotEqual(viewRoot, fork2Root)
+	require.NotEqual(fork1Root, fork2Root)
+
+	// Both forks see the pending changes of [view], but not each other's.
+	val, err := fork1.GetValue(context.Background(), []byte("key1"))
+	require.NoError(err)
+	require.Equal([]byte("value1"), val)
+	_, err = fork1.GetValue(context.Background(), []byte("key3"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	val, err = fork2.GetValue(context.Background(), []byte("key1"))
+	require.NoError(err)
+	require.Equal([]byte("value1"), val)
+	_, err = fork2.GetValue(context.Background(), []byte("key2"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	_, err = view.GetValue(context.Background(), []byte("key2"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// Committing one fork invalidates the other.
+	require.NoError(fork1.CommitToDB(context.Background()))
+	require.True(view.isInvalid())
+	require.True(fork2.isInvalid())
+
+	dbRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(fork1Root, dbRoot)
+
+	_, err = view.Fork()
+	require.ErrorIs(err, ErrInvalid)
+
+	// Forking a committed view fails.
+	_, err = fork1.Fork()
+	require.ErrorIs(err, ErrCommitted)
+}
+
+func TestMerkleRoots(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(int64(0))) // #nosec G404
+
+	views := make([]TrieView, 10)
+	expectedViews := make([]TrieView, len(views))
+	for i := range views {
+		batchOps := make([]database.BatchOp, 20)
+		for j := range batchOps {
+			key := make([]byte, r.Intn(8)+1)
+			_, _ = r.Read(key)
+			batchOps[j] = database.BatchOp{
+				Key:   key,
+				Value: []byte(strconv.Itoa(j)),
+			}
+		}
+
+		view, err := db.NewView(context.Background(), batchOps)
+		require.NoError(err)
+		views[i] = view
+
+		expectedViews[i], err = db.NewView(context.Background(), batchOps)
+		require.NoError(err)
+	}
+
+	roots, err := MerkleRoots(context.Background(), views)
+	require.NoError(err)
+	require.Len(roots, len(views))
+	for i, view := range expectedViews {
+		expectedRoot, err := view.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		require.Equal(expectedRoot, roots[i])
+	}
+
+	// An invalid view causes an error.
+	invalidView, err := db.NewView(context.Background(), nil)
+	require.NoError(err)
+	invalidView.(*trieView).invalidate()
+	_, err = MerkleRoots(context.Background(), []TrieView{views[1], invalidView})
+	require.ErrorIs(err, ErrInvalid)
+
+	// Views that weren't created by a merkleDB are rejected.
+	_, err = MerkleRoots(context.Background(), []TrieView{views[1], nil})
+	require.ErrorIs(err, ErrUnsupportedView)
+}
+
 func Test_Trie_ConcurrentNewViewAndCommit(t *testing.T) {
 	require := require.New(t)
 
@@ -980,6 +1101,60 @@ func Test_Trie_ConcurrentNewViewAndCommit(t *testing.T) {
 	require.NotNil(newView)
 }
 
+// Computes roots while holding [db.lock], as composite operations do, while
+// other goroutines compute roots and commit. Should be run with -race.
+func Test_Trie_GetMerkleRootWhileLocked(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	view, err := db.NewView(context.Background(), []database.BatchOp{
+		{Key: []byte("key"), Value: []byte("value")},
+	})
+	require.NoError(err)
+
+	// The lock-free variant requires the node IDs to have been calculated.
+	_, err = view.(*trieView).getMerkleRoot()
+	require.ErrorIs(err, ErrNodesNotCalculated)
+
+	expectedViewRoot, err := view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				_, err := db.GetMerkleRoot(context.Background())
+				require.NoError(err)
+				viewRoot, err := view.GetMerkleRoot(context.Background())
+				require.NoError(err)
+				require.Equal(expectedViewRoot, viewRoot)
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		db.lock.Lock()
+		dbRoot := db.getMerkleRoot()
+		viewRoot, err := view.(*trieView).getMerkleRoot()
+		db.lock.Unlock()
+
+		require.NoError(err)
+		require.Equal(expectedViewRoot, viewRoot)
+		require.NotEqual(dbRoot, viewRoot)
+	}
+	wg.Wait()
+
+	require.NoError(view.CommitToDB(context.Background()))
+	db.lock.RLock()
+	require.Equal(expectedViewRoot, db.getMerkleRoot())
+	db.lock.RUnlock()
+}
+
 func TestTrieCommitToDB(t *testing.T) {
 	r := require.New(t)
 
@@ -1075,3 +1250,115 @@ func TestTrieCommitToDB(t *testing.T) {
 	r.NoError(err)
 	r.Equal(value3, got)
 }
+
+func TestTrieCommitToDBWithProgress(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	ops := make([]database.BatchOp, 10*commitProgressInterval)
+	for i := range ops {
+		key := []byte(strconv.Itoa(i))
+		ops[i] = database.BatchOp{Key: key, Value: key}
+	}
+	view, err := db.NewView(context.Background(), ops)
+	require.NoError(err)
+
+	var (
+		nodesFlushed []int
+		totalNodes   []int
+	)
+	require.NoError(view.CommitToDBWithProgress(context.Background(), func(flushed, total int) {
+		// The database's locks aren't held.
+		_, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+
+		nodesFlushed = append(nodesFlushed, flushed)
+		totalNodes = append(totalNodes, total)
+	}))
+
+	// All the calls were made before the commit returned.
+	require.NotEmpty(nodesFlushed)
+	total := totalNodes[0]
+	require.Greater(total, len(ops))
+	for i := range nodesFlushed {
+		require.Equal(total, totalNodes[i])
+		if i > 0 {
+			require.Greater(nodesFlushed[i], nodesFlushed[i-1])
+		}
+	}
+	require.Equal(total, nodesFlushed[len(nodesFlushed)-1])
+
+	value, err := db.Get(ops[0].Key)
+	require.NoError(err)
+	require.Equal(ops[0].Value, value)
+
+	// Progress isn't reported for a commit that fails.
+	err = view.CommitToDBWithProgress(context.Background(), func(int, int) {
+		require.FailNow("unexpected progress")
+	})
+	require.ErrorIs(err, ErrCommitted)
+}
+
+// slowDB delays every call to Get by [delay].
+type slowDB struct {
+	database.Database
+	delay time.Duration
+}
+
+func (db *slowDB) Get(key []byte) ([]byte, error) {
+	time.Sleep(db.delay)
+	return db.Database.Get(key)
+}
+
+func TestTrieCommitToDBWithDeadline(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db, err := newDB(context.Background(), baseDB, newDefaultConfig())
+	require.NoError(err)
+	ops := make([]database.BatchOp, commitProgressInterval)
+	for i := range ops {
+		key := []byte(strconv.Itoa(i))
+		ops[i] = database.BatchOp{Key: key, Value: key}
+	}
+	view, err := db.NewView(context.Background(), ops)
+	require.NoError(err)
+	require.NoError(view.CommitToDB(context.Background()))
+	require.NoError(db.Close())
+
+	// Reading nodes from disk is slower than the deadline allows.
+	db, err = newDB(context.Background(), &slowDB{Database: baseDB, delay: time.Millisecond}, newDefaultConfig())
+	require.NoError(err)
+	preCommitRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	for i := range ops {
+		ops[i].Value = []byte("updated")
+	}
+	view, err = db.NewView(context.Background(), ops)
+	require.NoError(err)
+
+	err = view.CommitToDBWithDeadline(context.Background(), time.Now().Add(time.Millisecond))
+	require.ErrorIs(err, ErrCommitDeadlineExceeded)
+
+	// The commit was rolled back.
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(preCommitRoot, root)
+	value, err := db.Get(ops[0].Key)
+	require.NoError(err)
+	require.Equal(ops[0].Key, value)
+
+	// The view is still valid, so the commit can be retried.
+	postCommitRoot, err := view.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.NoError(view.CommitToDBWithDeadline(context.Background(), time.Now().Add(time.Minute)))
+	root, err = db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(postCommitRoot, root)
+	value, err = db.Get(ops[0].Key)
+	require.NoError(err)
+	require.Equal([]byte("updated"), value)
+}
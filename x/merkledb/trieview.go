@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/ava-labs/avalanchego/utils"
 
@@ -44,6 +45,11 @@ var (
 	ErrNoValidRoot            = errors.New("a valid root was not provided to the trieView constructor")
 	ErrParentNotDatabase      = errors.New("parent trie is not database")
 	ErrNodesAlreadyCalculated = errors.New("cannot modify the trie after the node changes have been calculated")
+	ErrNodesNotCalculated     = errors.New("the node changes haven't been calculated")
+	ErrValueTooLarge          = errors.New("value exceeds the maximum length")
+	ErrCommitTooLarge         = errors.New("commit changes too many keys")
+	ErrCommitDeadlineExceeded = errors.New("commit deadline exceeded")
+	ErrUnsupportedView        = errors.New("view wasn't created by a merkleDB")
 
 	numCPU = runtime.NumCPU()
 )
@@ -120,6 +126,10 @@ func (t *trieView) NewView(ctx context.Context, batchOps []database.BatchOp) (Tr
 		return t.getParentTrie().NewView(ctx, batchOps)
 	}
 
+	if err := t.db.validateOps(batchOps); err != nil {
+		return nil, err
+	}
+
 	if err := t.calculateNodeIDs(ctx); err != nil {
 		return nil, err
 	}
@@ -140,6 +150,64 @@ func (t *trieView) NewView(ctx context.Context, batchOps []database.BatchOp) (Tr
 	return newView, nil
 }
 
+// Fork returns a new view with the same parent as this view and a copy of this
+// view's pending value changes. Changes recorded in either view after the fork
+// aren't visible to the other. The fork is tracked as a sibling of this view,
+// so committing either of them invalidates the other.
+// Assumes [t.commitLock] isn't held.
+func (t *trieView) Fork() (*trieView, error) {
+	if t.isInvalid() {
+		return nil, ErrInvalid
+	}
+
+	t.commitLock.RLock()
+	committed := t.committed
+	t.commitLock.RUnlock()
+	if committed {
+		return nil, ErrCommitted
+	}
+
+	parentTrie := t.getParentTrie()
+	root, err := parentTrie.getEditableNode(RootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fork, err := newTrieView(t.db, parentTrie, root, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, valueChange := range t.changes.values {
+		fork.changes.values[key] = &change[maybe.Maybe[[]byte]]{
+			before: valueChange.before,
+			after:  valueChange.after,
+		}
+	}
+
+	switch parentTrie := parentTrie.(type) {
+	case *merkleDB:
+		parentTrie.lock.Lock()
+		parentTrie.childViews = append(parentTrie.childViews, fork)
+		parentTrie.lock.Unlock()
+	case *trieView:
+		parentTrie.validityTrackingLock.Lock()
+		if parentTrie.invalidated {
+			parentTrie.validityTrackingLock.Unlock()
+			return nil, ErrInvalid
+		}
+		parentTrie.childViews = append(parentTrie.childViews, fork)
+		parentTrie.validityTrackingLock.Unlock()
+	}
+
+	// If a sibling was committed while forking, [fork] may not have been
+	// invalidated along with this view.
+	if t.isInvalid() {
+		fork.invalidate()
+		return nil, ErrInvalid
+	}
+	return fork, nil
+}
+
 // Creates a new view with the given [parentTrie].
 func newTrieView(
 	db *merkleDB,
@@ -228,7 +296,7 @@ func (t *trieView) calculateNodeIDs(ctx context.Context) error {
 
 		// [eg] limits the number of goroutines we start.
 		var eg errgroup.Group
-		eg.SetLimit(numCPU)
+		eg.SetLimit(t.db.viewBuildConcurrency)
 		if err = t.calculateNodeIDsHelper(ctx, t.root, &eg); err != nil {
 			return
 		}
@@ -322,35 +390,47 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 		Key: key,
 	}
 
-	// Get the node at the given path, or the node closest to it.
 	keyPath := newPath(key)
-
-	proofPath, err := t.getPathTo(keyPath)
+	proofPath, err := t.getProofPath(keyPath)
 	if err != nil {
 		return nil, err
 	}
 
 	// From root --> node from left --> right.
-	proof.Path = make([]ProofNode, len(proofPath), len(proofPath)+1)
+	proof.Path = make([]ProofNode, len(proofPath))
 	for i, node := range proofPath {
 		proof.Path[i] = node.asProofNode()
 	}
 
-	closestNode := proofPath[len(proofPath)-1]
-
-	if closestNode.key.Compare(keyPath) == 0 {
+	if closestNode := proofPath[len(proofPath)-1]; closestNode.key.Compare(keyPath) == 0 {
 		// There is a node with the given [key].
 		proof.Value = maybe.Bind(closestNode.value, slices.Clone[[]byte])
-		return proof, nil
+	}
+	return proof, nil
+}
+
+// getProofPath returns the nodes in a proof that [key] is in or not in trie
+// [t]. These are the nodes along the path to [key] and, if there's no node
+// with [key], the child of the closest node at the index where it would be.
+func (t *trieView) getProofPath(key path) ([]*node, error) {
+	// Get the node at the given path, or the node closest to it.
+	proofPath, err := t.getPathTo(key)
+	if err != nil {
+		return nil, err
+	}
+
+	closestNode := proofPath[len(proofPath)-1]
+	if closestNode.key.Compare(key) == 0 {
+		return proofPath, nil
 	}
 
 	// There is no node with the given [key].
 	// If there is a child at the index where the node would be
 	// if it existed, include that child in the proof.
-	nextIndex := keyPath[len(closestNode.key)]
+	nextIndex := key[len(closestNode.key)]
 	child, ok := closestNode.children[nextIndex]
 	if !ok {
-		return proof, nil
+		return proofPath, nil
 	}
 
 	childPath := closestNode.key + path(nextIndex) + child.compressedPath
@@ -358,11 +438,10 @@ func (t *trieView) getProof(ctx context.Context, key []byte) (*Proof, error) {
 	if err != nil {
 		return nil, err
 	}
-	proof.Path = append(proof.Path, childNode.asProofNode())
 	if t.isInvalid() {
 		return nil, ErrInvalid
 	}
-	return proof, nil
+	return append(proofPath, childNode), nil
 }
 
 // GetRangeProof returns a range proof for (at least part of) the key range [start, end].
@@ -459,6 +538,89 @@ func (t *trieView) GetRangeProof(
 	return &result, nil
 }
 
+// estimateRangeProofCost returns the number of proof nodes in, and the
+// approximate serialized size of, the proof that GetRangeProof would return
+// for the same arguments. Only the nodes on the paths to the boundary keys are
+// read, and no proof nodes or key/value pairs are copied.
+func (t *trieView) estimateRangeProofCost(
+	ctx context.Context,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxKeys int,
+) (int, int, error) {
+	if start.HasValue() && end.HasValue() && bytes.Compare(start.Value(), end.Value()) == 1 {
+		return 0, 0, ErrStartAfterEnd
+	}
+
+	if maxKeys <= 0 {
+		return 0, 0, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxKeys)
+	}
+
+	if err := t.calculateNodeIDs(ctx); err != nil {
+		return 0, 0, err
+	}
+
+	var (
+		size     int
+		numKeys  int
+		lastKey  []byte
+		endProof []*node
+		err      error
+	)
+	it := t.NewIteratorWithStart(start.Value())
+	for it.Next() && numKeys < maxKeys && (end.IsNothing() || bytes.Compare(it.Key(), end.Value()) <= 0) {
+		lastKey = it.Key()
+		size += keyValueProofSize(len(lastKey), len(it.Value()))
+		numKeys++
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return 0, 0, err
+	}
+
+	// Mirrors the boundary proofs chosen by GetRangeProof.
+	if numKeys > 0 {
+		endProof, err = t.getProofPath(newPath(lastKey))
+	} else if end.HasValue() {
+		endProof, err = t.getProofPath(newPath(end.Value()))
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var startProof []*node
+	if start.HasValue() {
+		startProof, err = t.getProofPath(newPath(start.Value()))
+		if err != nil {
+			return 0, 0, err
+		}
+
+		// Nodes shared with the end proof aren't included twice.
+		i := 0
+		for ; i < len(startProof) &&
+			i < len(endProof) &&
+			startProof[i].key == endProof[i].key; i++ {
+		}
+		startProof = startProof[i:]
+	}
+
+	if len(startProof) == 0 && len(endProof) == 0 && numKeys == 0 {
+		endProof = []*node{t.root}
+	}
+
+	for _, n := range startProof {
+		size += proofNodeSize(n)
+	}
+	for _, n := range endProof {
+		size += proofNodeSize(n)
+	}
+
+	if t.isInvalid() {
+		return 0, 0, ErrInvalid
+	}
+	return len(startProof) + len(endProof), size, nil
+}
+
 // CommitToDB commits changes from this trie to the underlying DB.
 func (t *trieView) CommitToDB(ctx context.Context) error {
 	ctx, span := t.db.tracer.Start(ctx, "MerkleDB.trieview.CommitToDB")
@@ -470,10 +632,44 @@ func (t *trieView) CommitToDB(ctx context.Context) error {
 	return t.commitToDB(ctx)
 }
 
+func (t *trieView) CommitToDBWithProgress(ctx context.Context, progress func(nodesFlushed, totalNodes int)) error {
+	ctx, span := t.db.tracer.Start(ctx, "MerkleDB.trieview.CommitToDBWithProgress")
+	defer span.End()
+
+	reporter := newProgressReporter(progress)
+	err := func() error {
+		t.db.commitLock.Lock()
+		defer t.db.commitLock.Unlock()
+
+		return t.commitToDBWithOptions(ctx, commitOptions{progress: reporter.report})
+	}()
+	// Wait for [progress] to be called with the last report after the
+	// locks have been released.
+	reporter.close()
+	return err
+}
+
+func (t *trieView) CommitToDBWithDeadline(ctx context.Context, deadline time.Time) error {
+	ctx, span := t.db.tracer.Start(ctx, "MerkleDB.trieview.CommitToDBWithDeadline")
+	defer span.End()
+
+	t.db.commitLock.Lock()
+	defer t.db.commitLock.Unlock()
+
+	return t.commitToDBWithOptions(ctx, commitOptions{deadline: deadline})
+}
+
 // Commits the changes from [trieToCommit] to this view,
 // this view to its parent, and so on until committing to the db.
 // Assumes [t.db.commitLock] is held.
 func (t *trieView) commitToDB(ctx context.Context) error {
+	return t.commitToDBWithOptions(ctx, commitOptions{})
+}
+
+// Like commitToDB, but the commit is configured by [opts].
+// See [merkleDB.commitChanges].
+// Assumes [t.db.commitLock] is held.
+func (t *trieView) commitToDBWithOptions(ctx context.Context, opts commitOptions) error {
 	t.commitLock.Lock()
 	defer t.commitLock.Unlock()
 
@@ -482,18 +678,31 @@ func (t *trieView) commitToDB(ctx context.Context) error {
 	))
 	defer span.End()
 
+	// Checked before the node changes are calculated so that nothing is
+	// done for a view that can't be committed.
+	if maxChangedKeys := t.db.maxChangedKeysPerCommit; maxChangedKeys > 0 && len(t.changes.values) > maxChangedKeys {
+		return fmt.Errorf("%w: %d keys changed > %d", ErrCommitTooLarge, len(t.changes.values), maxChangedKeys)
+	}
+
 	// Call this here instead of in [t.db.commitChanges]
 	// because doing so there would be a deadlock.
 	if err := t.calculateNodeIDs(ctx); err != nil {
 		return err
 	}
 
-	if err := t.db.commitChanges(ctx, t); err != nil {
+	// Verified before anything is written so that a mismatched root is never
+	// committed.
+	if t.db.verifyOnCommit {
+		if err := verifyRoot(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	if err := t.db.commitChanges(ctx, t, opts); err != nil {
 		return err
 	}
 
 	t.committed = true
-
 	return nil
 }
 
@@ -528,11 +737,61 @@ func (t *trieView) updateParent(newParent TrieView) {
 	t.parentTrie = newParent
 }
 
-// GetMerkleRoot returns the ID of the root of this trie.
+// GetMerkleRoot returns the ID of the root of this trie, calculating the node
+// IDs of this view if they haven't been calculated.
+// Calculating the node IDs may read from the database, which takes the
+// database's lock. Callers holding the database's lock should use
+// [getMerkleRoot] instead.
 func (t *trieView) GetMerkleRoot(ctx context.Context) (ids.ID, error) {
 	if err := t.calculateNodeIDs(ctx); err != nil {
 		return ids.Empty, err
 	}
+	return t.getMerkleRoot()
+}
+
+// MerkleRoots returns the merkle root of each of [views], in the same order.
+// The roots of the views are calculated concurrently, so this is faster than
+// calling GetMerkleRoot on each view when the views don't share ancestry.
+// The result is the same as calling GetMerkleRoot on each view in turn.
+// Returns [ErrUnsupportedView] if any of [views] wasn't created by a merkleDB.
+func MerkleRoots(ctx context.Context, views []TrieView) ([]ids.ID, error) {
+	trieViews := make([]*trieView, len(views))
+	for i, view := range views {
+		trieView, ok := view.(*trieView)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T at index %d", ErrUnsupportedView, view, i)
+		}
+		trieViews[i] = trieView
+	}
+
+	roots := make([]ids.ID, len(views))
+
+	// [eg] limits the number of goroutines we start.
+	var eg errgroup.Group
+	eg.SetLimit(numCPU)
+	for i, view := range trieViews {
+		i, view := i, view
+		eg.Go(func() error {
+			root, err := view.GetMerkleRoot(ctx)
+			if err != nil {
+				return err
+			}
+			roots[i] = root
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
+
+// getMerkleRoot returns the root ID of this view without taking any locks.
+// Returns [ErrNodesNotCalculated] if the node IDs haven't been calculated.
+func (t *trieView) getMerkleRoot() (ids.ID, error) {
+	if !t.nodesAlreadyCalculated.Get() {
+		return ids.Empty, ErrNodesNotCalculated
+	}
 	return t.root.id, nil
 }
 
@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"golang.org/x/exp/slices"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/utils/maybe"
+)
+
+var _ Txn = (*txn)(nil)
+
+// Txn is a set of reads and writes against a database that are applied
+// atomically. Reads see the writes previously made in the same Txn.
+type Txn interface {
+	database.KeyValueReader
+	database.KeyValueWriter
+	database.KeyValueDeleter
+}
+
+type txn struct {
+	db *merkleDB
+
+	// Key --> The value that key was changed to in this transaction.
+	// Nothing if the key was deleted.
+	changes map[string]maybe.Maybe[[]byte]
+	// The keys in [changes], in the order they were first changed.
+	keys []string
+}
+
+func newTxn(db *merkleDB) *txn {
+	return &txn{
+		db:      db,
+		changes: make(map[string]maybe.Maybe[[]byte]),
+	}
+}
+
+func (t *txn) Has(key []byte) (bool, error) {
+	_, err := t.Get(key)
+	if err == database.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (t *txn) Get(key []byte) ([]byte, error) {
+	if change, ok := t.changes[string(key)]; ok {
+		if change.IsNothing() {
+			return nil, database.ErrNotFound
+		}
+		return slices.Clone(change.Value()), nil
+	}
+	return t.db.Get(key)
+}
+
+func (t *txn) Put(key, value []byte) error {
+	t.record(key, maybe.Some(slices.Clone(value)))
+	return nil
+}
+
+func (t *txn) Delete(key []byte) error {
+	t.record(key, maybe.Nothing[[]byte]())
+	return nil
+}
+
+func (t *txn) record(key []byte, value maybe.Maybe[[]byte]) {
+	keyStr := string(key)
+	if _, ok := t.changes[keyStr]; !ok {
+		t.keys = append(t.keys, keyStr)
+	}
+	t.changes[keyStr] = value
+}
+
+// ops returns the changes made in this transaction.
+func (t *txn) ops() []database.BatchOp {
+	ops := make([]database.BatchOp, len(t.keys))
+	for i, key := range t.keys {
+		change := t.changes[key]
+		ops[i] = database.BatchOp{
+			Key:    []byte(key),
+			Value:  change.Value(),
+			Delete: change.IsNothing(),
+		}
+	}
+	return ops
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2019-2023, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ava-labs/avalanchego/database"
+)
+
+func TestUpdateCommitsOnSuccess(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte("a"), []byte("1")))
+	require.NoError(db.Put([]byte("b"), []byte("2")))
+
+	require.NoError(db.Update(context.Background(), func(tx Txn) error {
+		value, err := tx.Get([]byte("a"))
+		if err != nil {
+			return err
+		}
+		if err := tx.Put([]byte("c"), value); err != nil {
+			return err
+		}
+		return tx.Delete([]byte("b"))
+	}))
+
+	value, err := db.Get([]byte("a"))
+	require.NoError(err)
+	require.Equal([]byte("1"), value)
+
+	_, err = db.Get([]byte("b"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	value, err = db.Get([]byte("c"))
+	require.NoError(err)
+	require.Equal([]byte("1"), value)
+
+	// The root must match a database that had the same changes written
+	// directly.
+	expectedDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(expectedDB.Put([]byte("a"), []byte("1")))
+	require.NoError(expectedDB.Put([]byte("c"), []byte("1")))
+
+	expectedRoot, err := expectedDB.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(expectedRoot, root)
+}
+
+func TestUpdateRollsBackOnError(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte("a"), []byte("1")))
+
+	rootBefore, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	err = db.Update(context.Background(), func(tx Txn) error {
+		if err := tx.Put([]byte("a"), []byte("2")); err != nil {
+			return err
+		}
+		if err := tx.Put([]byte("b"), []byte("3")); err != nil {
+			return err
+		}
+		return errTest
+	})
+	require.ErrorIs(err, errTest)
+
+	value, err := db.Get([]byte("a"))
+	require.NoError(err)
+	require.Equal([]byte("1"), value)
+
+	_, err = db.Get([]byte("b"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	rootAfter, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(rootBefore, rootAfter)
+}
+
+func TestUpdateReadYourWrites(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(db.Put([]byte("a"), []byte("1")))
+
+	require.NoError(db.Update(context.Background(), func(tx Txn) error {
+		value, err := tx.Get([]byte("a"))
+		require.NoError(err)
+		require.Equal([]byte("1"), value)
+
+		require.NoError(tx.Put([]byte("a"), []byte("2")))
+		value, err = tx.Get([]byte("a"))
+		require.NoError(err)
+		require.Equal([]byte("2"), value)
+
+		// The change isn't visible outside the transaction until it commits.
+		value, err = db.Get([]byte("a"))
+		require.NoError(err)
+		require.Equal([]byte("1"), value)
+
+		require.NoError(tx.Delete([]byte("a")))
+		_, err = tx.Get([]byte("a"))
+		require.ErrorIs(err, database.ErrNotFound)
+
+		has, err := tx.Has([]byte("a"))
+		require.NoError(err)
+		require.False(has)
+
+		require.NoError(tx.Put([]byte("b"), []byte("3")))
+		has, err = tx.Has([]byte("b"))
+		require.NoError(err)
+		require.True(has)
+		return nil
+	}))
+
+	_, err = db.Get([]byte("a"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	value, err := db.Get([]byte("b"))
+	require.NoError(err)
+	require.Equal([]byte("3"), value)
+}